@@ -0,0 +1,279 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package analysis analyzes the structure of trained networks
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/metrics"
+	"github.com/pointlander/occam/vis"
+
+	"github.com/pointlander/levenshtein"
+	"github.com/pointlander/pagerank"
+)
+
+// TreeNode is a node of the prefix tree of the inputs sorted by their attention over the points
+type TreeNode struct {
+	// Index is the index of the point
+	Index int `json:"index"`
+	// Ranks are the attention of the inputs that pass through the node
+	Ranks []float32 `json:"ranks"`
+	// Labels are the labels of the inputs that end at the node
+	Labels   []string    `json:"labels,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// WriteJSON writes the tree as nested json
+func WriteJSON(tree []*TreeNode, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(tree)
+}
+
+// Analyzer calculates properties of the network. The prefix tree of the inputs is written to tree.html
+// and tree.json.
+func Analyzer(n *occam.Network, in []occam.Sample) error {
+	return analyze(n.Length, n.Logger, n.GetVectors(in))
+}
+
+// ComplexAnalyzer calculates properties of the complex network like Analyzer using the magnitudes of
+// the attention
+func ComplexAnalyzer(n *occam.ComplexNetwork, in []occam.Sample) error {
+	return analyze(n.Length, n.Logger, n.GetVectors(in))
+}
+
+// point is a point ranked by the attention of an input
+type point struct {
+	Index int
+	Rank  float32
+}
+
+// input is an input with the points sorted by attention
+type input struct {
+	Points []point
+	Label  string
+}
+
+// node is a node of the prefix tree of the inputs
+type node struct {
+	Nodes map[int]*node
+	Ranks []float32
+	Label []string
+}
+
+// prefix builds the prefix tree of the attention vectors of a network with length points. The inputs
+// with the points sorted by attention are returned in the order of the tree.
+func prefix(length int, vectors []occam.Sample) (*node, []input) {
+	var build func(in input, depth int, parent *node)
+	build = func(in input, depth int, parent *node) {
+		if depth >= length {
+			return
+		}
+		if parent.Nodes == nil {
+			parent.Nodes = make(map[int]*node)
+		}
+		n := parent.Nodes[in.Points[depth].Index]
+		if n == nil {
+			n = &node{}
+		}
+		n.Ranks = append(n.Ranks, in.Points[depth].Rank)
+		if depth == length-1 {
+			n.Label = append(n.Label, in.Label)
+		}
+		parent.Nodes[in.Points[depth].Index] = n
+		build(in, depth+1, n)
+	}
+	// For each input, label and sort the points in terms of distance to the input
+	inputs := make([]input, 0, len(vectors))
+	for _, vector := range vectors {
+		points := make([]point, 0, length)
+		for j, value := range vector.Measures {
+			points = append(points, point{
+				Index: j,
+				Rank:  float32(value),
+			})
+		}
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Rank > points[j].Rank
+		})
+		inputs = append(inputs, input{
+			Points: points,
+			Label:  vector.Label,
+		})
+	}
+	// Sort the inputs by the point indexes
+	sort.Slice(inputs, func(i, j int) bool {
+		index := 0
+		for inputs[i].Points[index].Index == inputs[j].Points[index].Index {
+			index++
+			if index == length {
+				return false
+			}
+		}
+		return inputs[i].Points[index].Index < inputs[j].Points[index].Index
+	})
+
+	root := &node{}
+	for _, in := range inputs {
+		build(in, 0, root)
+	}
+	return root, inputs
+}
+
+// export converts the children of the prefix tree node to tree nodes
+func export(parent *node) []*TreeNode {
+	indexes := make([]int, 0, len(parent.Nodes))
+	for index := range parent.Nodes {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	children := make([]*TreeNode, 0, len(indexes))
+	for _, index := range indexes {
+		child := parent.Nodes[index]
+		children = append(children, &TreeNode{
+			Index:    index,
+			Ranks:    child.Ranks,
+			Labels:   child.Label,
+			Children: export(child),
+		})
+	}
+	return children
+}
+
+// translate converts the prefix tree to a tree chart with the chains of single children collapsed
+func translate(root *node) []*vis.TreeNode {
+	var translate func(parent *node, tree *[]*vis.TreeNode)
+	tree := make([]*vis.TreeNode, 0, 8)
+	translate = func(parent *node, tree *[]*vis.TreeNode) {
+		if parent == nil || tree == nil {
+			return
+		}
+		if len(parent.Nodes) == 1 {
+			for i, n := range parent.Nodes {
+				if n.Nodes == nil {
+					label := ""
+					for _, l := range n.Label {
+						label += fmt.Sprintf("%s-", l)
+					}
+					t := vis.TreeNode{
+						Name: fmt.Sprintf("%d-%s", i, label),
+					}
+					*tree = append(*tree, &t)
+					break
+				}
+				translate(n, tree)
+			}
+			return
+		}
+		for i, n := range parent.Nodes {
+			t := vis.TreeNode{
+				Name:     fmt.Sprintf("%d", i),
+				Children: make([]*vis.TreeNode, 0, 8),
+			}
+			translate(n, &t.Children)
+			*tree = append(*tree, &t)
+		}
+	}
+	translate(root, &tree)
+	return tree
+}
+
+// Rank is the pagerank of a point
+type Rank struct {
+	Index int
+	Rank  float64
+}
+
+// pageRank ranks the length points by the attention graph of the vectors, highest rank first
+func pageRank(length int, vectors []occam.Sample) []Rank {
+	g := pagerank.NewGraph64()
+	for i, vector := range vectors {
+		for j, weight := range vector.Measures {
+			g.Link(uint64(i), uint64(j), weight)
+		}
+	}
+	ranks := make([]Rank, length)
+	g.Rank(0.85, 0.000001, func(node uint64, rank float64) {
+		ranks[node].Rank = rank
+		ranks[node].Index = int(node)
+	})
+	sort.Slice(ranks, func(i, j int) bool {
+		return ranks[i].Rank > ranks[j].Rank
+	})
+	return ranks
+}
+
+// analyze calculates properties of the attention vectors of a network with length points
+func analyze(length int, logger *slog.Logger, vectors []occam.Sample) error {
+	vectors = vectors[:length]
+	root, inputs := prefix(length, vectors)
+	err := WriteJSON(export(root), "tree.json")
+	if err != nil {
+		return err
+	}
+	err = vis.Tree(translate(root), "tree.html")
+	if err != nil {
+		return err
+	}
+
+	// Count how many inputs have the same label as their nearest neighbor
+	same := 0
+	for i, label := range inputs {
+		min, index := math.MaxInt, 0
+		for j, l := range inputs {
+			if i == j {
+				continue
+			}
+			a, b := make([]int, 0, 8), make([]int, 0, 8)
+			for k, value := range label.Points {
+				a = append(a, value.Index)
+				b = append(b, l.Points[k].Index)
+			}
+			total := levenshtein.ComputeDistance(a, b)
+			if total < min {
+				min, index = total, j
+			}
+		}
+		indexes := make([]int, 0, 18)
+		for _, rank := range label.Points[:18] {
+			indexes = append(indexes, rank.Index)
+		}
+		logger.Debug("nearest neighbor", "points", indexes, "label", label.Label, "neighbor", inputs[index].Label)
+		if label.Label == inputs[index].Label {
+			same++
+		}
+	}
+	logger.Info("nearest neighbor accuracy", "same", same, "length", length, "accuracy", float64(same)/float64(length))
+
+	// The clusters are the points with the most attention
+	samples, labels, clusters := make([][]float64, len(vectors)), make([]string, len(vectors)), make([]int, len(vectors))
+	for i, vector := range vectors {
+		samples[i], labels[i] = vector.Measures, vector.Label
+		for j, value := range vector.Measures {
+			if value > vector.Measures[clusters[i]] {
+				clusters[i] = j
+			}
+		}
+	}
+	logger.Info("metrics", "silhouette", metrics.Silhouette(samples, clusters),
+		"daviesbouldin", metrics.DaviesBouldin(samples, clusters), "ari", metrics.AdjustedRand(labels, clusters))
+
+	for _, rank := range pageRank(length, vectors) {
+		logger.Info("pagerank", "point", rank.Index, "rank", rank.Rank)
+	}
+	return nil
+}
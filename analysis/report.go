@@ -0,0 +1,137 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/vis"
+)
+
+// ReportRows is the maximum number of rows of the entropy table of a report
+const ReportRows = 100
+
+// Report writes a single html page to path with the cost curve of the network, the entropy ranking of the
+// inputs, the prefix tree of the inputs, the projection of the attention of the inputs onto its first two
+// principal components, and the pagerank of the points. The report is a go-echarts page, so the javascript
+// of the charts is loaded from the go-echarts assets host, see ReportWithAssets for a self contained page.
+func Report(n *occam.Network, inputs []occam.Sample, path string) error {
+	return ReportWithAssets(n, inputs, path, "")
+}
+
+// ReportWithAssets writes the report like Report with the javascript of the charts inlined from the local
+// copies in the assets directory, such as echarts.min.js, so the page doesn't need a network to be viewed
+func ReportWithAssets(n *occam.Network, inputs []occam.Sample, path, assets string) error {
+	if len(inputs) == 0 {
+		return errors.New("no inputs")
+	}
+	report := vis.Report{
+		Title:  "occam report",
+		Assets: assets,
+	}
+	if len(n.Points) > 0 {
+		report.Charts = append(report.Charts, vis.Cost(n.Points))
+	}
+
+	// The inputs ranked by entropy
+	entropy := n.GetEntropy(inputs)
+	sort.Slice(entropy, func(i, j int) bool {
+		return entropy[i].Entropy < entropy[j].Entropy
+	})
+	ranking := vis.Series{
+		Name: "entropy",
+	}
+	table := vis.Table{
+		Title:  "entropy ranking",
+		Header: []string{"rank", "input", "label", "entropy"},
+	}
+	for i, e := range entropy {
+		ranking.Points = append(ranking.Points, vis.Point{X: float64(i), Y: float64(e.Entropy)})
+		if i < ReportRows {
+			table.Rows = append(table.Rows, []string{
+				fmt.Sprintf("%d", i),
+				fmt.Sprintf("%d", e.Index),
+				e.Label,
+				fmt.Sprintf("%f", e.Entropy),
+			})
+		}
+	}
+	report.Charts = append(report.Charts, vis.Chart{
+		Kind:   vis.KindLine,
+		Title:  "entropy ranking",
+		XLabel: "rank",
+		YLabel: "entropy",
+		Series: []vis.Series{ranking},
+	})
+	report.Tables = append(report.Tables, table)
+
+	// The attention projected onto the first two principal components
+	vectors := n.GetVectors(inputs)
+	projection, err := project(vectors)
+	if err != nil {
+		return err
+	}
+	report.Charts = append(report.Charts, projection)
+
+	// The prefix tree and the pagerank use the first length inputs like Analyzer
+	if len(vectors) > n.Length {
+		vectors = vectors[:n.Length]
+	}
+	root, _ := prefix(n.Length, vectors)
+	report.Tree = translate(root)
+	table = vis.Table{
+		Title:  "pagerank",
+		Header: []string{"point", "rank"},
+	}
+	for _, rank := range pageRank(n.Length, vectors) {
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%d", rank.Index),
+			fmt.Sprintf("%f", rank.Rank),
+		})
+	}
+	report.Tables = append(report.Tables, table)
+
+	return report.Write(path)
+}
+
+// project returns a scatter chart of the vectors projected onto their first two principal components with
+// a series for each label
+func project(vectors []occam.Sample) (vis.Chart, error) {
+	chart := vis.Chart{
+		Kind:   vis.KindScatter,
+		Title:  "principal components of the attention",
+		XLabel: "first",
+		YLabel: "second",
+	}
+	rows := make([][]float64, len(vectors))
+	for i, vector := range vectors {
+		rows[i] = vector.Measures
+	}
+	projected, err := occam.Principal(rows, 2)
+	if err != nil {
+		return chart, err
+	}
+
+	labels := make(map[string]int)
+	for i, vector := range vectors {
+		index, ok := labels[vector.Label]
+		if !ok {
+			index = len(chart.Series)
+			labels[vector.Label] = index
+			chart.Series = append(chart.Series, vis.Series{
+				Name: vector.Label,
+			})
+		}
+		point := vis.Point{X: projected[i][0]}
+		if len(projected[i]) > 1 {
+			point.Y = projected[i][1]
+		}
+		chart.Series[index].Points = append(chart.Series[index].Points, point)
+	}
+	return chart, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// Anomaly is the anomaly score of a sample
+type Anomaly struct {
+	// Score is the entropy of the sample
+	Score float64
+	// Threshold is the adaptive threshold the score was compared to
+	Threshold float64
+	// Anomalous is true if the score is above the threshold
+	Anomalous bool
+}
+
+// Detector scores a stream of samples with a trained network and flags the samples with an entropy
+// above an adaptive threshold. The threshold is an exponentially weighted mean plus a number of
+// standard deviations of the entropy of the normal samples.
+type Detector struct {
+	Network *Network
+	// Alpha is the weight of each new sample in the moving averages
+	Alpha float64
+	// Deviations is the number of standard deviations above the mean for a sample to be anomalous
+	Deviations float64
+	// Warmup is the number of samples seen before any sample is flagged
+	Warmup   int
+	mean     float64
+	variance float64
+	count    int
+}
+
+// NewDetector creates a new anomaly detector for the trained network
+func NewDetector(n *Network) *Detector {
+	return &Detector{
+		Network:    n,
+		Alpha:      .01,
+		Deviations: 3,
+		Warmup:     32,
+	}
+}
+
+// Score computes the anomaly score of the sample. The network isn't trained.
+func (d *Detector) Score(sample []float64) Anomaly {
+	score := float64(d.Network.entropy(sample))
+	anomaly := Anomaly{
+		Score:     score,
+		Threshold: d.mean + d.Deviations*math.Sqrt(d.variance),
+	}
+	anomaly.Anomalous = d.count >= d.Warmup && score > anomaly.Threshold
+	if anomaly.Anomalous {
+		return anomaly
+	}
+
+	// Only the normal samples update the threshold so anomalies don't raise it
+	d.count++
+	alpha := d.Alpha
+	if alpha < 1/float64(d.count) {
+		alpha = 1 / float64(d.count)
+	}
+	delta := score - d.mean
+	d.mean += alpha * delta
+	d.variance = (1 - alpha) * (d.variance + alpha*delta*delta)
+	return anomaly
+}
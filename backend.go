@@ -0,0 +1,73 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas32"
+)
+
+// Backend is an accelerated matrix multiplication used for the attention
+type Backend interface {
+	// Gemm computes c = alpha*op(a)*op(b) + beta*c for row major matrices, where op transposes its matrix
+	// if the matching flag is set. op(a) is m by k, op(b) is k by n, and c is m by n.
+	Gemm(transA, transB bool, m, n, k int, alpha float32, a, b []float32, beta float32, c []float32)
+}
+
+// BLAS is a backend that multiplies with gonum/blas/blas32, which uses the pure go implementation unless
+// another implementation such as a cgo binding to OpenBLAS is registered with blas32.Use
+type BLAS struct{}
+
+// Gemm computes c = alpha*op(a)*op(b) + beta*c with blas32.Gemm
+func (BLAS) Gemm(transA, transB bool, m, n, k int, alpha float32, a, b []float32, beta float32, c []float32) {
+	general := func(trans bool, rows, cols int, data []float32) (blas.Transpose, blas32.General) {
+		if trans {
+			rows, cols = cols, rows
+		}
+		t := blas.NoTrans
+		if trans {
+			t = blas.Trans
+		}
+		return t, blas32.General{Rows: rows, Cols: cols, Stride: cols, Data: data}
+	}
+	ta, ga := general(transA, m, k, a)
+	tb, gb := general(transB, k, n, b)
+	blas32.Gemm(ta, tb, alpha, ga, gb, beta, blas32.General{Rows: m, Cols: n, Stride: n, Data: c})
+}
+
+// Mul multiplies two tensors like tf32.Mul with the Backend pointed to by the backend option. The output
+// has a row for each row of b and a column for each row of a.
+func Mul(k tf32.Continuation, node int, a, b *tf32.V, options ...map[string]interface{}) bool {
+	if len(a.S) != 2 || len(b.S) != 2 {
+		panic("tensor needs to have two dimensions")
+	}
+	width := a.S[0]
+	if width != b.S[0] {
+		panic("first dimension is not the same")
+	}
+	backend := *options[0]["backend"].(*Backend)
+	rowsA, rowsB := a.S[1], b.S[1]
+	c := tf32.NewV(rowsA, rowsB)
+	c.X = c.X[:cap(c.X)]
+	backend.Gemm(false, true, rowsB, rowsA, width, 1, b.X, a.X, 0, c.X)
+	if k(&c) {
+		return true
+	}
+	backend.Gemm(true, false, rowsA, width, rowsB, 1, c.D, b.X, 1, a.D)
+	backend.Gemm(false, false, rowsB, width, rowsA, 1, c.D, a.X, 1, b.D)
+	return false
+}
+
+// mul multiplies two tensors with the backend of the network, or with tf32.Mul if there is no backend
+func (n *Network) mul(a, b tf32.Meta) tf32.Meta {
+	if n.Config.Backend == nil {
+		return tf32.Mul(a, b)
+	}
+	return tf32.B(Mul)(a, b, map[string]interface{}{
+		"backend": &n.Config.Backend,
+	})
+}
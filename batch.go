@@ -0,0 +1,103 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Batch is a graph that evaluates a batch of queries through the attention blocks of the network in
+// one evaluation. The queries are the rows of a width by size input matrix.
+type Batch struct {
+	Size    int
+	Others  tf32.Set
+	Input   *tf32.V
+	L1      tf32.Meta
+	L2      tf32.Meta
+	Entropy tf32.Meta
+}
+
+// NewBatch creates a batch graph for size queries that shares the weights of the network.
+// Positional encodings aren't supported.
+func (n *Network) NewBatch(size int) *Batch {
+	if n.Config.Encoding != EncodingNone {
+		panic("batches don't support positional encodings")
+	}
+	b := &Batch{
+		Size:   size,
+		Others: tf32.NewSet(),
+	}
+	b.Others.Add("queries", n.Width, size)
+	b.Input = b.Others.ByName["queries"]
+	b.Input.X = b.Input.X[:cap(b.Input.X)]
+	b.L1, b.L2 = n.attention(b.Others.Get("queries"))
+	b.Entropy = tf32.Entropy(b.L2)
+	return b
+}
+
+// load loads the inputs into the rows of the queries, the unused rows are zeroed
+func (b *Batch) load(inputs []Sample, width int) {
+	for i := range b.Input.X {
+		b.Input.X[i] = 0
+	}
+	for i, input := range inputs {
+		for j, measure := range input.Measures {
+			b.Input.X[i*width+j] = float32(measure)
+		}
+	}
+}
+
+// GetEntropyBatch returns the entropy of the l2 output of the network for each input, evaluating size
+// inputs at a time
+func (n *Network) GetEntropyBatch(inputs []Sample, size int) []Entropy {
+	b := n.NewBatch(size)
+	outputs := make([]Entropy, 0, len(inputs))
+	for begin := 0; begin < len(inputs); begin += size {
+		end := begin + size
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		b.load(inputs[begin:end], n.Width)
+		b.Entropy(func(a *tf32.V) bool {
+			for i, sample := range inputs[begin:end] {
+				outputs = append(outputs, Entropy{
+					Entropy:  a.X[i],
+					Label:    sample.Label,
+					Measures: sample.Measures,
+					Index:    begin + i,
+				})
+			}
+			return true
+		})
+	}
+	return rank(outputs)
+}
+
+// GetVectorsBatch returns the l1 attention of the network for each input, evaluating size inputs at a time
+func (n *Network) GetVectorsBatch(inputs []Sample, size int) []Sample {
+	b := n.NewBatch(size)
+	outputs := make([]Sample, 0, len(inputs))
+	for begin := 0; begin < len(inputs); begin += size {
+		end := begin + size
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		b.load(inputs[begin:end], n.Width)
+		b.L1(func(a *tf32.V) bool {
+			for i, sample := range inputs[begin:end] {
+				vectors := make([]float64, n.Length)
+				for j, x := range a.X[i*n.Length : (i+1)*n.Length] {
+					vectors[j] = float64(x)
+				}
+				outputs = append(outputs, Sample{
+					Measures: vectors,
+					Label:    sample.Label,
+				})
+			}
+			return true
+		})
+	}
+	return outputs
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// MutualInformation computes the mutual information in bits between the samples and the soft cluster
+// assignments given by the attention over the points, I(X;C) = H(C) - H(C|X)
+func (n *Network) MutualInformation(samples [][]float64) (information, clusters float64) {
+	marginal := make([]float64, n.Length)
+	conditional := 0.0
+	for _, sample := range samples {
+		attention := n.Features(sample, LayerL1)
+		for i, a := range attention {
+			p := float64(a)
+			marginal[i] += p
+			if p > 0 {
+				conditional -= p * math.Log2(p)
+			}
+		}
+	}
+	conditional /= float64(len(samples))
+	for _, m := range marginal {
+		p := m / float64(len(samples))
+		if p > 0 {
+			clusters -= p * math.Log2(p)
+		}
+	}
+	return clusters - conditional, clusters
+}
+
+// Bottleneck is a point on the information bottleneck curve
+type Bottleneck struct {
+	Length      int
+	Temperature float32
+	// Compression is log2 of the number of points
+	Compression float64
+	// Information is the mutual information between the samples and the cluster assignments
+	Information float64
+	// Clusters is the entropy of the cluster assignments
+	Clusters float64
+}
+
+// InformationBottleneck trains a network for steps iterations for each number of points in lengths and
+// each temperature, and returns the mutual information retained between the samples and the cluster
+// assignments. If temperatures is empty the temperature of the configuration is used.
+func InformationBottleneck(samples [][]float64, config NetworkConfig, lengths []int, temperatures []float32, steps int) []Bottleneck {
+	if len(temperatures) == 0 {
+		temperatures = []float32{config.Temperature}
+	}
+	width := len(samples[0])
+	curve := make([]Bottleneck, 0, len(lengths)*len(temperatures))
+	for _, length := range lengths {
+		for _, temperature := range temperatures {
+			config.Temperature = temperature
+			n := NewNetworkWithConfig(width, length, config)
+			n.initialize(samples)
+			for n.I <= steps {
+				total := n.Iterate(samples[n.Rnd.Intn(len(samples))])
+				if !finite(total) {
+					break
+				}
+			}
+			information, clusters := n.MutualInformation(samples)
+			curve = append(curve, Bottleneck{
+				Length:      length,
+				Temperature: temperature,
+				Compression: math.Log2(float64(length)),
+				Information: information,
+				Clusters:    clusters,
+			})
+		}
+	}
+	return curve
+}
+
+// MDL is a minimum description length score of a network in bits
+type MDL struct {
+	// Model is the number of bits to encode the points, each parameter is encoded with log2(N)/2 bits
+	Model float64
+	// Data is the number of bits to encode the residual entropy of the cluster assignments of the samples
+	Data float64
+	// Total is the sum of the model and data bits
+	Total float64
+}
+
+// MDL computes the minimum description length score of the network on the samples, so networks with
+// different widths and numbers of points can be compared on a complexity versus fit axis
+func (n *Network) MDL(samples [][]float64) MDL {
+	parameters := float64(0)
+	for _, layer := range n.Layers {
+		parameters += float64(len(layer.X))
+	}
+	model := parameters * math.Log2(float64(len(samples))) / 2
+	data := 0.0
+	for _, sample := range samples {
+		for _, a := range n.Features(sample, LayerL1) {
+			p := float64(a)
+			if p > 0 {
+				data -= p * math.Log2(p)
+			}
+		}
+	}
+	return MDL{
+		Model: model,
+		Data:  data,
+		Total: model + data,
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Layer is a layer of the network
+type Layer int
+
+const (
+	// LayerL1 is the attention over the points
+	LayerL1 Layer = iota
+	// LayerL2 is the output of the attention
+	LayerL2
+	// LayerGradient is the partial derivatives of the cost with respect to the points, ordered by point
+	LayerGradient
+)
+
+// Features returns the output of the layer of the network for the input
+func (n *Network) Features(input []float64, layer Layer) []float32 {
+	if layer == LayerGradient {
+		return n.gradient([][]float64{input})
+	}
+	for i, measure := range input {
+		n.Input.X[i] = float32(measure)
+	}
+	meta := n.L1
+	if layer == LayerL2 {
+		meta = n.L2
+	}
+	var features []float32
+	meta(func(a *tf32.V) bool {
+		features = make([]float32, len(a.X))
+		copy(features, a.X)
+		return true
+	})
+	return features
+}
+
+// Project returns the output of the layer of the network for each input
+func (n *Network) Project(inputs []Sample, layer Layer) [][]float32 {
+	outputs := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		outputs[i] = n.Features(input.Measures, layer)
+	}
+	return outputs
+}
+
+// Attention returns the l1 attention over the points for the measures
+func (n *Network) Attention(measures []float64) []float32 {
+	return n.Features(measures, LayerL1)
+}
+
+// AttentionMatrix returns the l1 attention of each point over all of the points, with the point rows
+// as the queries
+func (n *Network) AttentionMatrix() [][]float32 {
+	b := n.NewBatch(n.Length)
+	copy(b.Input.X, n.Point.X)
+	matrix := make([][]float32, n.Length)
+	b.L1(func(a *tf32.V) bool {
+		for i := range matrix {
+			matrix[i] = make([]float32, n.Length)
+			copy(matrix[i], a.X[i*n.Length:(i+1)*n.Length])
+		}
+		return true
+	})
+	return matrix
+}
+
+// width is the number of features of a layer
+func (n *Network) width(layer Layer) int {
+	if layer == LayerL2 {
+		return n.Width
+	}
+	return n.Length
+}
+
+// Classifier is a supervised softmax classifier over the features of a layer of a network
+type Classifier struct {
+	Network *Network
+	Layer   Layer
+	Classes int
+	Epochs  int
+	Eta     float32
+	Set     tf32.Set
+	Points  []float32
+	// Width is the number of features
+	Width int
+	// Features returns the features of an input, the output of the layer of the network by default
+	Features func(input []float64) []float32
+	// Rnd initializes the weights
+	Rnd   *rand.Rand
+	input *tf32.V
+	l1    tf32.Meta
+}
+
+// NewClassifier creates a new classifier over the features of a layer of the network
+func NewClassifier(n *Network, layer Layer, classes int) *Classifier {
+	return &Classifier{
+		Network: n,
+		Layer:   layer,
+		Classes: classes,
+		Epochs:  8 * 1024,
+		Eta:     .1,
+		Width:   n.width(layer),
+		Features: func(input []float64) []float32 {
+			return n.Features(input, layer)
+		},
+		Rnd: n.Rnd,
+	}
+}
+
+// Fit trains the classifier on the inputs and their labels and returns the final cost
+func (c *Classifier) Fit(inputs [][]float64, labels []int) float32 {
+	width, length := c.Width, len(inputs)
+
+	others := tf32.NewSet()
+	others.Add("inputs", width, length)
+	in := others.ByName["inputs"]
+	for _, input := range inputs {
+		in.X = append(in.X, c.Features(input)...)
+	}
+	others.Add("targets", c.Classes, length)
+	targets := others.ByName["targets"]
+	targets.X = targets.X[:cap(targets.X)]
+	for i, label := range labels {
+		targets.X[i*c.Classes+label] = 1
+	}
+
+	c.Set = tf32.NewSet()
+	c.Set.Add("weights", width, c.Classes)
+	weights := c.Set.ByName["weights"]
+	weights.X = weights.X[:cap(weights.X)]
+	He{}.Initialize(c.Rnd, weights.X, width, c.Classes)
+	weights.States = make([][]float32, StateTotal)
+	for i := range weights.States {
+		weights.States[i] = make([]float32, len(weights.X))
+	}
+	c.Set.Add("bias", c.Classes, 1)
+	bias := c.Set.ByName["bias"]
+	bias.X = bias.X[:cap(bias.X)]
+	bias.States = make([][]float32, StateTotal)
+	for i := range bias.States {
+		bias.States[i] = make([]float32, len(bias.X))
+	}
+
+	softmax := tf32.U(SphericalSoftmax)
+	l1 := softmax(tf32.Add(tf32.Mul(c.Set.Get("weights"), others.Get("inputs")), c.Set.Get("bias")))
+	cost := tf32.Avg(tf32.CrossEntropy(l1, others.Get("targets")))
+
+	c.Points = make([]float32, 0, c.Epochs)
+	total := float32(0.0)
+	for i := 1; i <= c.Epochs; i++ {
+		total = tf32.Gradient(cost).X[0]
+		if math.IsNaN(float64(total)) {
+			break
+		}
+		NewAdam().Update(&c.Set, i, c.Eta, nil)
+		c.Set.Zero()
+		others.Zero()
+		c.Points = append(c.Points, total)
+	}
+
+	c.input = nil
+	return total
+}
+
+// Predict returns the predicted class of the input and the class probabilities
+func (c *Classifier) Predict(input []float64) (int, []float32) {
+	if c.input == nil {
+		others := tf32.NewSet()
+		others.Add("input", c.Width, 1)
+		c.input = others.ByName["input"]
+		c.input.X = c.input.X[:cap(c.input.X)]
+		softmax := tf32.U(SphericalSoftmax)
+		c.l1 = softmax(tf32.Add(tf32.Mul(c.Set.Get("weights"), others.Get("input")), c.Set.Get("bias")))
+	}
+	copy(c.input.X, c.Features(input))
+	var probabilities []float32
+	c.l1(func(a *tf32.V) bool {
+		probabilities = make([]float32, len(a.X))
+		copy(probabilities, a.X)
+		return true
+	})
+	index, max := 0, float32(0.0)
+	for i, p := range probabilities {
+		if p > max {
+			index, max = i, p
+		}
+	}
+	return index, probabilities
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// clip records the global norm of the gradients and clips them by value and then by the global norm
+// if the configuration enables clipping
+func (n *Network) clip() {
+	sum := 0.0
+	for _, w := range n.Set.Weights {
+		for _, d := range w.D {
+			sum += float64(d) * float64(d)
+		}
+	}
+	n.norm = float32(math.Sqrt(sum))
+
+	if value := n.Config.ClipValue; value > 0 {
+		sum = 0
+		for _, w := range n.Set.Weights {
+			for i, d := range w.D {
+				if d > value {
+					d = value
+				} else if d < -value {
+					d = -value
+				}
+				w.D[i] = d
+				sum += float64(d) * float64(d)
+			}
+		}
+	}
+
+	if norm := float64(n.Config.ClipNorm); norm > 0 {
+		if total := math.Sqrt(sum); total > norm {
+			scale := float32(norm / total)
+			for _, w := range n.Set.Weights {
+				for i := range w.D {
+					w.D[i] *= scale
+				}
+			}
+		}
+	}
+}
+
+// LastGradientNorm returns the global norm of the gradients of the last step before they were clipped,
+// which grows large before the cost explodes
+func (n *Network) LastGradientNorm() float32 {
+	return n.norm
+}
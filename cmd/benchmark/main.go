@@ -0,0 +1,175 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/synthetic"
+)
+
+var (
+	// FlagData is the comma separated list of data sets
+	FlagData = flag.String("data", "blobs,circles,moons", "comma separated data sets: blobs, circles, or moons")
+	// FlagSizes is the comma separated list of numbers of samples
+	FlagSizes = flag.String("sizes", "128,512", "comma separated numbers of samples")
+	// FlagWidths is the comma separated list of numbers of features
+	FlagWidths = flag.String("widths", "2,8", "comma separated numbers of features")
+	// FlagActivations is the comma separated list of activations
+	FlagActivations = flag.String("activations", "softmax,spherical", "comma separated activations: softmax or spherical")
+	// FlagOptimizers is the comma separated list of optimizers
+	FlagOptimizers = flag.String("optimizers", "adam", "comma separated optimizers: adam, adamw, sgd, or rmsprop")
+	// FlagPoints is the number of points of the network
+	FlagPoints = flag.Int("points", 32, "number of points")
+	// FlagEpochs is the number of passes over the samples
+	FlagEpochs = flag.Int("epochs", 8, "number of passes over the samples")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "warn", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	// FlagSeed is the seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
+)
+
+// Activations are the activations by name
+var Activations = map[string]occam.Activation{
+	"softmax":   occam.ActivationSoftmax,
+	"spherical": occam.ActivationSpherical,
+}
+
+// Optimizers are the optimizers by name
+var Optimizers = map[string]func() occam.Optimizer{
+	"adam":    func() occam.Optimizer { return occam.NewAdam() },
+	"adamw":   func() occam.Optimizer { return occam.NewAdamW(.01) },
+	"sgd":     func() occam.Optimizer { return occam.NewSGD(.9) },
+	"rmsprop": func() occam.Optimizer { return occam.NewRMSProp() },
+}
+
+// integers parses a comma separated list of integers
+func integers(list string) []int {
+	values := make([]int, 0, 8)
+	for _, part := range strings.Split(list, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			panic(err)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Result is the result of a benchmark run
+type Result struct {
+	Data       string
+	Size       int
+	Width      int
+	Activation string
+	Optimizer  string
+	Evaluation occam.Evaluation
+	// Throughput is the number of training steps per second
+	Throughput float64
+}
+
+func main() {
+	flag.Parse()
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
+	names := strings.Split(*FlagData, ",")
+	activations := strings.Split(*FlagActivations, ",")
+	optimizers := strings.Split(*FlagOptimizers, ",")
+	sizes, widths := integers(*FlagSizes), integers(*FlagWidths)
+	results := make([]Result, 0, 8)
+	for _, name := range names {
+		generate, ok := synthetic.Generators[name]
+		if !ok {
+			panic(fmt.Sprintf("unknown data set %s", name))
+		}
+		for _, size := range sizes {
+			for _, width := range widths {
+				data := generate(rand.New(rand.NewSource(*FlagSeed)), size, width)
+				for _, activation := range activations {
+					a, ok := Activations[activation]
+					if !ok {
+						panic(fmt.Sprintf("unknown activation %s", activation))
+					}
+					for _, o := range optimizers {
+						optimizer, ok := Optimizers[o]
+						if !ok {
+							panic(fmt.Sprintf("unknown optimizer %s", o))
+						}
+						config := occam.DefaultNetworkConfig()
+						config.Activation = a
+						n := occam.NewNetworkWithConfig(width, *FlagPoints, config,
+							occam.WithOptimizer(optimizer()), occam.WithSeed(*FlagSeed))
+						n.Logger = logger
+						n.DisableHistory = true
+
+						// Set the points to randomly selected samples
+						indexes := n.Rnd.Perm(len(data))
+						for i := 0; i < n.Length; i++ {
+							for j, measure := range data[indexes[i%len(indexes)]].Measures {
+								n.Point.X[i*width+j] = float32(measure)
+							}
+						}
+
+						start := time.Now()
+						for epoch := 0; epoch < *FlagEpochs; epoch++ {
+							n.Rnd.Shuffle(len(indexes), func(i, j int) {
+								indexes[i], indexes[j] = indexes[j], indexes[i]
+							})
+							for _, index := range indexes {
+								total := n.Iterate(data[index].Measures)
+								if math.IsNaN(float64(total)) {
+									logger.Error("cost is nan", "data", name, "step", n.I)
+									break
+								}
+							}
+						}
+						elapsed := time.Since(start)
+
+						result := Result{
+							Data:       name,
+							Size:       size,
+							Width:      width,
+							Activation: activation,
+							Optimizer:  o,
+							Evaluation: n.Evaluate(data),
+							Throughput: float64(n.I-1) / elapsed.Seconds(),
+						}
+						logger.Info("result", "data", name, "size", size, "width", width, "activation", activation,
+							"optimizer", o, "accuracy", result.Evaluation.Accuracy, "throughput", result.Throughput)
+						results = append(results, result)
+					}
+				}
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(w, "data\tsize\twidth\tactivation\toptimizer\tentropy\tclusters\tpurity\tnmi\taccuracy\tsteps/s")
+	for _, result := range results {
+		e := result.Evaluation
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%.4f\t%d\t%.4f\t%.4f\t%.4f\t%.0f\n", result.Data, result.Size, result.Width,
+			result.Activation, result.Optimizer, e.Entropy, e.Clusters, e.Purity, e.NMI, e.Accuracy, result.Throughput)
+	}
+	w.Flush()
+}
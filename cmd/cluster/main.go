@@ -0,0 +1,158 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/pointlander/occam"
+)
+
+var (
+	// FlagInput is the csv file
+	FlagInput = flag.String("input", "", "csv file to cluster")
+	// FlagLabel is the column of the labels
+	FlagLabel = flag.Int("label", -1, "column of the labels, -1 if the rows aren't labeled")
+	// FlagStandardize is the flag to standardize the columns
+	FlagStandardize = flag.Bool("standardize", false, "scale the columns to zero mean and unit variance")
+	// FlagPoints is the number of points of the network
+	FlagPoints = flag.Int("points", 0, "number of points, 0 uses the number of rows up to 1024")
+	// FlagEpochs is the number of passes over the rows
+	FlagEpochs = flag.Int("epochs", 16, "number of passes over the rows")
+	// FlagRBF scores the points with an rbf kernel
+	FlagRBF = flag.Bool("rbf", false, "score the points with an rbf kernel instead of the dot product")
+	// FlagOutput is the prefix of the output files
+	FlagOutput = flag.String("output", "cluster", "prefix of the output files")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	// FlagSeed is the seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
+)
+
+func main() {
+	flag.Parse()
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
+	if *FlagInput == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	in, err := os.Open(*FlagInput)
+	if err != nil {
+		panic(err)
+	}
+	inputs, err := occam.LoadCSV(in, *FlagLabel)
+	in.Close()
+	if err != nil {
+		panic(err)
+	}
+	if len(inputs) == 0 {
+		panic("no rows")
+	}
+	var normalization occam.Normalization
+	if *FlagStandardize {
+		normalization = occam.Standardize(inputs)
+	}
+	width := len(inputs[0].Measures)
+	samples := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		samples[i] = input.Measures
+	}
+	logger.Info("loaded", "rows", len(inputs), "width", width)
+
+	length := *FlagPoints
+	if length <= 0 {
+		length = len(inputs)
+		if length > 1024 {
+			length = 1024
+		}
+	}
+	config := occam.DefaultNetworkConfig()
+	if *FlagRBF {
+		config.Kernel = occam.KernelRBF
+	}
+	n := occam.NewNetworkWithConfig(width, length, config, occam.WithSeed(*FlagSeed))
+	n.Logger = logger
+	n.Normalization = normalization
+	n.OnStep = occam.Steps(n.History(), occam.LogSteps(logger))
+
+	// Set the points to randomly selected rows
+	indexes := n.Rnd.Perm(len(inputs))
+	for i := 0; i < length; i++ {
+		for j, measure := range samples[indexes[i%len(indexes)]] {
+			n.Point.X[i*width+j] = float32(measure)
+		}
+	}
+
+	// The stochastic gradient descent loop
+	for epoch := 0; epoch < *FlagEpochs && err == nil; epoch++ {
+		n.Rnd.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+		for _, index := range indexes {
+			_, err = n.TryIterate(samples[index])
+			if err != nil {
+				logger.Error("training stopped", "step", n.I, "error", err)
+				break
+			}
+		}
+	}
+
+	err = n.SaveCheckpoint(*FlagOutput + "_set.w")
+	if err != nil {
+		panic(err)
+	}
+
+	// Write the cluster assignments
+	entropy := n.GetEntropyParallel(inputs, 0)
+	out, err := os.Create(*FlagOutput + "_assignments.csv")
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	writer := csv.NewWriter(out)
+	err = writer.Write([]string{"row", "label", "cluster", "entropy"})
+	if err != nil {
+		panic(err)
+	}
+	clusters := make(map[int]int)
+	for i, sample := range samples {
+		cluster := n.Cluster(sample)
+		clusters[cluster]++
+		err = writer.Write([]string{
+			strconv.Itoa(i),
+			inputs[i].Label,
+			strconv.Itoa(cluster),
+			strconv.FormatFloat(float64(entropy[i].Entropy), 'f', -1, 32),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		panic(err)
+	}
+
+	if *FlagLabel >= 0 {
+		fmt.Println(n.Evaluate(inputs))
+	}
+	logger.Info("done", "clusters", len(clusters))
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/vis"
+)
+
+var (
+	// FlagInput is the embedding file
+	FlagInput = flag.String("input", "", "embedding file: .vec, .vec.gz, .bin, .csv, or .npy")
+	// FlagPoints is the number of points of the network
+	FlagPoints = flag.Int("points", 0, "number of points, 0 uses the number of embeddings up to 1024")
+	// FlagEpochs is the number of passes over the embeddings
+	FlagEpochs = flag.Int("epochs", 16, "number of passes over the embeddings")
+	// FlagNormalize is the flag to normalize the embeddings
+	FlagNormalize = flag.Bool("normalize", false, "normalize the embeddings to unit length")
+	// FlagRBF scores the points with an rbf kernel
+	FlagRBF = flag.Bool("rbf", false, "score the points with an rbf kernel instead of the dot product")
+	// FlagEval evaluates a checkpoint without training
+	FlagEval = flag.String("eval", "", "evaluate the checkpoint on the embeddings without training")
+	// FlagOutput is the prefix of the output files
+	FlagOutput = flag.String("output", "embedding", "prefix of the output files")
+	// FlagPlotter is the plotting backend
+	FlagPlotter = flag.String("plotter", "gonum", "plotting backend: gonum, echarts, vega, or none")
+	// FlagFormat is the image format of the plots
+	FlagFormat = flag.String("format", "png", "image format of the plots: png, svg, or pdf")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	// FlagSeed is the seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
+)
+
+func main() {
+	flag.Parse()
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
+	if *FlagInput == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	embeddings, err := Read(*FlagInput)
+	if err != nil {
+		panic(err)
+	}
+	if len(embeddings) == 0 {
+		panic("no embeddings")
+	}
+	width := len(embeddings[0].Vector)
+	normalization := occam.Normalization{Kind: occam.NormalizeUnit}
+	samples := make([][]float64, len(embeddings))
+	inputs := make([]occam.Sample, len(embeddings))
+	for i, embedding := range embeddings {
+		if len(embedding.Vector) != width {
+			panic(fmt.Sprintf("embedding %s has width %d but should have width %d",
+				embedding.Label, len(embedding.Vector), width))
+		}
+		if *FlagNormalize {
+			normalization.Apply(embedding.Vector)
+		}
+		samples[i] = embedding.Vector
+		inputs[i] = occam.Sample{
+			Measures: embedding.Vector,
+			Label:    embedding.Label,
+		}
+	}
+	logger.Info("loaded", "embeddings", len(embeddings), "width", width)
+
+	length := *FlagPoints
+	if length <= 0 {
+		length = len(embeddings)
+		if length > 1024 {
+			length = 1024
+		}
+	}
+	config := occam.DefaultNetworkConfig()
+	if *FlagRBF {
+		config.Kernel = occam.KernelRBF
+	}
+	if *FlagEval != "" {
+		n, err := occam.OpenNetwork(*FlagEval, config)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(n.Evaluate(inputs))
+		return
+	}
+	n := occam.NewNetworkWithConfig(width, length, config, occam.WithSeed(*FlagSeed))
+	n.Logger = logger
+	if *FlagNormalize {
+		n.Normalization = normalization
+	}
+	n.OnStep = occam.Steps(n.History(), occam.LogSteps(logger))
+
+	// Set the points to randomly selected embeddings
+	indexes := n.Rnd.Perm(len(embeddings))
+	for i := 0; i < length; i++ {
+		embedding := embeddings[indexes[i%len(indexes)]]
+		for j, value := range embedding.Vector {
+			n.Point.X[i*width+j] = float32(value)
+		}
+	}
+
+	// The stochastic gradient descent loop
+	for epoch := 0; epoch < *FlagEpochs && err == nil; epoch++ {
+		n.Rnd.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+		for _, index := range indexes {
+			_, err = n.TryIterate(samples[index])
+			if err != nil {
+				logger.Error("training stopped", "step", n.I, "error", err)
+				break
+			}
+		}
+	}
+
+	// Plot the cost
+	plotter, err := vis.New(*FlagPlotter, *FlagFormat)
+	if err != nil {
+		panic(err)
+	}
+	err = plotter.Plot(vis.Cost(n.Points), *FlagOutput+"_cost"+plotter.Extension())
+	if err != nil {
+		panic(err)
+	}
+
+	err = n.SaveCheckpoint(*FlagOutput + "_set.w")
+	if err != nil {
+		panic(err)
+	}
+
+	// Write the cluster assignments
+	entropy := n.GetEntropyParallel(inputs, 0)
+	clusters := make([]int, len(samples))
+	for i, sample := range samples {
+		clusters[i] = n.Cluster(sample)
+	}
+	out, err := os.Create(*FlagOutput + "_assignments.csv")
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	writer := csv.NewWriter(out)
+	err = writer.Write([]string{"label", "cluster", "entropy"})
+	if err != nil {
+		panic(err)
+	}
+	for i, embedding := range embeddings {
+		err = writer.Write([]string{
+			embedding.Label,
+			strconv.Itoa(clusters[i]),
+			strconv.FormatFloat(float64(entropy[i].Entropy), 'f', -1, 32),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		panic(err)
+	}
+
+	// Write the report
+	report, err := os.Create(*FlagOutput + "_report.txt")
+	if err != nil {
+		panic(err)
+	}
+	defer report.Close()
+	w := bufio.NewWriter(report)
+	defer w.Flush()
+	mdl := n.MDL(samples)
+	fmt.Fprintf(w, "embeddings %d\nwidth %d\npoints %d\nsteps %d\n", len(embeddings), width, length, n.I-1)
+	if length := len(n.Points); length > 0 {
+		fmt.Fprintf(w, "cost %f\n", n.Points[length-1].Y)
+	}
+	fmt.Fprintf(w, "mdl model %f data %f total %f bits\n", mdl.Model, mdl.Data, mdl.Total)
+
+	prototypes := n.Prototypes(samples)
+	fmt.Fprintf(w, "clusters %d\n\n", len(prototypes))
+	members := make(map[int][]int)
+	for i, cluster := range clusters {
+		members[cluster] = append(members[cluster], i)
+	}
+	sort.Slice(prototypes, func(i, j int) bool {
+		return prototypes[i].Count > prototypes[j].Count
+	})
+	for _, prototype := range prototypes {
+		indexes := members[prototype.Cluster]
+		sum := 0.0
+		for _, index := range indexes {
+			sum += float64(entropy[index].Entropy)
+		}
+		fmt.Fprintf(w, "cluster %d count %d entropy %f medoid %s\n", prototype.Cluster, prototype.Count,
+			sum/float64(len(indexes)), embeddings[prototype.Medoid].Label)
+		for i, index := range indexes {
+			if i == 16 {
+				fmt.Fprintf(w, " ...")
+				break
+			}
+			fmt.Fprintf(w, " %s", embeddings[index].Label)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	logger.Info("done", "clusters", len(prototypes), "mdl", mdl.Total)
+}
@@ -0,0 +1,220 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pointlander/occam/embeddings"
+)
+
+// Embedding is a labeled feature vector
+type Embedding struct {
+	Label  string
+	Vector []float64
+}
+
+// Read reads the embeddings in a file, the format is chosen by the extension of the file:
+// .vec, .vec.gz, .bin, and .bin.gz are word vectors, .csv is comma separated values, and .npy is a numpy array
+func Read(path string) ([]Embedding, error) {
+	switch {
+	case strings.HasSuffix(path, ".vec"), strings.HasSuffix(path, ".vec.gz"),
+		strings.HasSuffix(path, ".bin"), strings.HasSuffix(path, ".bin.gz"):
+		return ReadVec(path)
+	case strings.HasSuffix(path, ".csv"):
+		return ReadCSV(path)
+	case strings.HasSuffix(path, ".npy"):
+		return ReadNpy(path)
+	}
+	return nil, fmt.Errorf("unknown format of %s", path)
+}
+
+// open opens a file that is optionally gzip compressed
+func open(path string) (io.ReadCloser, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return in, nil
+	}
+	reader, err := gzip.NewReader(in)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, in}, nil
+}
+
+// ReadVec reads word vectors in the fastText text or word2vec binary formats
+func ReadVec(path string) ([]Embedding, error) {
+	vectors, err := embeddings.Load(path, embeddings.Options{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Embedding, 0, len(vectors.List))
+	for _, vector := range vectors.List {
+		embedding := Embedding{
+			Label:  vector.Word,
+			Vector: make([]float64, len(vector.Vector)),
+		}
+		for i, value := range vector.Vector {
+			embedding.Vector[i] = float64(value)
+		}
+		result = append(result, embedding)
+	}
+	return result, nil
+}
+
+// ReadCSV reads comma separated values. A first row that isn't numeric is a header and is skipped,
+// and a first column that isn't numeric is the label of the row.
+func ReadCSV(path string) ([]Embedding, error) {
+	in, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	numeric := func(value string) bool {
+		_, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil
+	}
+	if len(records) > 0 && len(records[0]) > 1 && !numeric(records[0][1]) {
+		records = records[1:]
+	}
+	embeddings := make([]Embedding, 0, len(records))
+	for i, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		embedding := Embedding{
+			Label: strconv.Itoa(i),
+		}
+		if !numeric(record[0]) {
+			embedding.Label, record = record[0], record[1:]
+		}
+		for _, field := range record {
+			value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+			embedding.Vector = append(embedding.Vector, value)
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, nil
+}
+
+// ReadNpy reads a two dimensional little endian float32 or float64 numpy array in c order.
+// The rows are labeled by their index.
+func ReadNpy(path string) ([]Embedding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 10 || !bytes.Equal(data[:6], []byte("\x93NUMPY")) {
+		return nil, errors.New("not a numpy file")
+	}
+	var length, offset int
+	switch data[6] {
+	case 1:
+		length, offset = int(binary.LittleEndian.Uint16(data[8:10])), 10
+	case 2, 3:
+		if len(data) < 12 {
+			return nil, errors.New("truncated numpy header")
+		}
+		length, offset = int(binary.LittleEndian.Uint32(data[8:12])), 12
+	default:
+		return nil, fmt.Errorf("unsupported numpy version %d", data[6])
+	}
+	if len(data) < offset+length {
+		return nil, errors.New("truncated numpy header")
+	}
+	header := string(data[offset : offset+length])
+	data = data[offset+length:]
+
+	field := func(name string) string {
+		index := strings.Index(header, "'"+name+"'")
+		if index < 0 {
+			return ""
+		}
+		value := strings.TrimSpace(header[index+len(name)+2:])
+		value = strings.TrimSpace(strings.TrimPrefix(value, ":"))
+		return value
+	}
+	if strings.HasPrefix(field("fortran_order"), "True") {
+		return nil, errors.New("fortran order isn't supported")
+	}
+	size := 0
+	descr := field("descr")
+	switch {
+	case strings.HasPrefix(descr, "'<f4'"):
+		size = 4
+	case strings.HasPrefix(descr, "'<f8'"):
+		size = 8
+	default:
+		return nil, fmt.Errorf("unsupported type %s", descr)
+	}
+	shape := field("shape")
+	if !strings.HasPrefix(shape, "(") || !strings.Contains(shape, ")") {
+		return nil, fmt.Errorf("invalid shape %s", shape)
+	}
+	dimensions := make([]int, 0, 2)
+	for _, part := range strings.Split(shape[1:strings.Index(shape, ")")], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		dimension, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		dimensions = append(dimensions, dimension)
+	}
+	if len(dimensions) != 2 {
+		return nil, fmt.Errorf("array has %d dimensions but should have 2", len(dimensions))
+	}
+	rows, columns := dimensions[0], dimensions[1]
+	if len(data) < rows*columns*size {
+		return nil, errors.New("truncated numpy data")
+	}
+
+	embeddings := make([]Embedding, rows)
+	for i := range embeddings {
+		vector := make([]float64, columns)
+		for j := range vector {
+			value := data[(i*columns+j)*size:]
+			if size == 4 {
+				vector[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(value)))
+			} else {
+				vector[j] = math.Float64frombits(binary.LittleEndian.Uint64(value))
+			}
+		}
+		embeddings[i] = Embedding{
+			Label:  strconv.Itoa(i),
+			Vector: vector,
+		}
+	}
+	return embeddings, nil
+}
@@ -5,50 +5,30 @@
 package main
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"image/png"
-	"io"
+	"log/slog"
 	"math"
-	"math/rand"
 	"os"
-	"sort"
-	"strconv"
-	"strings"
+	"os/signal"
 	"time"
 
 	"github.com/pointlander/gradient/tf32"
-
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
-	"gonum.org/v1/plot/vg/draw"
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/embeddings"
+	"github.com/pointlander/occam/vis"
 )
 
 const (
-	// B1 exponential decay of the rate for the first moment estimates
-	B1 = 0.9
-	// B2 exponential decay rate for the second-moment estimates
-	B2 = 0.999
-	// S is the scaling factor for the softmax
-	S = 1.0 - 1e-300
 	// Eta is the learning rate
 	Eta = .001
 )
 
-const (
-	// StateM is the state for the mean
-	StateM = iota
-	// StateV is the state for the variance
-	StateV
-	// StateTotal is the total number of states
-	StateTotal
-)
-
 // PartOfSpeech is a part of speech with examples
 var PartsOfSpeech = map[string][10]string{
 	"noun":         {"city", "new york", "banana", "bananas", "family", "ice cream", "table", "anger"},
@@ -61,186 +41,115 @@ var PartsOfSpeech = map[string][10]string{
 	"interjection": {"ouch", "wow", "oh", "ah", "oops", "yikes", "yay", "yuck", "yippee", "yup"},
 }
 
-// Vector is a word vector
-type Vector struct {
-	Word   string
-	Vector []float32
-}
+var (
+	//FlagInfer inference mode
+	FlagInfer = flag.String("infer", "", "inference mode with the _network.gob or _set.w file saved by training")
+	//FlagFormat image format of the plots
+	FlagFormat = flag.String("format", "png", "image format of the plots: png, svg, or pdf")
+	//FlagTrain train mode
+	FlagTrain = flag.String("train", "en", "train mode: en, de, or curriculum")
+	//FlagLevel level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	//FlagJSON write the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	//FlagBackend matrix multiplication backend of the attention
+	FlagBackend = flag.String("backend", "tf32", "matrix multiplication backend of the attention: tf32 or blas")
+	//FlagExport export the network in a portable format
+	FlagExport = flag.String("export", "", "export the network in a portable format: json or binary")
+	//FlagSeed seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
+	//FlagResume resume training from a saved network
+	FlagResume = flag.String("resume", "", "resume training from a network saved by a previous run")
+	//FlagParallelism number of workers of the softmax
+	FlagParallelism = flag.Int("parallelism", 0, "number of workers of the softmax, 0 uses the number of cpus")
+	//FlagSchedule learning rate schedule
+	FlagSchedule = flag.String("schedule", "constant", "learning rate schedule: constant, step, or cosine")
+	//FlagRetries number of rollbacks when the cost isn't finite
+	FlagRetries = flag.Int("retries", 0, "number of rollbacks with a halved learning rate when the cost isn't finite, 0 disables recovery")
+	//FlagClip global norm the gradients are clipped to
+	FlagClip = flag.Float64("clip", 0, "global norm the gradients of each step are clipped to, 0 disables clipping")
+	//FlagCheckpoint save the network every n steps
+	FlagCheckpoint = flag.Int("checkpoint", 1024, "save the network every n steps, 0 only saves at the end")
+	//FlagEnglish english word vectors
+	FlagEnglish = flag.String("en", "cc.en.300.vec.gz", "english word vectors: .vec, .vec.gz, or word2vec .bin")
+	//FlagGerman german word vectors
+	FlagGerman = flag.String("de", "cc.de.300.vec.gz", "german word vectors: .vec, .vec.gz, or word2vec .bin")
+	//FlagRows maximum number of word vectors loaded
+	FlagRows = flag.Int("rows", 0, "maximum number of word vectors loaded from each file, 0 loads all")
+	//FlagCache train from on-disk stores of the word vectors
+	FlagCache = flag.Int("cache", 0, "train from on-disk stores of the word vectors with a cache of n vectors, 0 loads all of the vectors into memory")
+)
 
-// Vectors is a set of word vectors
-type Vectors struct {
-	List       []Vector
-	Dictionary map[string]Vector
-}
+func main() {
+	flag.Parse()
 
-// NewVectors creates a new word vector set
-func NewVectors(file string) Vectors {
-	vectors := Vectors{
-		Dictionary: make(map[string]Vector),
-	}
-	in, err := os.Open(file)
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
 	if err != nil {
 		panic(err)
 	}
-	defer in.Close()
-
-	gzipReader, err := gzip.NewReader(in)
-	if err != nil {
-		panic(err)
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
+	// The word vectors are only loaded if they are used
+	vectors := embeddings.Options{
+		MaxRows:   *FlagRows,
+		Lower:     true,
+		Normalize: true,
 	}
-	reader := bufio.NewReader(gzipReader)
-	for {
-		line, err := reader.ReadString('\n')
+	english := embeddings.NewLazy(*FlagEnglish, vectors)
+	german := embeddings.NewLazy(*FlagGerman, vectors)
+	load := func(lazy *embeddings.Lazy) *embeddings.Vectors {
+		vectors, err := lazy.Vectors()
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
-		}
-		parts := strings.Split(line, " ")
-		values := make([]float32, 0, len(parts)-1)
-		for _, v := range parts[1:] {
-			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
-			if err != nil {
-				panic(err)
-			}
-			values = append(values, float32(n))
-		}
-		max := float32(0)
-		for _, v := range values {
-			if v < 0 {
-				v = -v
-			}
-			if v > max {
-				max = v
-			}
-		}
-		for i, v := range values {
-			values[i] = v / max
-		}
-		word := strings.ToLower(strings.TrimSpace(parts[0]))
-		vector := Vector{
-			Word:   word,
-			Vector: values,
-		}
-		vectors.List = append(vectors.List, vector)
-		vectors.Dictionary[word] = vector
-		if len(vector.Vector) == 0 {
-			fmt.Println(vector)
-		}
-	}
-	return vectors
-}
-
-// Softmax is the softmax function for big numbers
-func Softmax(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
-	c, size, width := tf32.NewV(a.S...), len(a.X), a.S[0]
-	max := float32(0)
-	for _, v := range a.X {
-		if v > max {
-			max = v
-		}
-	}
-	values := make([]float64, width)
-	for i := 0; i < size; i += width {
-		s := float64(max) * S
-		sum := 0.0
-		for j, ax := range a.X[i : i+width] {
-			values[j] = math.Exp(float64(ax) - s)
-			sum += values[j]
-		}
-		for _, cx := range values {
-			c.X = append(c.X, float32(cx/sum))
+			panic(err)
 		}
+		return vectors
 	}
-	if k(&c) {
-		return true
-	}
-	for i, d := range c.D {
-		cx := c.X[i]
-		a.D[i] += d * (cx - cx*cx)
-	}
-	return false
-}
-
-var (
-	//FlagInfer inference mode
-	FlagInfer = flag.String("infer", "", "inference mode")
-	//FlagTrain train mode
-	FlagTrain = flag.String("train", "en", "train mode")
-)
-
-func main() {
-	flag.Parse()
-	rnd := rand.New(rand.NewSource(1))
-
-	env := NewVectors("cc.en.300.vec.gz")
-	dev := NewVectors("cc.de.300.vec.gz")
 
 	width := 300
 
 	if *FlagInfer != "" {
+		env := load(english)
+		// The dimensions and the configuration are read from the saved network
+		n, err := occam.LoadNetwork(*FlagInfer)
+		if err != nil {
+			panic(err)
+		}
+		width, length := n.Width, n.Length
+		model, err := occam.NewModel(n)
+		if err != nil {
+			panic(err)
+		}
 		others := tf32.NewSet()
 		others.Add("symbols", width, 1)
 		symbols := others.ByName["symbols"]
 		symbols.X = symbols.X[:cap(symbols.X)]
+		points := n.Point
 
-		// Create the weight data matrix
-		set := tf32.NewSet()
-		set.Open(*FlagInfer)
-		points := set.ByName["points"]
-
-		softmax := tf32.U(Softmax)
-		l1 := softmax(tf32.Mul(set.Get("points"), others.Get("symbols")))
-
-		type Point struct {
-			Index int
-			Rank  float32
-		}
-		type Input struct {
-			Points []Point
-			Label  string
-		}
-
-		cluster := func(word string, vectors Vectors) Input {
+		// cluster ranks the points by their attention for the word
+		cluster := func(word string, vectors *embeddings.Vectors) []occam.Neighbor {
 			vector := vectors.Dictionary[word]
+			sample := make([]float64, len(vector.Vector))
 			for i, measure := range vector.Vector {
-				symbols.X[i] = float32(measure)
+				sample[i] = float64(measure)
 			}
-			// Calculate the l1 output of the neural network
-			var input Input
-			l1(func(a *tf32.V) bool {
-				points := make([]Point, 0, width)
-				for j, value := range a.X {
-					points = append(points, Point{
-						Index: j,
-						Rank:  value,
-					})
-				}
-				sort.Slice(points, func(i, j int) bool {
-					return points[i].Rank > points[j].Rank
-				})
-				input = Input{
-					Points: points,
-					Label:  word,
-				}
-				return true
-			})
-			return input
+			return model.Nearest(sample, model.Length)
 		}
 		a := cluster("car", env)
 		b := cluster("truck", env)
-		for _, value := range a.Points {
-			if value.Rank == 0 {
+		for _, value := range a {
+			if value.Attention == 0 {
 				continue
 			}
-			fmt.Printf("%d %f ", value.Index, value.Rank)
+			fmt.Printf("%d %f ", value.Index, value.Attention)
 		}
 		fmt.Printf("\n")
-		for _, value := range b.Points {
-			if value.Rank == 0 {
+		for _, value := range b {
+			if value.Attention == 0 {
 				continue
 			}
-			fmt.Printf("%d %f ", value.Index, value.Rank)
+			fmt.Printf("%d %f ", value.Index, value.Attention)
 		}
 		fmt.Printf("\n")
 
@@ -249,7 +158,7 @@ func main() {
 			for _, word := range words {
 				fmt.Printf("%12s ", part)
 				value := cluster(word, env)
-				for _, point := range value.Points[:20] {
+				for _, point := range value[:20] {
 					common[point.Index]++
 					fmt.Printf("%4d ", point.Index)
 				}
@@ -264,10 +173,10 @@ func main() {
 			fmt.Println(maxIndex)
 		}
 
-		l1 = tf32.Mul(set.Get("points"), others.Get("symbols"))
+		l1 := tf32.Mul(n.Set.Get("points"), others.Get("symbols"))
 
-		g, y := image.NewGray16(image.Rect(0, 0, 1024, 1024)), 0
-		for i := 0; i < width*1024; i += width {
+		g, y := image.NewGray16(image.Rect(0, 0, length, length)), 0
+		for i := 0; i < width*length; i += width {
 			copy(symbols.X, points.X[i:i+width])
 			max, min := float32(0), float32(math.MaxFloat32)
 			l1(func(a *tf32.V) bool {
@@ -321,122 +230,182 @@ func main() {
 		}
 	}*/
 
-	i := 1
-	pow := func(x float32) float32 {
-		y := math.Pow(float64(x), float64(i))
-		if math.IsNaN(y) || math.IsInf(y, 0) {
-			return 0
-		}
-		return float32(y)
+	options := []occam.Option{
+		occam.WithLearningRate(Eta),
+		occam.WithParallelism(*FlagParallelism),
+		occam.WithSeed(*FlagSeed),
 	}
-
-	others := tf32.NewSet()
-	others.Add("symbols", width, 1)
-	symbols := others.ByName["symbols"]
-	symbols.X = symbols.X[:cap(symbols.X)]
-
-	// Create the weight data matrix
-	set := tf32.NewSet()
-	set.Add("points", width, 1024)
-	for _, w := range set.Weights {
-		for i := 0; i < cap(w.X); i++ {
-			w.X = append(w.X, float32((2*rnd.Float64() - 1)))
-		}
-		w.States = make([][]float32, StateTotal)
-		for i := range w.States {
-			w.States[i] = make([]float32, len(w.X))
+	if *FlagRetries > 0 {
+		options = append(options, occam.WithRecovery(1024, *FlagRetries, .5))
+	}
+	if *FlagClip > 0 {
+		options = append(options, occam.WithClipNorm(float32(*FlagClip)))
+	}
+	switch *FlagBackend {
+	case "tf32":
+	case "blas":
+		options = append(options, occam.WithBackend(occam.BLAS{}))
+	default:
+		panic(fmt.Sprintf("unknown backend %s", *FlagBackend))
+	}
+	var n *occam.Network
+	if *FlagResume != "" {
+		n, err = occam.LoadNetwork(*FlagResume, options...)
+		if err != nil {
+			panic(err)
 		}
+	} else {
+		n = occam.NewNetwork(width, 1024, append(options, occam.WithInitializer(occam.Uniform{Min: -1, Max: 1}))...)
 	}
+	n.Logger = logger
+	n.OnStep = occam.Steps(n.History(), occam.LogSteps(logger))
+	n.Normalization = occam.Normalization{Kind: occam.NormalizeMaxAbs}
+	checkpoint := fmt.Sprintf("%s_network.gob", *FlagTrain)
 
-	// The neural network is the attention model from attention is all you need
-	softmax := tf32.U(Softmax)
-	l1 := softmax(tf32.Mul(set.Get("points"), others.Get("symbols")))
-	l2 := softmax(tf32.Mul(tf32.T(set.Get("points")), l1))
-	cost := tf32.Entropy(l2)
-
-	points := make(plotter.XYs, 0, 8)
 	min := float32(math.MaxFloat32)
 
-	// The stochastic gradient descent loop
-	for i < 256*1024 {
-		// Randomly select and load the input
-		vectors := env
-		if *FlagTrain == "de" {
-			vectors = dev
+	// The word vectors are read from on-disk stores next to the vector files if they are cached
+	source := func(path string, lazy *embeddings.Lazy) embeddings.Source {
+		if *FlagCache <= 0 {
+			return load(lazy)
 		}
-		/*if i < 128*1024 {
-			vectors = env
-		} else if i < 256*1024 {
-			vectors = dev
-		} else {
-			vectors = env
-			if rnd.Intn(2) == 0 {
-				vectors = dev
+		name := path + ".store"
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			logger.Info("creating store", "path", name)
+			err = embeddings.CreateStore(name, path, vectors)
+			if err != nil {
+				panic(err)
 			}
-		}*/
-		vector := vectors.List[rnd.Intn(len(vectors.List))]
-		for i := range symbols.X {
-			symbols.X[i] = vector.Vector[i]
 		}
-
-		start := time.Now()
-		// Calculate the gradients
-		total := tf32.Gradient(cost).X[0]
-		if total < min {
-			min = total
+		store, err := embeddings.OpenStore(name, *FlagCache)
+		if err != nil {
+			panic(err)
 		}
+		return store
+	}
 
-		// Update the point weights with the partial derivatives using adam
-		b1, b2 := pow(B1), pow(B2)
-		for j, w := range set.Weights {
-			for k, d := range w.D {
-				g := d
-				m := B1*w.States[StateM][k] + (1-B1)*g
-				v := B2*w.States[StateV][k] + (1-B2)*g*g
-				w.States[StateM][k] = m
-				w.States[StateV][k] = v
-				mhat := m / (1 - b1)
-				vhat := v / (1 - b2)
-				set.Weights[j].X[k] -= Eta * mhat / (float32(math.Sqrt(float64(vhat))) + 1e-8)
+	// The curriculum is english first, then german, and then mixed
+	var sources []embeddings.Source
+	switch *FlagTrain {
+	case "de":
+		sources = []embeddings.Source{source(*FlagGerman, german)}
+	case "curriculum":
+		sources = []embeddings.Source{source(*FlagEnglish, english), source(*FlagGerman, german)}
+	default:
+		sources = []embeddings.Source{source(*FlagEnglish, english)}
+	}
+	size := 0
+	for _, source := range sources {
+		size += source.Len()
+	}
+	sample := func(index int) embeddings.Vector {
+		for _, source := range sources {
+			if index < source.Len() {
+				vector, err := source.Vector(index)
+				if err != nil {
+					panic(err)
+				}
+				return vector
 			}
+			index -= source.Len()
 		}
-
-		// Housekeeping
-		end := time.Since(start)
-		fmt.Println(i, total, end)
-		set.Zero()
-		others.Zero()
-
-		if math.IsNaN(float64(total)) {
-			fmt.Println(total)
-			break
+		panic(fmt.Sprintf("index %d out of range", index))
+	}
+	curriculum := &occam.StagedCurriculum{
+		Rnd: n.Rnd,
+		Stages: []occam.Stage{
+			{Steps: 256 * 1024, Begin: 0, End: size},
+		},
+	}
+	if *FlagTrain == "curriculum" {
+		english := sources[0].Len()
+		curriculum.Stages = []occam.Stage{
+			{Steps: 128 * 1024, Begin: 0, End: english},
+			{Steps: 256 * 1024, Begin: english, End: size},
+			{Steps: 384 * 1024, Begin: 0, End: size},
 		}
-
-		points = append(points, plotter.XY{X: float64(i), Y: float64(total)})
-		i++
+	}
+	steps := curriculum.Stages[len(curriculum.Stages)-1].Steps
+	switch *FlagSchedule {
+	case "constant":
+	case "step":
+		n.Config.Schedule = occam.StepDecay{Steps: steps / 4, Factor: .5}
+	case "cosine":
+		n.Config.Schedule = occam.Warmup{Steps: 1024, Schedule: occam.Cosine{Steps: steps - 1024}}
+	default:
+		panic(fmt.Sprintf("unknown schedule %s", *FlagSchedule))
 	}
 
-	// Plot the cost
-	p := plot.New()
-
-	p.Title.Text = "epochs vs cost"
-	p.X.Label.Text = "epochs"
-	p.Y.Label.Text = "cost"
-
-	scatter, err := plotter.NewScatter(points)
+	// The stochastic gradient descent loop, which is stopped cleanly by an interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	data := make([]float64, width)
+	err = n.TrainContext(ctx, steps, func(step int) []float64 {
+		// Randomly select and load the input
+		vector := sample(curriculum.Next(step))
+		for i := range data {
+			data[i] = float64(vector.Vector[i])
+		}
+		return data
+	}, func(progress occam.Progress) {
+		if progress.Cost < min {
+			min = progress.Cost
+		}
+		if progress.Step%1024 == 0 {
+			logger.Info("progress", "step", progress.Step, "steps", progress.Steps, "cost", progress.Cost,
+				"norm", n.LastGradientNorm(), "eta", progress.ETA.Round(time.Second))
+		}
+		if *FlagCheckpoint > 0 && progress.Step%*FlagCheckpoint == 0 {
+			err := n.Save(checkpoint)
+			if err != nil {
+				panic(err)
+			}
+		}
+	})
+	switch {
+	case errors.Is(err, occam.ErrNaN):
+		logger.Error("training stopped", "step", n.I, "error", err)
+	case errors.Is(err, context.Canceled):
+		logger.Warn("training interrupted", "step", n.I)
+	case err != nil:
+		panic(err)
+	}
+	stop()
+	err = n.Save(checkpoint)
 	if err != nil {
 		panic(err)
 	}
-	scatter.GlyphStyle.Radius = vg.Length(1)
-	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
-	p.Add(scatter)
 
-	err = p.Save(8*vg.Inch, 8*vg.Inch, "cost.png")
+	// Plot the cost
+	err = vis.CostPlot(n.Points, "cost."+*FlagFormat)
 	if err != nil {
 		panic(err)
 	}
 
-	set.Save(fmt.Sprintf("%s_set.w", *FlagTrain), 0, 0)
+	n.Set.Save(fmt.Sprintf("%s_set.w", *FlagTrain), 0, 0)
+
+	if *FlagExport != "" {
+		format, extension := occam.FormatJSON, "json"
+		switch *FlagExport {
+		case "json":
+		case "binary":
+			format, extension = occam.FormatBinary, "occam"
+		default:
+			panic(fmt.Sprintf("unknown export format %s", *FlagExport))
+		}
+		out, err := os.Create(fmt.Sprintf("%s_network.%s", *FlagTrain, extension))
+		if err != nil {
+			panic(err)
+		}
+		err = n.Export(out, format)
+		if err != nil {
+			panic(err)
+		}
+		err = out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}
 
-	fmt.Println("min", min)
+	logger.Info("done", "min", min)
 }
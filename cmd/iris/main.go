@@ -7,73 +7,146 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"math"
+	"os"
 	"sort"
 
 	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/analysis"
+	"github.com/pointlander/occam/metrics"
+	"github.com/pointlander/occam/vis"
 
 	"github.com/pointlander/datum/iris"
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
-	"gonum.org/v1/plot/vg/draw"
 )
 
 var (
 	// FlagNormalize is the flag to normalize the data
 	FlagNormalize = flag.Bool("normalize", false, "normalize the data")
+	// FlagPlotter is the plotting backend
+	FlagPlotter = flag.String("plotter", "gonum", "plotting backend: gonum, echarts, vega, or none")
+	// FlagFormat is the image format of the plots
+	FlagFormat = flag.String("format", "png", "image format of the plots: png, svg, or pdf")
+	// FlagSmooth is the weight of the exponential moving average of the cost plot
+	FlagSmooth = flag.Float64("smooth", 0, "weight of the exponential moving average of the cost, 0 disables smoothing")
+	// FlagLog plots the cost on a log scale
+	FlagLog = flag.Bool("log", false, "plot the cost on a log scale")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	// FlagSeed is the seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
+	// FlagRBF scores the points with an rbf kernel
+	FlagRBF = flag.Bool("rbf", false, "score the points with an rbf kernel instead of the dot product")
+	// FlagEval evaluates a checkpoint without training
+	FlagEval = flag.String("eval", "", "evaluate the checkpoint on the data set without training")
+	// FlagSnapshots is the number of snapshots averaged at the end of training
+	FlagSnapshots = flag.Int("snapshots", 0, "number of snapshots averaged at the end of training, 0 disables snapshots")
+	// FlagResume resumes training from a checkpoint
+	FlagResume = flag.String("resume", "", "resume training from the checkpoint")
+	// FlagReport writes an html report
+	FlagReport = flag.String("report", "", "write an html report of the network to the file")
+	// FlagAssets is the directory of the local copies of the scripts of the report
+	FlagAssets = flag.String("assets", "", "directory with echarts.min.js, which is inlined so the report is self contained")
+	// FlagCost is the cost the network is trained on
+	FlagCost = flag.String("cost", "entropy", "cost the network is trained on: entropy, reconstruction, divergence, or crossentropy, which labels the first 5 samples of each class")
+	// FlagRetries is the number of rollbacks when the cost isn't finite
+	FlagRetries = flag.Int("retries", 0, "number of rollbacks with a halved learning rate when the cost isn't finite, 0 disables recovery")
+	// FlagEnsemble is the number of networks of the consensus clustering
+	FlagEnsemble = flag.Int("ensemble", 0, "number of networks of the consensus clustering, 0 disables the ensemble")
 )
 
 func main() {
 	flag.Parse()
 
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
 	// Load the iris data set
 	datum, err := iris.Load()
 	if err != nil {
 		panic(err)
 	}
-	fisher := datum.Fisher
+	fisher := occam.FromIris(datum.Fisher)
 	length := len(fisher)
+	var normalization occam.Normalization
 	if *FlagNormalize {
+		normalization.Kind = occam.NormalizeUnit
 		for _, value := range fisher {
-			sum := 0.0
-			for _, measure := range value.Measures {
-				sum += measure * measure
-			}
-			sum = math.Sqrt(sum)
-			for i := range value.Measures {
-				value.Measures[i] /= sum
-			}
+			normalization.Apply(value.Measures)
 		}
 	}
 
-	n := occam.NewNetwork(4, length)
-
+	config := occam.DefaultNetworkConfig()
+	if *FlagRBF {
+		config.Kernel = occam.KernelRBF
+	}
+	objective, ok := occam.Costs[*FlagCost]
+	if !ok {
+		panic(fmt.Sprintf("unknown cost %s", *FlagCost))
+	}
+	config.Cost = objective
+	if *FlagEval != "" {
+		n, err := occam.OpenNetwork(*FlagEval, config)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(n.Evaluate(fisher))
+		return
+	}
+	measures := make([][]float64, 0, length)
+	for _, value := range fisher {
+		measures = append(measures, value.Measures)
+	}
 	// Set point weights to the iris data
-	for i, value := range fisher {
-		for j, measure := range value.Measures {
-			n.Point.X[4*i+j] = float32(measure)
+	options := []occam.Option{occam.WithSeed(*FlagSeed), occam.WithInitializer(occam.Data{Samples: measures})}
+	if *FlagRetries > 0 {
+		options = append(options, occam.WithRecovery(length, *FlagRetries, .5))
+	}
+	n := occam.NewNetworkWithConfig(4, length, config, options...)
+	n.Logger = logger
+	n.Normalization = normalization
+	n.OnStep = occam.Steps(n.History(), occam.LogSteps(logger))
+
+	if *FlagResume != "" {
+		err := n.LoadCheckpoint(*FlagResume)
+		if err != nil {
+			panic(err)
 		}
 	}
 
 	entropy := n.GetEntropy(fisher)
-	sort.Slice(entropy, func(i, j int) bool {
+	sort.SliceStable(entropy, func(i, j int) bool {
 		return entropy[i].Entropy > entropy[j].Entropy
 	})
 	last := float32(0.0)
 	for i, e := range entropy {
-		entropy[i].Order = i
 		fmt.Printf("%3d %.7f %.7f %s\n", i, e.Entropy, last-e.Entropy, e.Label)
 		last = e.Entropy
 	}
-	gradients := n.GetGradients(fisher)
-	vectors := n.GetVectors2(fisher)
+	// The gradients of the points summed over the samples
+	gradients := make([][]float32, n.Length)
+	for i := range gradients {
+		gradients[i] = make([]float32, n.Width)
+	}
+	for _, gradient := range n.GetGradients(fisher) {
+		for i, grad := range gradient {
+			gradients[i/n.Width][i%n.Width] += grad
+		}
+	}
+	vectors := n.Project(fisher, occam.LayerL2)
 
 	for i, grad := range gradients {
 		xy, x, y, x2, y2 := float32(0.0), float32(0.0), float32(0.0), float32(0.0), float32(0.0)
 		for i, grad := range grad {
 			grad = -grad
-			measure := float32(vectors[i].Measures[i])
+			measure := vectors[i][i]
 			xy += grad * measure
 			x += grad
 			y += measure
@@ -85,143 +158,37 @@ func main() {
 		y /= float32(len(gradients))
 		x2 /= float32(len(gradients))
 		y2 /= float32(len(gradients))
-		fmt.Println(i, (xy-x*y)/(float32(math.Sqrt(float64(x2-x*x)))*float32(math.Sqrt(float64(y2-y*y)))), grad, vectors[i].Measures)
+		fmt.Println(i, (xy-x*y)/(float32(math.Sqrt(float64(x2-x*x)))*float32(math.Sqrt(float64(y2-y*y)))), grad, vectors[i])
 	}
 
-	var split func(n *occam.Network, depth int, entropy []occam.Entropy, splits []int) []int
-	split = func(n *occam.Network, depth int, entropy []occam.Entropy, splits []int) []int {
-		if depth == 0 {
-			return splits
-		}
-
-		sum := float32(0.0)
-		for _, e := range entropy {
-			sum += e.Entropy
-		}
-		avg, vari := sum/float32(len(entropy)), float32(0.0)
-		for _, e := range entropy {
-			difference := e.Entropy - avg
-			vari += difference * difference
-		}
-		vari /= float32(len(entropy))
-
-		index, max := 0, float32(0.0)
-		for i := 1; i < len(entropy); i++ {
-			suma, counta := float32(0.0), float32(0.0)
-			for _, e := range entropy[:i] {
-				suma += e.Entropy
-				counta++
-			}
-			avga, varia := suma/counta, float32(0.0)
-			for _, e := range entropy[:i] {
-				difference := e.Entropy - avga
-				varia += difference * difference
-			}
-			varia /= counta
-
-			sumb, countb := float32(0.0), float32(0.0)
-			for _, e := range entropy[i:] {
-				sumb += e.Entropy
-				countb++
-			}
-			avgb, varib := sumb/countb, float32(0.0)
-			for _, e := range entropy[i:] {
-				difference := e.Entropy - avgb
-				varib += difference * difference
-			}
-			varib /= countb
-
-			gain := vari - (varia + varib)
-			if gain > max {
-				index, max = i, gain
-			}
-		}
-		splits = append(splits, entropy[index].Order)
-
-		/*dat := make([]iris.Iris, 0, 8)
-		for _, e := range entropy[index:] {
-			dat = append(dat, iris.Iris{
-				Measures: e.Measures,
-				Label:    e.Label,
-			})
-		}*/
-		splits = split(n, depth-1, entropy[index:], splits)
-
-		/*dat = make([]iris.Iris, 0, 8)
-		for _, e := range entropy[:index] {
-			dat = append(dat, iris.Iris{
-				Measures: e.Measures,
-				Label:    e.Label,
-			})
-		}*/
-		return split(n, depth-1, entropy[:index], splits)
-	}
-	splits := split(n, 2, entropy, []int{})
+	splits := occam.SplitEntropy(entropy, 2)
 	fmt.Println(splits)
 
-	nonlinear := make([]occam.Entropy, splits[0])
-	copy(nonlinear, entropy[:splits[0]])
-	for i := range nonlinear {
-		nonlinear[i].Index = i
-	}
-	type AB struct {
-		A, B uint
-	}
-	ab := make([]AB, len(nonlinear))
-	for i := 0; i < 1024; i++ {
-		data := make([]iris.Iris, 0, 8)
-		for _, e := range nonlinear {
-			measures := make([]float64, len(e.Measures))
-			for j, m := range e.Measures {
-				measures[j] = m + n.Rnd.NormFloat64()*0.1
-			}
-			data = append(data, iris.Iris{
-				Measures: measures,
-				Label:    e.Label,
-			})
-		}
-
-		n := occam.NewNetwork(4, len(data))
-		// Set point weights to the iris data
-		for i, value := range data {
-			for j, measure := range value.Measures {
-				n.Point.X[4*i+j] = float32(measure)
-			}
-		}
-
-		entropy := n.GetEntropy(data)
-		sort.Slice(entropy, func(i, j int) bool {
-			return entropy[i].Entropy > entropy[j].Entropy
-		})
-		for j := range entropy {
-			entropy[j].Order = j
-		}
-		splits := split(n, 1, entropy, []int{})
-		for j, e := range entropy {
-			if j < splits[0] {
-				ab[e.Index].A++
-			} else {
-				ab[e.Index].B++
-			}
+	nonlinear := make([]occam.Sample, splits[0])
+	for i, e := range entropy[:splits[0]] {
+		nonlinear[i] = occam.Sample{
+			Measures: e.Measures,
+			Label:    e.Label,
 		}
 	}
+	ab := occam.StabilityAnalysis(nonlinear, 0.1, 1024, 1, occam.WithSeed(*FlagSeed))
 	for i, e := range nonlinear {
-		fmt.Println(i, e.Label, ab[e.Index].A, ab[e.Index].B)
+		fmt.Println(i, e.Label, ab[i][0], ab[i][1])
 	}
 
-	data := make([]iris.Iris, 0, 8)
-	for _, e := range nonlinear {
+	data := make([]occam.Sample, 0, 8)
+	for i, e := range nonlinear {
 		measures := make([]float64, len(e.Measures)+2)
 		copy(measures, e.Measures)
-		measures[len(e.Measures)] = float64(ab[e.Index].A) / 1024
-		measures[len(e.Measures)+1] = float64(ab[e.Index].B) / 1024
-		data = append(data, iris.Iris{
+		measures[len(e.Measures)] = ab[i][0]
+		measures[len(e.Measures)+1] = ab[i][1]
+		data = append(data, occam.Sample{
 			Measures: measures,
 			Label:    e.Label,
 		})
 	}
 	{
-		n := occam.NewNetwork(6, len(data))
+		n := occam.NewNetwork(6, len(data), occam.WithSeed(*FlagSeed))
 		// Set point weights to the iris data
 		for i, value := range data {
 			for j, measure := range value.Measures {
@@ -230,19 +197,30 @@ func main() {
 		}
 
 		entropy := n.GetEntropy(data)
-		sort.Slice(entropy, func(i, j int) bool {
+		sort.SliceStable(entropy, func(i, j int) bool {
 			return entropy[i].Entropy > entropy[j].Entropy
 		})
-		for j := range entropy {
-			entropy[j].Order = j
-		}
-		splits := split(n, 1, entropy, []int{})
+		splits := occam.SplitEntropy(entropy, 1)
 		for i, e := range entropy {
 			fmt.Printf("%3d %.7f %s\n", i, e.Entropy, e.Label)
 		}
 		fmt.Println(splits)
 	}
 
+	if *FlagEnsemble > 0 {
+		ensemble := occam.NewEnsemble(*FlagEnsemble, config, occam.WithSeed(*FlagSeed))
+		ensemble.Sigma, ensemble.Epochs = 0.1, 16
+		samples, labels := make([][]float64, length), make([]string, length)
+		for i, value := range fisher {
+			samples[i], labels[i] = value.Measures, value.Label
+		}
+		consensus := ensemble.Fit(samples)
+		for i, cluster := range consensus.Clusters {
+			fmt.Printf("%3d %3d %.7f %s\n", i, cluster, consensus.Agreement[i], labels[i])
+		}
+		fmt.Println("consensus", metrics.AdjustedRand(labels, consensus.Clusters))
+	}
+
 	// The stochastic gradient descent loop
 	epochs := 8 * 1024
 	if *FlagNormalize {
@@ -252,57 +230,114 @@ func main() {
 	for i := range indexes {
 		indexes[i] = i
 	}
-	for n.I < epochs {
+	if *FlagSnapshots > 0 {
+		err := n.Snapshot(*FlagSnapshots, length)
+		if err != nil {
+			panic(err)
+		}
+	}
+	// The target of a labeled sample is uniform over the points of the samples with the same label
+	targets := make([][]float32, length)
+	if *FlagCost == "crossentropy" {
+		counts := make(map[string]int)
+		for i, value := range fisher {
+			if counts[value.Label] >= 5 {
+				continue
+			}
+			counts[value.Label]++
+			targets[i] = make([]float32, length)
+			for j, other := range fisher {
+				if other.Label == value.Label {
+					targets[i][j] = 1
+				}
+			}
+			sum := float32(0)
+			for _, target := range targets[i] {
+				sum += target
+			}
+			for j := range targets[i] {
+				targets[i][j] /= sum
+			}
+		}
+	}
+	for n.I < epochs && err == nil {
 		n.Rnd.Shuffle(length, func(i, j int) {
 			indexes[i], indexes[j] = indexes[j], indexes[i]
 		})
 		for _, index := range indexes {
 			// Randomly select a load the input
 			sample := fisher[index]
-			total := n.Iterate(sample.Measures)
-
-			if math.IsNaN(float64(total)) {
-				fmt.Println(total)
+			if *FlagCost == "crossentropy" {
+				err := n.SetTarget(targets[index])
+				if err != nil {
+					panic(err)
+				}
+			}
+			_, err = n.TryIterate(sample.Measures)
+			if err != nil {
+				logger.Error("training stopped", "step", n.I, "error", err)
 				break
 			}
 		}
 	}
 
 	// Plot the cost
-	p := plot.New()
-
-	p.Title.Text = "epochs vs cost"
-	p.X.Label.Text = "epochs"
-	p.Y.Label.Text = "cost"
-
-	scatter, err := plotter.NewScatter(n.Points)
+	plotter, err := vis.New(*FlagPlotter, *FlagFormat)
+	if err != nil {
+		panic(err)
+	}
+	cost := vis.Cost(n.Points)
+	if *FlagSmooth > 0 {
+		cost = cost.Smooth(*FlagSmooth)
+	}
+	cost.LogY = *FlagLog
+	err = plotter.Plot(cost, "cost"+plotter.Extension())
 	if err != nil {
 		panic(err)
 	}
-	scatter.GlyphStyle.Radius = vg.Length(1)
-	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
-	p.Add(scatter)
 
-	err = p.Save(8*vg.Inch, 8*vg.Inch, "cost.png")
+	err = n.SaveCheckpoint("set.w")
 	if err != nil {
 		panic(err)
 	}
 
-	n.Set.Save("set.w", 0, 0)
+	if *FlagSnapshots > 0 {
+		fmt.Println("final", n.Evaluate(fisher))
+		averaged, err := n.Snapshots.Average()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("averaged", averaged.Evaluate(fisher))
+	}
 
-	n.Analyzer(fisher)
+	err = analysis.Analyzer(n, fisher)
+	if err != nil {
+		panic(err)
+	}
+	if *FlagReport != "" {
+		err = analysis.ReportWithAssets(n, fisher, *FlagReport, *FlagAssets)
+		if err != nil {
+			panic(err)
+		}
+	}
 
-	entropy2 := n.GetEntropy(fisher)
-	for i, e := range entropy {
-		entropy[i].Optimized = entropy2[e.Index].Entropy
+	for i, information := range n.FeatureInformation(measures, 8) {
+		fmt.Printf("feature %d %.7f\n", i, information)
 	}
-	sort.Slice(entropy, func(i, j int) bool {
-		return (entropy[i].Entropy - entropy[i].Optimized) > (entropy[j].Entropy - entropy[j].Optimized)
-	})
+	names := []string{"sepal length", "sepal width", "petal length", "petal width"}
+	for _, rule := range n.Explain(measures, names, 3) {
+		fmt.Println(rule)
+	}
+	err = vis.PlotLandscape(n.EntropyLandscape(measures[0], 2, 3, 1, 32), "landscape."+*FlagFormat)
+	if err != nil {
+		panic(err)
+	}
+
+	entropy = n.EntropyDelta(entropy, n.GetEntropy(fisher))
 	for i, e := range entropy {
 		fmt.Printf("%3d %.7f %.7f %.7f %s\n", i, e.Entropy, e.Optimized, e.Entropy-e.Optimized, e.Label)
 		entropy[i].Entropy = e.Entropy - e.Optimized
 	}
-	splits2 := split(n, 2, entropy, []int{})
+	splits2 := occam.SplitEntropy(entropy, 2)
 	fmt.Println(splits2)
 }
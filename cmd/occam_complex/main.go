@@ -5,85 +5,55 @@
 package main
 
 import (
+	"encoding/csv"
 	"flag"
 	"fmt"
-	"math"
+	"log/slog"
 	"math/cmplx"
 	"math/rand"
+	"os"
 	"sort"
-	"time"
+	"strconv"
 
 	"github.com/pointlander/datum/iris"
-	"github.com/pointlander/gradient/tc128"
-	"github.com/pointlander/gradient/tf32"
 	"github.com/pointlander/occam"
-
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
-	"gonum.org/v1/plot/vg/draw"
+	"github.com/pointlander/occam/analysis"
+	"github.com/pointlander/occam/vis"
 )
 
 const (
-	// B1 exponential decay of the rate for the first moment estimates
-	B1 = 0.9
-	// B2 exponential decay rate for the second-moment estimates
-	B2 = 0.999
 	// S is the scaling factor for the softmax
 	S = 1.0 - 1e-300
 	// Eta is the learning rate
 	Eta = .1
 )
 
-const (
-	// StateM is the state for the mean
-	StateM = iota
-	// StateV is the state for the variance
-	StateV
-	// StateTotal is the total number of states
-	StateTotal
-)
-
-// SphericalSoftmax is the spherical softmax function
-// https://arxiv.org/abs/1511.05042
-func SphericalSoftmax(k tc128.Continuation, node int, a *tc128.V, options ...map[string]interface{}) bool {
-	const E = complex128(0)
-	c, size, width := tc128.NewV(a.S...), len(a.X), a.S[0]
-	values, sums, row := make([]complex128, width), make([]complex128, a.S[1]), 0
-	for i := 0; i < size; i += width {
-		sum := complex128(0.0)
-		for j, ax := range a.X[i : i+width] {
-			values[j] = ax*ax + E
-			sum += values[j]
-		}
-		for _, cx := range values {
-			c.X = append(c.X, (cx+E)/sum)
-		}
-		sums[row] = sum
-		row++
-	}
-	if k(&c) {
-		return true
-	}
-	// (2 a (b^2 + c^2 + d^2 + 0.003))/(a^2 + b^2 + c^2 + d^2 + 0.004)^2
-	for i, d := range c.D {
-		ax, sum := a.X[i], sums[i/width]
-		//a.D[i] += d*(2*ax*(sum-(ax*ax+E)))/(sum*sum) - d*cx*2*ax/sum
-		a.D[i] += d * (2 * ax * (sum - (ax*ax + E))) / (sum * sum)
-	}
-	return false
-}
-
 var (
 	//FlagInfer inference mode
 	FlagInfer = flag.String("infer", "", "inference mode")
+	//FlagFormat image format of the plots
+	FlagFormat = flag.String("format", "png", "image format of the plots: png, svg, or pdf")
 	//FlagTrain train mode
 	FlagTrain = flag.String("train", "en", "train mode")
+	//FlagLevel level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	//FlagJSON write the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	//FlagSeed seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
 )
 
 func main() {
 	flag.Parse()
-	rnd := rand.New(rand.NewSource(1))
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+	rnd := rand.New(rand.NewSource(*FlagSeed))
 	_ = rnd
 
 	// Load the iris data set
@@ -91,7 +61,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	fisher := datum.Fisher
+	fisher := occam.FromIris(datum.Fisher)
 	width, length := 4, len(fisher)
 
 	averages := make([]float64, width)
@@ -105,80 +75,32 @@ func main() {
 		averages[i] = value / float64(length)
 	}
 
-	others := tc128.NewSet()
-	others.Add("inputs", width, length)
-	inputs := others.ByName["inputs"]
-	for _, value := range fisher {
-		measures := value.Measures
-		inputs.X = append(inputs.X, /*cmplx.Rect(measures[0], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[1], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[2], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[3], rnd.Float64()*2*math.Pi),*/
-			complex(measures[0], 0),
-			complex(measures[1], 0),
-			complex(measures[2], 0),
-			complex(measures[3], 0),
-			/*complex(measures[0], measures[0]),
-			complex(measures[1], measures[1]),
-			complex(measures[2], measures[2]),
-			complex(measures[3], measures[3]),*/
-			/*complex(measures[0], measures[0]-averages[0]),
-			complex(measures[1], measures[1]-averages[1]),
-			complex(measures[2], measures[2]-averages[2]),
-			complex(measures[3], measures[3]-averages[3]),*/
-		)
+	n := occam.NewComplexNetwork(width, length, occam.WithLearningRate(Eta), occam.WithSeed(*FlagSeed))
+	n.Logger = logger
+	n.OnStep = occam.LogSteps(logger)
+	rows := make([][]complex128, 0, length)
+	for i, value := range fisher {
+		row := occam.Complex(value.Measures)
+		copy(n.Point.X[i*width:(i+1)*width], row)
+		rows = append(rows, row)
 	}
 
-	set := tc128.NewSet()
-	set.Add("weights", width, length)
-	weights := set.ByName["weights"]
-	for _, value := range fisher {
-		measures := value.Measures
-		weights.X = append(weights.X,
-			/*cmplx.Rect(measures[0], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[1], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[2], rnd.Float64()*2*math.Pi),
-			cmplx.Rect(measures[3], rnd.Float64()*2*math.Pi),*/
-			complex(measures[0], 0),
-			complex(measures[1], 0),
-			complex(measures[2], 0),
-			complex(measures[3], 0),
-			/*complex(measures[0], measures[0]),
-			complex(measures[1], measures[1]),
-			complex(measures[2], measures[2]),
-			complex(measures[3], measures[3]),*/
-			/*complex(measures[0], measures[0]-averages[0]),
-			complex(measures[1], measures[1]-averages[1]),
-			complex(measures[2], measures[2]-averages[2]),
-			complex(measures[3], measures[3]-averages[3]),*/
-		)
-	}
-	weights.States = make([][]complex128, StateTotal)
-	for i := range weights.States {
-		weights.States[i] = make([]complex128, len(weights.X))
-	}
-
-	softmax := tc128.U(SphericalSoftmax)
-	l1 := softmax(tc128.Mul(set.Get("weights"), others.Get("inputs")))
-	l2 := softmax(tc128.T(tc128.Mul(l1, tc128.T(set.Get("weights")))))
-	entropy := tc128.Entropy(l2)
-	cost := tc128.Avg(entropy)
-
 	type Item struct {
 		Label string
 		Rank  complex128
 	}
-	rank := func() {
+	ranks := func() []Item {
 		items := make([]Item, 0, 8)
-		entropy(func(a *tc128.V) bool {
-			for i := 0; i < length; i++ {
-				items = append(items, Item{
-					Label: fisher[i].Label,
-					Rank:  a.X[i],
-				})
-			}
-			return true
-		})
+		for _, e := range n.GetEntropy(fisher) {
+			items = append(items, Item{
+				Label: e.Label,
+				Rank:  e.Entropy,
+			})
+		}
+		return items
+	}
+	rank := func() {
+		items := ranks()
 		sort.Slice(items, func(i, j int) bool {
 			return cmplx.Abs(items[i].Rank) < cmplx.Abs(items[j].Rank)
 		})
@@ -189,192 +111,106 @@ func main() {
 	rank()
 
 	correct := 0
-	l2(func(a *tc128.V) bool {
-		others := tf32.NewSet()
-		others.Add("inputs", width, length)
-		inputs := others.ByName["inputs"]
-		for _, value := range a.X {
-			inputs.X = append(inputs.X, float32(cmplx.Abs(value)))
+	{
+		labels := make([]int, length)
+		for i, value := range fisher {
+			labels[i] = iris.Labels[value.Label]
 		}
-		others.Add("targets", 3, length)
-		targets := others.ByName["targets"]
-		targets.X = targets.X[:cap(targets.X)]
-		for i := 0; i < length; i++ {
-			targets.X[i*3+iris.Labels[fisher[i].Label]] = 1
-		}
-
-		set := tf32.NewSet()
-		set.Add("weights", width, 3)
-		weights := set.ByName["weights"]
-		factor := math.Sqrt(2.0 / float64(weights.S[0]))
-		for i := 0; i < cap(weights.X); i++ {
-			weights.X = append(weights.X, float32(rnd.NormFloat64()*factor))
-		}
-		weights.States = make([][]float32, StateTotal)
-		for i := range weights.States {
-			weights.States[i] = make([]float32, len(weights.X))
-		}
-		set.Add("bias", 3, 1)
-		bias := set.ByName["bias"]
-		bias.X = bias.X[:cap(bias.X)]
-		bias.States = make([][]float32, StateTotal)
-		for i := range bias.States {
-			bias.States[i] = make([]float32, len(bias.X))
-		}
-
-		softmax := tf32.U(occam.SphericalSoftmax)
-		l1 := softmax(tf32.Add(tf32.Mul(set.Get("weights"), others.Get("inputs")), set.Get("bias")))
-		cost := tf32.Avg(tf32.CrossEntropy(l1, others.Get("targets")))
-
-		i := 1
-		pow := func(x float32) float32 {
-			return float32(math.Pow(float64(x), float64(i)))
-		}
-
-		points := make(plotter.XYs, 0, 8)
-
-		// The stochastic gradient descent loop
-		for i < 8*1024 {
-			start := time.Now()
-			// Calculate the gradients
-			total := tf32.Gradient(cost).X[0]
-
-			// Update the point weights with the partial derivatives using adam
-			b1, b2 := pow(B1), pow(B2)
-			for j, w := range set.Weights {
-				for k, d := range w.D {
-					g := d
-					m := B1*w.States[StateM][k] + (1-B1)*g
-					v := B2*w.States[StateV][k] + (1-B2)*g*g
-					w.States[StateM][k] = m
-					w.States[StateV][k] = v
-					mhat := m / (1 - b1)
-					vhat := v / (1 - b2)
-					set.Weights[j].X[k] -= Eta * mhat / float32(math.Sqrt(float64(vhat))+1e-8)
-				}
-			}
-
-			// Housekeeping
-			end := time.Since(start)
-			fmt.Println(i, total, end)
-			set.Zero()
-			others.Zero()
-
-			if math.IsNaN(float64(total)) {
-				fmt.Println(total)
-				break
-			}
-
-			points = append(points, plotter.XY{X: float64(i), Y: float64(total)})
-			i++
+		cost := n.FitHead(fisher, labels, 3)
+		logger.Info("head", "cost", cost)
+		points := make([]vis.Point, 0, len(n.Head.Points))
+		for i, total := range n.Head.Points {
+			points = append(points, vis.Point{X: float64(i + 1), Y: float64(total)})
 		}
 
 		fmt.Println("----------------------------------------------------------")
-		l1(func(a *tf32.V) bool {
-			for i := 0; i < length; i++ {
-				x, index, max := a.X[i*3:i*3+3], 0, float32(0.0)
-				for i, value := range x {
-					if value > max {
-						index, max = i, value
-					}
-				}
-				expected := iris.Labels[fisher[i].Label]
-				fmt.Println(i, index, expected)
-				if index == expected {
-					correct++
-				}
+		for i, value := range fisher {
+			index, _ := n.PredictHead(value.Measures)
+			expected := labels[i]
+			fmt.Println(i, index, expected)
+			if index == expected {
+				correct++
 			}
-			return true
-		})
+		}
 		fmt.Println("----------------------------------------------------------")
 
 		// Plot the cost
-		p := plot.New()
-
-		p.Title.Text = "epochs vs cost"
-		p.X.Label.Text = "epochs"
-		p.Y.Label.Text = "cost"
-
-		scatter, err := plotter.NewScatter(points)
+		err = vis.Gonum{}.Plot(vis.Scatter("epochs vs cost", "epochs", "cost",
+			vis.Series{Name: "cost", Points: points}), "occam_top_complex_cost."+*FlagFormat)
 		if err != nil {
 			panic(err)
 		}
-		scatter.GlyphStyle.Radius = vg.Length(1)
-		scatter.GlyphStyle.Shape = draw.CircleGlyph{}
-		p.Add(scatter)
-
-		err = p.Save(8*vg.Inch, 8*vg.Inch, "occam_top_complex_cost.png")
-		if err != nil {
-			panic(err)
-		}
-		return true
-	})
-
-	points := make(plotter.XYs, 0, 8)
-
-	i := 1
-	pow := func(x complex128) complex128 {
-		return cmplx.Pow(x, complex(float64(i), 0))
 	}
 
-	// The stochastic gradient descent loop
-	for i < 1024 {
-		start := time.Now()
-		// Calculate the gradients
-		total := tc128.Gradient(cost).X[0]
-
-		// Update the point weights with the partial derivatives using adam
-		b1, b2 := pow(B1), pow(B2)
-		for j, w := range set.Weights {
-			for k, d := range w.D {
-				g := d
-				m := B1*w.States[StateM][k] + (1-B1)*g
-				v := B2*w.States[StateV][k] + (1-B2)*g*g
-				w.States[StateM][k] = m
-				w.States[StateV][k] = v
-				mhat := m / (1 - b1)
-				vhat := v / (1 - b2)
-				set.Weights[j].X[k] -= Eta * mhat / (cmplx.Sqrt(vhat) + 1e-8)
-			}
-		}
-
-		// Housekeeping
-		end := time.Since(start)
-		fmt.Println(i, cmplx.Abs(total), end)
-		set.Zero()
-
+	// The gradient descent loop over all of the inputs
+	for n.I < 1024 {
+		total := n.IterateBatch(rows...)
 		if cmplx.IsNaN(total) {
-			fmt.Println(total)
+			logger.Error("cost is nan", "step", n.I)
 			break
 		}
-
-		points = append(points, plotter.XY{X: float64(i), Y: float64(cmplx.Abs(total))})
-		i++
 	}
 
 	rank()
 
 	// Plot the cost
-	p := plot.New()
+	err = vis.CostPlot(n.Points, "occam_complex_cost."+*FlagFormat)
+	if err != nil {
+		panic(err)
+	}
 
-	p.Title.Text = "epochs vs cost"
-	p.X.Label.Text = "epochs"
-	p.Y.Label.Text = "cost"
+	n.Set.Save("occam_complex_set.w", 0, 0)
 
-	scatter, err := plotter.NewScatter(points)
+	err = analysis.ComplexAnalyzer(n, fisher)
 	if err != nil {
 		panic(err)
 	}
-	scatter.GlyphStyle.Radius = vg.Length(1)
-	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
-	p.Add(scatter)
 
-	err = p.Save(8*vg.Inch, 8*vg.Inch, "occam_complex_cost.png")
+	items := ranks()
+
+	// Export the complex entropies
+	out, err := os.Create("occam_complex_entropy.csv")
 	if err != nil {
 		panic(err)
 	}
+	defer out.Close()
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"index", "label", "real", "imag", "abs", "phase"})
+	for i, item := range items {
+		writer.Write([]string{
+			strconv.Itoa(i),
+			item.Label,
+			strconv.FormatFloat(real(item.Rank), 'g', -1, 64),
+			strconv.FormatFloat(imag(item.Rank), 'g', -1, 64),
+			strconv.FormatFloat(cmplx.Abs(item.Rank), 'g', -1, 64),
+			strconv.FormatFloat(cmplx.Phase(item.Rank), 'g', -1, 64),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		panic(err)
+	}
 
-	set.Save("occam_complex_set.w", 0, 0)
+	// Plot the complex entropies in the argand plane
+	labels := make([]string, len(iris.Labels))
+	for label, index := range iris.Labels {
+		labels[index] = label
+	}
+	argand := vis.Scatter("argand plane of entropy", "real", "imaginary")
+	argand.Radius = 3
+	for _, label := range labels {
+		series := vis.Series{Name: label}
+		for _, item := range items {
+			if item.Label == label {
+				series.Points = append(series.Points, vis.Point{X: real(item.Rank), Y: imag(item.Rank)})
+			}
+		}
+		argand.Series = append(argand.Series, series)
+	}
+	err = vis.Gonum{}.Plot(argand, "occam_complex_phase."+*FlagFormat)
+	if err != nil {
+		panic(err)
+	}
 
-	fmt.Println("correct", correct, float64(correct)/150)
+	logger.Info("done", "correct", correct, "accuracy", float64(correct)/150)
 }
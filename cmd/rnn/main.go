@@ -5,26 +5,46 @@
 package main
 
 import (
+	crand "crypto/rand"
 	"encoding/binary"
 	"flag"
 	"fmt"
 	"io"
-	"math"
+	"log/slog"
 	"math/rand"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/pointlander/gradient/tf32"
 	"github.com/pointlander/occam"
-
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
-	"gonum.org/v1/plot/vg/draw"
+	"github.com/pointlander/occam/vis"
 )
 
 var (
 	// FlagRND mixes random information into the rnn
 	FlagRND = flag.Bool("rnd", false, "rnd mode")
+	// FlagWidth is the width of the state
+	FlagWidth = flag.Int("width", 8, "width of the state")
+	// FlagSteps is the number of steps to run the rnn for
+	FlagSteps = flag.Int("steps", 8*1024, "number of steps")
+	// FlagState is the initial state
+	FlagState = flag.String("state", "1", "comma separated initial state")
+	// FlagReplay is the capacity of the replay buffer
+	FlagReplay = flag.Int("replay", 0, "capacity of the replay buffer, 0 disables replay")
+	// FlagPlotter is the plotting backend
+	FlagPlotter = flag.String("plotter", "gonum", "plotting backend: gonum, echarts, vega, or none")
+	// FlagFormat is the image format of the plots
+	FlagFormat = flag.String("format", "png", "image format of the plots: png, svg, or pdf")
+	// FlagSmooth is the weight of the exponential moving average of the cost plot
+	FlagSmooth = flag.Float64("smooth", 0, "weight of the exponential moving average of the cost, 0 disables smoothing")
+	// FlagLog plots the cost on a log scale
+	FlagLog = flag.Bool("log", false, "plot the cost on a log scale")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+	// FlagSeed is the seed of the random number generators
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
 )
 
 // Source is a true random number source
@@ -34,12 +54,8 @@ type Source struct {
 
 // NewSource creates a new true random source
 func NewSource() *Source {
-	in, err := os.Open("/dev/random")
-	if err != nil {
-		panic(err)
-	}
 	return &Source{
-		Reader: in,
+		Reader: crand.Reader,
 	}
 }
 
@@ -63,67 +79,108 @@ func (s *Source) Uint64() uint64 {
 	return binary.BigEndian.Uint64(data)
 }
 
+// Experiment is the environment of the rnn experiment, which prints the states and in rnd mode mixes
+// random numbers into the state
+type Experiment struct {
+	RndA, RndB *rand.Rand
+	States     [][]float64
+}
+
+// Observe returns two random numbers in rnd mode and nothing otherwise
+func (e *Experiment) Observe() []float64 {
+	if *FlagRND && len(e.States) > 0 {
+		return []float64{e.RndA.Float64(), e.RndB.Float64()}
+	}
+	return nil
+}
+
+// Act prints the state and in rnd mode records it
+func (e *Experiment) Act(action []float64) bool {
+	fmt.Println(action)
+	if *FlagRND {
+		emitted := make([]float64, len(action))
+		copy(emitted, action)
+		e.States = append(e.States, emitted)
+	}
+	return false
+}
+
 func main() {
 	flag.Parse()
 
-	rnda, rndb := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(2))
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+	slog.SetDefault(logger)
+
+	rnda, rndb := rand.New(rand.NewSource(*FlagSeed)), rand.New(rand.NewSource(*FlagSeed+1))
 	//rnda, rndb := rand.New(NewSource()), rand.New(NewSource())
-	n := occam.NewNetwork(8, 8)
-	state := make([]float64, 8)
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
+	width := *FlagWidth
+	if *FlagRND && width < 2 {
+		panic("rnd mode requires a width of at least 2")
+	}
+	r := occam.NewRNN(width)
+	r.SetSeed(*FlagSeed)
+	r.Logger = logger
+	r.OnStep = occam.Steps(r.History(), occam.LogSteps(logger))
+	if *FlagReplay > 0 {
+		r.EnableReplay(*FlagReplay, 64, 8)
+	}
+	for i := 0; i < width; i++ {
+		for j := 0; j < width; j++ {
 			if *FlagRND {
-				n.Point.X[8*i+j] = 1
+				r.Point.X[width*i+j] = 1
 			} else {
-				n.Point.X[8*i+j] = n.Rnd.Float32()
+				r.Point.X[width*i+j] = r.Rnd.Float32()
 			}
 		}
 	}
 
-	state[0] = 1
-	for i := 0; i < 8*1024; i++ {
-		total := n.Iterate(state)
-
-		if math.IsNaN(float64(total)) {
-			fmt.Println(total)
+	for i, value := range strings.Split(*FlagState, ",") {
+		if i >= width {
 			break
 		}
-
-		n.L2(func(a *tf32.V) bool {
-			for i, value := range a.X {
-				state[i] = float64(value)
-			}
-			return true
-		})
-		fmt.Println(state)
-		if *FlagRND {
-			state[0] = rnda.Float64()
-			state[1] = rndb.Float64()
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		s, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			panic(err)
 		}
+		r.State[i] = s
 	}
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			fmt.Printf("%f ", n.Point.X[i*8+j])
+	environment := &Experiment{
+		RndA:   rnda,
+		RndB:   rndb,
+		States: make([][]float64, 0, *FlagSteps),
+	}
+	r.Run(environment, *FlagSteps)
+	for i := 0; i < width; i++ {
+		for j := 0; j < width; j++ {
+			fmt.Printf("%f ", r.Point.X[i*width+j])
 		}
 		fmt.Printf("\n")
 	}
 
-	// Plot the cost
-	p := plot.New()
-
-	p.Title.Text = "epochs vs cost"
-	p.X.Label.Text = "epochs"
-	p.Y.Label.Text = "cost"
+	if *FlagRND {
+		PrintRandomness(Randomness(environment.States))
+	}
 
-	scatter, err := plotter.NewScatter(n.Points)
+	// Plot the cost
+	plotter, err := vis.New(*FlagPlotter, *FlagFormat)
 	if err != nil {
 		panic(err)
 	}
-	scatter.GlyphStyle.Radius = vg.Length(1)
-	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
-	p.Add(scatter)
-
-	err = p.Save(8*vg.Inch, 8*vg.Inch, "cost.png")
+	cost := vis.Cost(r.Points)
+	if *FlagSmooth > 0 {
+		cost = cost.Smooth(*FlagSmooth)
+	}
+	cost.LogY = *FlagLog
+	err = plotter.Plot(cost, "cost"+plotter.Extension())
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// Test is the result of a statistical randomness test
+type Test struct {
+	Name      string
+	Statistic float64
+	P         float64
+}
+
+// Bits converts a sequence of states into bits by comparing each value against the uniform value
+func Bits(states [][]float64) []uint8 {
+	bits := make([]uint8, 0, 8)
+	for _, state := range states {
+		threshold := 1 / float64(len(state))
+		for _, value := range state {
+			if value > threshold {
+				bits = append(bits, 1)
+			} else {
+				bits = append(bits, 0)
+			}
+		}
+	}
+	return bits
+}
+
+// Monobit is the frequency test from NIST SP 800-22
+func Monobit(bits []uint8) Test {
+	sum := 0.0
+	for _, bit := range bits {
+		sum += 2*float64(bit) - 1
+	}
+	s := math.Abs(sum) / math.Sqrt(float64(len(bits)))
+	return Test{
+		Name:      "monobit",
+		Statistic: s,
+		P:         math.Erfc(s / math.Sqrt2),
+	}
+}
+
+// Runs is the runs test from NIST SP 800-22
+func Runs(bits []uint8) Test {
+	n := float64(len(bits))
+	ones := 0.0
+	for _, bit := range bits {
+		ones += float64(bit)
+	}
+	pi := ones / n
+	if math.Abs(pi-.5) >= 2/math.Sqrt(n) {
+		return Test{
+			Name: "runs",
+		}
+	}
+	v := 1.0
+	for i := 1; i < len(bits); i++ {
+		if bits[i] != bits[i-1] {
+			v++
+		}
+	}
+	numerator := math.Abs(v - 2*n*pi*(1-pi))
+	denominator := 2 * math.Sqrt(2*n) * pi * (1 - pi)
+	return Test{
+		Name:      "runs",
+		Statistic: v,
+		P:         math.Erfc(numerator / denominator),
+	}
+}
+
+// SerialCorrelation is the serial correlation coefficient of the values
+func SerialCorrelation(states [][]float64) Test {
+	values := make([]float64, 0, 8)
+	for _, state := range states {
+		values = append(values, state...)
+	}
+	n := float64(len(values))
+	sum, sum2, product := 0.0, 0.0, 0.0
+	for i, value := range values {
+		sum += value
+		sum2 += value * value
+		product += value * values[(i+1)%len(values)]
+	}
+	c := (n*product - sum*sum) / (n*sum2 - sum*sum)
+	return Test{
+		Name:      "serial correlation",
+		Statistic: c,
+		P:         math.Erfc(math.Abs(c) * math.Sqrt(n) / math.Sqrt2),
+	}
+}
+
+// ApproximateEntropy is the approximate entropy test from NIST SP 800-22
+func ApproximateEntropy(bits []uint8, m int) Test {
+	n := len(bits)
+	phi := func(m int) float64 {
+		if m == 0 {
+			return 0
+		}
+		counts := make([]float64, 1<<uint(m))
+		for i := 0; i < n; i++ {
+			pattern := 0
+			for j := 0; j < m; j++ {
+				pattern = pattern<<1 | int(bits[(i+j)%n])
+			}
+			counts[pattern]++
+		}
+		sum := 0.0
+		for _, count := range counts {
+			if count > 0 {
+				c := count / float64(n)
+				sum += c * math.Log(c)
+			}
+		}
+		return sum
+	}
+	apen := phi(m) - phi(m+1)
+	chi2 := 2 * float64(n) * (math.Ln2 - apen)
+	return Test{
+		Name:      "approximate entropy",
+		Statistic: apen,
+		P:         mathext.GammaIncRegComp(math.Pow(2, float64(m-1)), chi2/2),
+	}
+}
+
+// Randomness runs the randomness tests on a sequence of states
+func Randomness(states [][]float64) []Test {
+	bits := Bits(states)
+	if len(bits) == 0 {
+		return nil
+	}
+	return []Test{
+		Monobit(bits),
+		Runs(bits),
+		SerialCorrelation(states),
+		ApproximateEntropy(bits, 2),
+	}
+}
+
+// PrintRandomness prints the results of the randomness tests
+func PrintRandomness(tests []Test) {
+	for _, test := range tests {
+		result := "pass"
+		if test.P < .01 {
+			result = "fail"
+		}
+		fmt.Printf("%-20s %12.7f %.7f %s\n", test.Name, test.Statistic, test.P, result)
+	}
+}
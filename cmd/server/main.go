@@ -0,0 +1,62 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/pointlander/occam"
+	"github.com/pointlander/occam/rpc"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	// FlagAddress is the address the server listens on
+	FlagAddress = flag.String("address", ":7777", "address the server listens on")
+	// FlagLevel is the level of the logs
+	FlagLevel = flag.String("level", "info", "level of the logs: debug, info, warn, or error")
+	// FlagJSON writes the logs as json
+	FlagJSON = flag.Bool("json", false, "write the logs as json")
+)
+
+func main() {
+	flag.Parse()
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(*FlagLevel))
+	if err != nil {
+		panic(err)
+	}
+	logger := occam.NewLogger(os.Stderr, *FlagJSON, level)
+
+	listener, err := net.Listen("tcp", *FlagAddress)
+	if err != nil {
+		panic(err)
+	}
+	server := rpc.NewServer()
+	s := grpc.NewServer()
+	rpc.RegisterOccamServer(s, server)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+		s.GracefulStop()
+	}()
+
+	logger.Info("serving", "address", listener.Addr().String())
+	err = s.Serve(listener)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("done")
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"log/slog"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pointlander/gradient/tc128"
+)
+
+// ComplexSphericalSoftmax is the spherical softmax function for complex numbers. The complex128 pointed
+// to by the optional epsilon option is added to each squared value to stabilize rows that are close to zero.
+// https://arxiv.org/abs/1511.05042
+func ComplexSphericalSoftmax(k tc128.Continuation, node int, a *tc128.V, options ...map[string]interface{}) bool {
+	E := complex128(0)
+	if len(options) > 0 {
+		if e, ok := options[0]["epsilon"].(*complex128); ok {
+			E = *e
+		}
+	}
+	c, size, width := tc128.NewV(a.S...), len(a.X), a.S[0]
+	values, sums, row := make([]complex128, width), make([]complex128, a.S[1]), 0
+	for i := 0; i < size; i += width {
+		sum := complex128(0.0)
+		for j, ax := range a.X[i : i+width] {
+			values[j] = ax*ax + E
+			sum += values[j]
+		}
+		for _, cx := range values {
+			if sum == 0 {
+				// A row of zeros without an epsilon is uniform
+				c.X = append(c.X, complex(1/float64(width), 0))
+				continue
+			}
+			c.X = append(c.X, cx/sum)
+		}
+		sums[row] = sum
+		row++
+	}
+	if k(&c) {
+		return true
+	}
+	// The gradient of input i is 2 a_i (d_i - Σ_j d_j c_j) / sum like SphericalSoftmax
+	dots := make([]complex128, a.S[1])
+	for i, d := range c.D {
+		dots[i/width] += d * c.X[i]
+	}
+	for i, d := range c.D {
+		ax, row := a.X[i], i/width
+		if sums[row] == 0 {
+			continue
+		}
+		a.D[i] += 2 * ax * (d - dots[row]) / sums[row]
+	}
+	return false
+}
+
+// ComplexEntropy is the complex self entropy of a point
+type ComplexEntropy struct {
+	Entropy  complex128
+	Label    string
+	Measures []float64
+	// Index is the index of the input
+	Index int
+	// Order is the rank of the input in order of decreasing magnitude of the entropy like Entropy.Order
+	Order int
+}
+
+// rankComplex sets the Order of the entropies, which are in the order of the inputs
+func rankComplex(entropy []ComplexEntropy) []ComplexEntropy {
+	order := make([]int, len(entropy))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return cmplx.Abs(entropy[order[i]].Entropy) > cmplx.Abs(entropy[order[j]].Entropy)
+	})
+	for i, index := range order {
+		entropy[index].Order = i
+	}
+	return entropy
+}
+
+// ComplexNetwork is a clustering neural network with complex weights and the complex spherical softmax
+type ComplexNetwork struct {
+	Rnd    *rand.Rand
+	Source *Source
+	Width  int
+	Length int
+	Config NetworkConfig
+	Set    tc128.Set
+	Others tc128.Set
+	Input  *tc128.V
+	Point  *tc128.V
+	L1     tc128.Meta
+	L2     tc128.Meta
+	Cost   tc128.Meta
+	I      int
+	Points []XY
+	Logger *slog.Logger
+	OnStep StepFunc
+	// Head is the classification head trained by FitHead
+	Head    *Classifier
+	epsilon complex128
+}
+
+// NewComplexNetwork creates a new complex neural network with the default configuration modified by the
+// options. Only the learning rate, the spherical softmax epsilon, and the seed are used.
+func NewComplexNetwork(width, length int, options ...Option) *ComplexNetwork {
+	source := NewSource(1)
+	n := ComplexNetwork{
+		Rnd:    rand.New(source),
+		Source: source,
+		Logger: NewNopLogger(),
+		Width:  width,
+		Length: length,
+		I:      1,
+	}
+	o := Network{
+		Rnd:    n.Rnd,
+		Source: source,
+		Config: DefaultNetworkConfig(),
+	}
+	for _, option := range options {
+		option(&o)
+	}
+	n.Config = o.Config
+	n.epsilon = complex(float64(n.Config.Epsilon), 0)
+
+	// Create the input data matrix, which has a row for each input of a batch
+	n.Others = tc128.NewSet()
+	n.Others.Add("input", width, 1)
+	n.Input = n.Others.ByName["input"]
+	n.Input.X = n.Input.X[:cap(n.Input.X)]
+
+	// Create the weight data matrix
+	n.Set = tc128.NewSet()
+	n.Set.Add("points", width, length)
+	n.Point = n.Set.ByName["points"]
+	n.Point.X = n.Point.X[:cap(n.Point.X)]
+	n.Point.States = make([][]complex128, StateTotal)
+	for i := range n.Point.States {
+		n.Point.States[i] = make([]complex128, len(n.Point.X))
+	}
+
+	// The neural network is the attention model from attention is all you need
+	spherical := tc128.U(ComplexSphericalSoftmax)
+	softmax := func(a tc128.Meta) tc128.Meta {
+		return spherical(a, map[string]interface{}{
+			"epsilon": &n.epsilon,
+		})
+	}
+	points := n.Set.Get("points")
+	n.L1 = softmax(tc128.Mul(points, n.Others.Get("input")))
+	n.L2 = softmax(tc128.T(tc128.Mul(n.L1, tc128.T(points))))
+	n.Cost = tc128.Avg(tc128.Entropy(n.L2))
+
+	n.Points = make([]XY, 0, 8)
+
+	return &n
+}
+
+// Complex converts the measures to complex numbers with zero imaginary parts
+func Complex(measures []float64) []complex128 {
+	values := make([]complex128, len(measures))
+	for i, measure := range measures {
+		values[i] = complex(measure, 0)
+	}
+	return values
+}
+
+// load loads the rows into the input
+func (n *ComplexNetwork) load(rows ...[]complex128) {
+	size := n.Width * len(rows)
+	if cap(n.Input.X) < size || cap(n.Input.D) < size {
+		n.Input.X = make([]complex128, size)
+		n.Input.D = make([]complex128, size)
+	}
+	n.Input.X, n.Input.D = n.Input.X[:size], n.Input.D[:size]
+	n.Input.S[1] = len(rows)
+	for i, row := range rows {
+		copy(n.Input.X[i*n.Width:(i+1)*n.Width], row)
+	}
+}
+
+// GetEntropy returns the complex entropy of the network for each input in the order of the inputs
+func (n *ComplexNetwork) GetEntropy(inputs []Sample) []ComplexEntropy {
+	outputs := make([]ComplexEntropy, 0, len(inputs))
+	for i, sample := range inputs {
+		n.load(Complex(sample.Measures))
+		n.Cost(func(a *tc128.V) bool {
+			outputs = append(outputs, ComplexEntropy{
+				Entropy:  a.X[0],
+				Label:    sample.Label,
+				Measures: sample.Measures,
+				Index:    i,
+			})
+			return true
+		})
+	}
+	return rankComplex(outputs)
+}
+
+// Features returns the output of the layer of the network for the input. LayerGradient isn't supported.
+func (n *ComplexNetwork) Features(input []complex128, layer Layer) []complex128 {
+	if layer == LayerGradient {
+		panic("complex networks don't support gradient features")
+	}
+	n.load(input)
+	meta := n.L1
+	if layer == LayerL2 {
+		meta = n.L2
+	}
+	var features []complex128
+	meta(func(a *tc128.V) bool {
+		features = make([]complex128, len(a.X))
+		copy(features, a.X)
+		return true
+	})
+	return features
+}
+
+// GetVectors returns the magnitude of the l1 attention of the network for each input, which can be
+// analyzed like the vectors of a Network
+func (n *ComplexNetwork) GetVectors(inputs []Sample) []Sample {
+	outputs := make([]Sample, 0, len(inputs))
+	for _, sample := range inputs {
+		attention := n.Features(Complex(sample.Measures), LayerL1)
+		vectors := make([]float64, len(attention))
+		for i, value := range attention {
+			vectors[i] = cmplx.Abs(value)
+		}
+		outputs = append(outputs, Sample{
+			Measures: vectors,
+			Label:    sample.Label,
+		})
+	}
+	return outputs
+}
+
+// Iterate does a gradient descent operation on the sample and returns the cost
+func (n *ComplexNetwork) Iterate(data []complex128) complex128 {
+	return n.IterateBatch(data)
+}
+
+// IterateBatch does a gradient descent operation on the average entropy of the rows and returns the cost
+func (n *ComplexNetwork) IterateBatch(rows ...[]complex128) complex128 {
+	n.load(rows...)
+
+	start := time.Now()
+	// Calculate the gradients
+	total := tc128.Gradient(n.Cost).X[0]
+
+	// Update the point weights with the partial derivatives using adam
+	i := complex(float64(n.I), 0)
+	b1, b2 := cmplx.Pow(B1, i), cmplx.Pow(B2, i)
+	eta := complex(float64(n.Config.rate(n.I)), 0)
+	for _, w := range n.Set.Weights {
+		for k, d := range w.D {
+			m := B1*w.States[StateM][k] + (1-B1)*d
+			v := B2*w.States[StateV][k] + (1-B2)*d*d
+			w.States[StateM][k] = m
+			w.States[StateV][k] = v
+			mhat := m / (1 - b1)
+			vhat := v / (1 - b2)
+			w.X[k] -= eta * mhat / (cmplx.Sqrt(vhat) + 1e-8)
+		}
+	}
+
+	// Housekeeping
+	end := time.Since(start)
+	n.Set.Zero()
+	n.Others.Zero()
+	n.Points = append(n.Points, XY{X: float64(n.I), Y: cmplx.Abs(total)})
+	if n.OnStep != nil {
+		n.OnStep(n.I, float32(cmplx.Abs(total)), end)
+	}
+	n.I++
+
+	return total
+}
@@ -0,0 +1,154 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/pointlander/gradient/tc128"
+)
+
+// complexSpherical runs the complex spherical softmax on the rows and returns the output and the gradient of
+// the cost sum(weights * output) with respect to the rows
+func complexSpherical(rows []complex128, width int, weights []complex128, epsilon complex128) (output, gradient []complex128) {
+	a := tc128.NewV(width, len(rows)/width)
+	a.X = append(a.X, rows...)
+	a.D = make([]complex128, len(rows))
+	options := map[string]interface{}{
+		"epsilon": &epsilon,
+	}
+	ComplexSphericalSoftmax(func(c *tc128.V) bool {
+		output = append(output, c.X...)
+		copy(c.D, weights)
+		return false
+	}, 0, &a, options)
+	return output, a.D
+}
+
+func TestComplexSphericalSoftmax(t *testing.T) {
+	const width = 3
+	weights := []complex128{.3 + .1i, -1.2, .7 - .5i, 2i, -.4 + .2i, .9}
+	cases := []struct {
+		name    string
+		rows    []complex128
+		epsilon complex128
+	}{
+		{"plain", []complex128{.5 + .2i, -1 + .3i, 2 - .1i, .25i, 1 - 1.5i, -.5 + .75i}, 0},
+		{"epsilon", []complex128{.5 + .2i, -1 + .3i, 2 - .1i, .25i, 1 - 1.5i, -.5 + .75i}, .1},
+		{"near zero", []complex128{1e-3 + 1e-3i, -2e-3, 1.5e-3i, 5e-4, 2e-3 - 1e-3i, 3e-3}, 0},
+		{"near zero epsilon", []complex128{1e-3 + 1e-3i, -2e-3, 1.5e-3i, 5e-4, 2e-3 - 1e-3i, 3e-3}, 1e-6},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output, gradient := complexSpherical(c.rows, width, weights, c.epsilon)
+
+			for row := 0; row < len(c.rows)/width; row++ {
+				sum, values := complex128(0), make([]complex128, width)
+				for j := range values {
+					x := c.rows[row*width+j]
+					values[j] = x*x + c.epsilon
+					sum += values[j]
+				}
+				for j, value := range values {
+					if got, want := output[row*width+j], value/sum; cmplx.Abs(got-want) > 1e-9 {
+						t.Errorf("output %d of row %d is %v but should be %v", j, row, got, want)
+					}
+				}
+			}
+
+			// The softmax is holomorphic, so the derivative along the real axis is the gradient
+			cost := func(rows []complex128) complex128 {
+				output, _ := complexSpherical(rows, width, weights, c.epsilon)
+				sum := complex128(0)
+				for i, value := range output {
+					sum += weights[i] * value
+				}
+				return sum
+			}
+			for i := range c.rows {
+				h := complex(1e-6*cmplx.Abs(c.rows[i]), 0)
+				rows := append([]complex128(nil), c.rows...)
+				rows[i] = c.rows[i] + h
+				plus := cost(rows)
+				rows[i] = c.rows[i] - h
+				minus := cost(rows)
+				want := (plus - minus) / (2 * h)
+				if got := gradient[i]; cmplx.IsNaN(got) || cmplx.Abs(got-want) > 1e-5*(1+cmplx.Abs(want)) {
+					t.Errorf("gradient %d is %v but should be %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComplexSphericalSoftmaxZero(t *testing.T) {
+	const width = 3
+	weights := []complex128{1, 2i, 3}
+	for _, epsilon := range []complex128{0, 1e-3} {
+		output, gradient := complexSpherical(make([]complex128, width), width, weights, epsilon)
+		for i, value := range output {
+			if cmplx.Abs(value-1.0/width) > 1e-12 {
+				t.Errorf("output %d for epsilon %v is %v but should be uniform", i, epsilon, value)
+			}
+		}
+		for i, value := range gradient {
+			if value != 0 {
+				t.Errorf("gradient %d for epsilon %v is %v but should be zero", i, epsilon, value)
+			}
+		}
+	}
+}
+
+func TestComplexNetwork(t *testing.T) {
+	samples := []Sample{
+		{Measures: []float64{1, .1, .2, .1}, Label: "a"},
+		{Measures: []float64{.9, .2, .1, .2}, Label: "a"},
+		{Measures: []float64{.1, 1, .2, .1}, Label: "b"},
+		{Measures: []float64{.2, .9, .1, .1}, Label: "b"},
+		{Measures: []float64{.1, .2, 1, .9}, Label: "c"},
+		{Measures: []float64{.2, .1, .9, 1}, Label: "c"},
+	}
+	width, length := 4, len(samples)
+	n := NewComplexNetwork(width, length, WithLearningRate(.01), WithSeed(1))
+	for i, sample := range samples {
+		copy(n.Point.X[i*width:(i+1)*width], Complex(sample.Measures))
+	}
+	for i := 0; i < 64; i++ {
+		sample := samples[i%len(samples)]
+		cost := n.Iterate(Complex(sample.Measures))
+		if cmplx.IsNaN(cost) || cmplx.IsInf(cost) {
+			t.Fatalf("cost is %v at step %d", cost, i)
+		}
+	}
+	for _, value := range n.Point.X {
+		if cmplx.IsNaN(value) || cmplx.IsInf(value) {
+			t.Fatalf("point is %v", value)
+		}
+	}
+
+	entropy := n.GetEntropy(samples)
+	if len(entropy) != len(samples) {
+		t.Fatalf("there are %d entropies but should be %d", len(entropy), len(samples))
+	}
+	sorted := make([]ComplexEntropy, len(entropy))
+	for i, e := range entropy {
+		if cmplx.IsNaN(e.Entropy) || cmplx.IsInf(e.Entropy) {
+			t.Errorf("entropy %d is %v", i, e.Entropy)
+		}
+		if e.Index != i || e.Label != samples[i].Label {
+			t.Errorf("entropy %d has index %d and label %s", i, e.Index, e.Label)
+		}
+		if e.Order < 0 || e.Order >= len(sorted) || sorted[e.Order].Measures != nil {
+			t.Fatalf("entropy %d has an invalid order %d", i, e.Order)
+		}
+		sorted[e.Order] = e
+	}
+	for i := 1; i < len(sorted); i++ {
+		if cmplx.Abs(sorted[i-1].Entropy) < cmplx.Abs(sorted[i].Entropy) {
+			t.Errorf("entropy of order %d is less than the entropy of order %d", i-1, i)
+		}
+	}
+}
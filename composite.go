@@ -0,0 +1,128 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Term is a weighted term of a composite cost
+type Term struct {
+	Name   string
+	Cost   tf32.Meta
+	Weight float32
+	// Schedule adjusts the weight for the step if it isn't nil
+	Schedule func(step int) float32
+}
+
+// Composite is a weighted sum of cost terms
+type Composite struct {
+	Terms []*Term
+	Cost  tf32.Meta
+}
+
+// EntropyTerm is the self entropy of the l2 output
+func (n *Network) EntropyTerm(weight float32) *Term {
+	return &Term{
+		Name:   "entropy",
+		Cost:   tf32.Entropy(n.L2),
+		Weight: weight,
+	}
+}
+
+// ReconstructionTerm is the quadratic loss between the l2 output and the input
+func (n *Network) ReconstructionTerm(weight float32) *Term {
+	return &Term{
+		Name:   "reconstruction",
+		Cost:   tf32.Quadratic(n.L2, n.Others.Get("input")),
+		Weight: weight,
+	}
+}
+
+// SupervisedTerm is the cross entropy of the classification head, see Supervise.
+// The label is set with Supervision.SetLabel.
+func (n *Network) SupervisedTerm(weight float32) *Term {
+	if n.Supervision == nil {
+		panic("supervision has not been enabled")
+	}
+	return &Term{
+		Name:   "supervised",
+		Cost:   n.Supervision.CrossEntropy,
+		Weight: weight,
+	}
+}
+
+// KLTerm is the kl divergence between the attention distribution and the uniform prior
+func (n *Network) KLTerm(weight float32) *Term {
+	logK := n.constant("klLogK", float32(math.Log(float64(n.Length))))
+	return &Term{
+		Name:   "kl",
+		Cost:   tf32.Sub(logK, tf32.Entropy(n.L1)),
+		Weight: weight,
+	}
+}
+
+// WeightDecayTerm is the sum of the squares of the points
+func (n *Network) WeightDecayTerm(weight float32) *Term {
+	points := n.Point.Meta()
+	return &Term{
+		Name:   "decay",
+		Cost:   tf32.Sum(tf32.Hadamard(points, points)),
+		Weight: weight,
+	}
+}
+
+// NewComposite creates a cost that is the weighted sum of the terms
+func NewComposite(terms ...*Term) *Composite {
+	if len(terms) == 0 {
+		panic("a composite cost needs at least one term")
+	}
+	scale := tf32.U(Scale)
+	c := Composite{
+		Terms: terms,
+	}
+	for _, term := range terms {
+		cost := scale(term.Cost, map[string]interface{}{
+			"scale": &term.Weight,
+		})
+		if c.Cost == nil {
+			c.Cost = cost
+			continue
+		}
+		c.Cost = tf32.Add(c.Cost, cost)
+	}
+	return &c
+}
+
+// IterateComposite does a gradient descent operation on the sample using the composite cost.
+// The weights of the terms with schedules are updated before the gradients are calculated. The
+// classification head is updated if the composite has a supervised term.
+func (n *Network) IterateComposite(data []float64, c *Composite) float32 {
+	for _, term := range c.Terms {
+		if term.Schedule != nil {
+			term.Weight = term.Schedule(n.I)
+		}
+		if term.Name == "supervised" && n.Supervision != nil {
+			n.Supervision.labeled = true
+		}
+	}
+	if n.Supervision != nil {
+		defer func() {
+			n.Supervision.labeled = false
+		}()
+	}
+	for i, measure := range data {
+		n.Input.X[i] = float32(measure)
+	}
+
+	total, _ := n.guard(n.Config.rate, func() float32 {
+		// Calculate the gradients
+		return tf32.Gradient(c.Cost).X[0]
+	})
+
+	return total
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+// Objective is the cost the network is trained on
+type Objective int
+
+const (
+	// ObjectiveEntropy is the self entropy of the l2 output
+	ObjectiveEntropy Objective = iota
+	// ObjectiveReconstruction compares the l2 output to the input
+	ObjectiveReconstruction
+	// ObjectiveBoth is the sum of the entropy and the reconstruction loss
+	ObjectiveBoth
+)
+
+// Reconstruction is the loss used to compare the l2 output to the input
+type Reconstruction int
+
+const (
+	// ReconstructionMSE is the quadratic reconstruction loss
+	ReconstructionMSE Reconstruction = iota
+	// ReconstructionCosine is one minus the cosine similarity
+	ReconstructionCosine
+)
+
+// Activation is the softmax variant used for the attention
+type Activation int
+
+const (
+	// ActivationSoftmax is the softmax function for big numbers
+	ActivationSoftmax Activation = iota
+	// ActivationSpherical is the spherical softmax function
+	ActivationSpherical
+)
+
+// Kernel is the function used to score the points against the input before the softmax
+type Kernel int
+
+const (
+	// KernelDot scores the points with the dot product
+	KernelDot Kernel = iota
+	// KernelRBF scores the points with the negative squared distance scaled by gamma,
+	// which is the log of a radial basis function kernel
+	KernelRBF
+)
+
+// NetworkConfig is the configuration of a network
+type NetworkConfig struct {
+	// Eta is the learning rate
+	Eta float32
+	// Activation is the softmax variant used for the attention
+	Activation Activation
+	// Softmax overrides the activation with a custom softmax function
+	Softmax SoftmaxFunc `json:"-"`
+	// Optimizer updates the weights, adam if nil
+	Optimizer Optimizer `json:"-"`
+	// Schedule adjusts the learning rate for each step, constant if nil
+	Schedule Schedule `json:"-"`
+	// Backend multiplies the matrices of the attention, tf32.Mul if nil
+	Backend Backend `json:"-"`
+	// Parallelism is the number of workers of the softmax, the number of cpus if less than 1
+	Parallelism int
+	// Epsilon is added to the squared values of the spherical softmax
+	Epsilon float32
+	// Kernel is the function used to score the points against the input
+	Kernel Kernel
+	// Gamma is the bandwidth of the rbf kernel
+	Gamma float32
+	// Layers is the number of stacked attention blocks
+	Layers int
+	// Residual adds the input of each block to its output followed by layer normalization
+	Residual bool
+	// Bias adds a learned bias for each point to the attention scores of each block
+	Bias bool
+	// Encoding is the positional encoding added to the input
+	Encoding Encoding
+	// Positions is the number of positions for the positional encoding
+	Positions int
+	// Objective is the cost the network is trained on
+	Objective Objective
+	// Cost overrides the objective with a custom cost, for example one of Costs
+	Cost CostFunc `json:"-"`
+	// Reconstruction is the reconstruction loss
+	Reconstruction Reconstruction
+	// Variational adds a kl divergence between the attention distribution and a uniform prior
+	Variational bool
+	// Beta is the weight of the kl divergence
+	Beta float32
+	// Temperature divides the attention logits before the softmax, lower temperatures sharpen the attention
+	Temperature float32
+	// Annealing adjusts the temperature for each step, constant if nil
+	Annealing Schedule `json:"-"`
+	// Initializer initializes the points of each layer, the points of the first layer are zero and the
+	// points of the other layers are uniform between -1 and 1 if nil
+	Initializer Initializer `json:"-"`
+	// ClipNorm scales the gradients down to this global norm if their norm is greater, zero disables it
+	ClipNorm float32
+	// ClipValue clips each partial derivative to plus or minus this value, zero disables it
+	ClipValue float32
+}
+
+// DefaultNetworkConfig is the default network configuration
+func DefaultNetworkConfig() NetworkConfig {
+	return NetworkConfig{
+		Eta:         Eta,
+		Layers:      1,
+		Beta:        1,
+		Temperature: 1,
+		Gamma:       1,
+	}
+}
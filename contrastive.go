@@ -0,0 +1,81 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Pair is a pair of samples that must or cannot be in the same cluster
+type Pair struct {
+	A    []float64
+	B    []float64
+	Must bool
+}
+
+// Contrastive is the graph for the similarity of the attention of two samples
+type Contrastive struct {
+	A          *tf32.V
+	B          *tf32.V
+	Scale      float32
+	Similarity tf32.Meta
+}
+
+// contrastive builds the contrastive graph
+func (n *Network) contrastive() *Contrastive {
+	if n.Contrastive != nil {
+		return n.Contrastive
+	}
+	c := Contrastive{}
+	n.Others.Add("a", n.Width, 1)
+	c.A = n.Others.ByName["a"]
+	c.A.X = c.A.X[:cap(c.A.X)]
+	n.Others.Add("b", n.Width, 1)
+	c.B = n.Others.ByName["b"]
+	c.B.X = c.B.X[:cap(c.B.X)]
+
+	attend, scale := n.attend(), tf32.U(Scale)
+	points := n.Point.Meta()
+	la := attend(n.score(points, n.Others.Get("a")))
+	lb := attend(n.score(points, n.Others.Get("b")))
+	c.Similarity = scale(tf32.Similarity(la, lb), map[string]interface{}{
+		"scale": &c.Scale,
+	})
+	n.Contrastive = &c
+	return n.Contrastive
+}
+
+// IterateContrastive does a gradient descent operation on the sample with a contrastive term
+// for each of the pairs. Must link pairs add weight*(1 - similarity) to the cost and cannot link
+// pairs add weight*similarity, where similarity is the cosine similarity of the attention of the pair.
+func (n *Network) IterateContrastive(data []float64, pairs []Pair, weight float32) float32 {
+	c := n.contrastive()
+	for i, measure := range data {
+		n.Input.X[i] = float32(measure)
+	}
+
+	total, _ := n.guard(n.Config.rate, func() float32 {
+		// Calculate the gradients
+		total := tf32.Gradient(n.Cost).X[0]
+		for _, pair := range pairs {
+			for i, measure := range pair.A {
+				c.A.X[i] = float32(measure)
+			}
+			for i, measure := range pair.B {
+				c.B.X[i] = float32(measure)
+			}
+			if pair.Must {
+				c.Scale = -weight
+				total += weight + tf32.Gradient(c.Similarity).X[0]
+			} else {
+				c.Scale = weight
+				total += tf32.Gradient(c.Similarity).X[0]
+			}
+		}
+		return total
+	})
+
+	return total
+}
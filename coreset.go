@@ -0,0 +1,97 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Coreset is a weighted subset of samples that preserves the clustering structure of the full set
+type Coreset struct {
+	// Indexes are the indexes of the selected samples in the full set
+	Indexes []int
+	// Samples are the selected samples
+	Samples [][]float64
+	// Weights are the weights of the selected samples, which sum to about the size of the full set
+	Weights []float64
+	// cumulative is the cumulative sum of the weights for sampling
+	cumulative []float64
+}
+
+// NewCoreset builds a lightweight coreset of size samples. Samples are selected with probability half
+// uniform and half proportional to their squared distance from the mean, and weighted by the inverse of
+// their probability, so sums over the coreset are unbiased estimates of sums over the full set. nil is
+// returned if there are no samples or size isn't positive.
+func NewCoreset(rnd *rand.Rand, samples [][]float64, size int) *Coreset {
+	if len(samples) == 0 || size <= 0 {
+		return nil
+	}
+	width := len(samples[0])
+	mean := make([]float64, width)
+	for _, sample := range samples {
+		for j, measure := range sample {
+			mean[j] += measure / float64(len(samples))
+		}
+	}
+	distances, sum := make([]float64, len(samples)), 0.0
+	for i, sample := range samples {
+		for j, measure := range sample {
+			difference := measure - mean[j]
+			distances[i] += difference * difference
+		}
+		sum += distances[i]
+	}
+
+	probabilities, cumulative := make([]float64, len(samples)), make([]float64, len(samples))
+	total := 0.0
+	for i := range samples {
+		probabilities[i] = .5 / float64(len(samples))
+		if sum > 0 {
+			probabilities[i] += .5 * distances[i] / sum
+		} else {
+			probabilities[i] *= 2
+		}
+		total += probabilities[i]
+		cumulative[i] = total
+	}
+
+	coreset := &Coreset{
+		Indexes: make([]int, size),
+		Samples: make([][]float64, size),
+		Weights: make([]float64, size),
+	}
+	for i := 0; i < size; i++ {
+		index := sort.SearchFloat64s(cumulative, rnd.Float64()*total)
+		if index >= len(samples) {
+			index = len(samples) - 1
+		}
+		coreset.Indexes[i] = index
+		coreset.Samples[i] = samples[index]
+		coreset.Weights[i] = 1 / (float64(size) * probabilities[index])
+	}
+	return coreset
+}
+
+// Sample returns a sample of the coreset drawn with probability proportional to its weight, or nil if
+// the coreset is empty
+func (c *Coreset) Sample(rnd *rand.Rand) []float64 {
+	if c == nil || len(c.Samples) == 0 {
+		return nil
+	}
+	if c.cumulative == nil {
+		c.cumulative = make([]float64, len(c.Weights))
+		total := 0.0
+		for i, weight := range c.Weights {
+			total += weight
+			c.cumulative[i] = total
+		}
+	}
+	index := sort.SearchFloat64s(c.cumulative, rnd.Float64()*c.cumulative[len(c.cumulative)-1])
+	if index >= len(c.Samples) {
+		index = len(c.Samples) - 1
+	}
+	return c.Samples[index]
+}
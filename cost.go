@@ -0,0 +1,152 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// CostFunc builds the cost a network is trained on from the graph of the network, it is called after l1
+// and l2 are built
+type CostFunc func(n *Network) tf32.Meta
+
+// Costs are the builtin costs by name
+var Costs = map[string]CostFunc{
+	"entropy":        CostEntropy,
+	"reconstruction": CostReconstruction,
+	"divergence":     CostDivergence,
+	"crossentropy":   CostCrossEntropy(1),
+}
+
+// logarithm is the log of a probability that is clamped away from zero
+func logarithm(p float32) float32 {
+	const E = 1e-7
+	if p < E {
+		p = E
+	}
+	return float32(math.Log(float64(p)))
+}
+
+// CrossEntropy computes the cross entropy of each column of a against the target distribution in the
+// same column of b, -sum b log a. The target doesn't get a gradient, and a target of zeros has a cross
+// entropy of zero.
+func CrossEntropy(k tf32.Continuation, node int, a, b *tf32.V, options ...map[string]interface{}) bool {
+	if len(a.S) != 2 || len(b.S) != 2 {
+		panic("tensor needs to have two dimensions")
+	}
+	width := a.S[0]
+	if width != b.S[0] || a.S[1] != b.S[1] {
+		panic("dimensions are not the same")
+	}
+	c := tf32.NewV(a.S[1])
+	for i := 0; i < len(a.X); i += width {
+		sum := float32(0.0)
+		for j, ax := range a.X[i : i+width] {
+			sum += b.X[i+j] * logarithm(ax)
+		}
+		c.X = append(c.X, -sum)
+	}
+	if k(&c) {
+		return true
+	}
+	for i := 0; i < len(a.X); i += width {
+		d := c.D[i/width]
+		for j, ax := range a.X[i : i+width] {
+			if ax < 1e-7 {
+				continue
+			}
+			a.D[i+j] -= d * b.X[i+j] / ax
+		}
+	}
+	return false
+}
+
+// Divergence computes the kl divergence of each column of a from the target distribution in the same
+// column of b, sum b log(b / a). The target doesn't get a gradient.
+func Divergence(k tf32.Continuation, node int, a, b *tf32.V, options ...map[string]interface{}) bool {
+	if len(a.S) != 2 || len(b.S) != 2 {
+		panic("tensor needs to have two dimensions")
+	}
+	width := a.S[0]
+	if width != b.S[0] || a.S[1] != b.S[1] {
+		panic("dimensions are not the same")
+	}
+	c := tf32.NewV(a.S[1])
+	for i := 0; i < len(a.X); i += width {
+		sum := float32(0.0)
+		for j, ax := range a.X[i : i+width] {
+			if bx := b.X[i+j]; bx > 0 {
+				sum += bx * (logarithm(bx) - logarithm(ax))
+			}
+		}
+		c.X = append(c.X, sum)
+	}
+	if k(&c) {
+		return true
+	}
+	for i := 0; i < len(a.X); i += width {
+		d := c.D[i/width]
+		for j, ax := range a.X[i : i+width] {
+			if ax < 1e-7 {
+				continue
+			}
+			a.D[i+j] -= d * b.X[i+j] / ax
+		}
+	}
+	return false
+}
+
+// CostEntropy is the self entropy of the l2 output, which is the default cost
+func CostEntropy(n *Network) tf32.Meta {
+	return tf32.Entropy(n.L2)
+}
+
+// CostReconstruction is the quadratic loss between the l2 output and the input
+func CostReconstruction(n *Network) tf32.Meta {
+	return tf32.Quadratic(n.L2, n.Others.Get("input"))
+}
+
+// CostDivergence is the kl divergence of the l2 output from the input, so the inputs have to be
+// distributions
+func CostDivergence(n *Network) tf32.Meta {
+	return tf32.B(Divergence)(n.L2, n.Others.Get("input"))
+}
+
+// CostCrossEntropy returns a cost for semi-supervised training that adds the cross entropy of the l1
+// attention against the target distribution over the points set by SetTarget, scaled by weight, to the
+// self entropy of the l2 output. The target of an unlabeled sample is zeros, so only the entropy is
+// trained on.
+func CostCrossEntropy(weight float32) CostFunc {
+	return func(n *Network) tf32.Meta {
+		n.Others.Add("target", n.Length, 1)
+		target := n.Others.ByName["target"]
+		target.X = target.X[:cap(target.X)]
+		crossEntropy := tf32.B(CrossEntropy)(n.L1, n.Others.Get("target"))
+		return tf32.Add(tf32.Entropy(n.L2), tf32.U(Scale)(crossEntropy, map[string]interface{}{
+			"scale": &weight,
+		}))
+	}
+}
+
+// SetTarget sets the target distribution over the points of CostCrossEntropy for the next sample. A nil
+// target is zeros, which is an unlabeled sample.
+func (n *Network) SetTarget(target []float32) error {
+	t := n.Others.ByName["target"]
+	if t == nil {
+		return errors.New("the cost doesn't have a target")
+	}
+	if target != nil && len(target) != len(t.X) {
+		return fmt.Errorf("size of the target is %d but should be %d", len(target), len(t.X))
+	}
+	for i := range t.X {
+		t.X[i] = 0
+	}
+	copy(t.X, target)
+	return nil
+}
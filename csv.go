@@ -0,0 +1,139 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Missing are the values of a csv field that mark a missing measure
+var Missing = map[string]bool{
+	"":     true,
+	"?":    true,
+	"na":   true,
+	"nan":  true,
+	"null": true,
+}
+
+// LoadCSV loads samples from csv data. The field in labelColumn is the label of a sample and the other
+// fields are its measures, a negative labelColumn loads samples without labels. The first row is skipped
+// if it isn't numeric. Missing measures are replaced with the mean of their column.
+func LoadCSV(r io.Reader, labelColumn int) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv has no rows")
+	}
+	if labelColumn >= len(records[0]) {
+		return nil, fmt.Errorf("label column %d is out of range", labelColumn)
+	}
+
+	// parse parses the measures of a row, missing measures are nan
+	parse := func(record []string) ([]float64, error) {
+		measures := make([]float64, 0, len(record))
+		for i, field := range record {
+			if i == labelColumn {
+				continue
+			}
+			field = strings.TrimSpace(field)
+			if Missing[strings.ToLower(field)] {
+				measures = append(measures, math.NaN())
+				continue
+			}
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, err
+			}
+			measures = append(measures, value)
+		}
+		return measures, nil
+	}
+	width, header := len(records[0]), 0
+	if _, err := parse(records[0]); err != nil {
+		records, header = records[1:], 1
+	}
+
+	samples := make([]Sample, 0, len(records))
+	sums, counts := make([]float64, width), make([]int, width)
+	for i, record := range records {
+		measures, err := parse(record)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1+header, err)
+		}
+		for j, measure := range measures {
+			if !math.IsNaN(measure) {
+				sums[j] += measure
+				counts[j]++
+			}
+		}
+		sample := Sample{
+			Measures: measures,
+		}
+		if labelColumn >= 0 {
+			sample.Label = strings.TrimSpace(record[labelColumn])
+		}
+		samples = append(samples, sample)
+	}
+	for _, sample := range samples {
+		for j, measure := range sample.Measures {
+			if math.IsNaN(measure) {
+				if counts[j] > 0 {
+					sample.Measures[j] = sums[j] / float64(counts[j])
+				} else {
+					sample.Measures[j] = 0
+				}
+			}
+		}
+	}
+	return samples, nil
+}
+
+// Standardize scales the measures of the samples in place to zero mean and unit variance per column
+// and returns the normalization, so other inputs can be scaled the same way. Constant columns are set
+// to zero.
+func Standardize(samples []Sample) Normalization {
+	if len(samples) == 0 {
+		return Normalization{}
+	}
+	width := len(samples[0].Measures)
+	mean, variance := make([]float64, width), make([]float64, width)
+	for _, sample := range samples {
+		for j, measure := range sample.Measures {
+			mean[j] += measure
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(samples))
+	}
+	for _, sample := range samples {
+		for j, measure := range sample.Measures {
+			diff := measure - mean[j]
+			variance[j] += diff * diff
+		}
+	}
+	deviation := make([]float64, width)
+	for j := range deviation {
+		deviation[j] = math.Sqrt(variance[j] / float64(len(samples)))
+	}
+	normalization := Normalization{
+		Kind:      NormalizeStandard,
+		Mean:      mean,
+		Deviation: deviation,
+	}
+	for _, sample := range samples {
+		normalization.Apply(sample.Measures)
+	}
+	return normalization
+}
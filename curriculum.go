@@ -0,0 +1,102 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Curriculum orders or reweights the training samples over time
+type Curriculum interface {
+	// Next returns the index of the training sample for the step
+	Next(step int) int
+}
+
+// Stage is a stage of a curriculum that samples uniformly from the samples in [Begin, End) until Steps
+type Stage struct {
+	Steps int
+	Begin int
+	End   int
+}
+
+// StagedCurriculum is a curriculum made of a sequence of stages. The last stage is used after all of
+// the stages have been completed.
+type StagedCurriculum struct {
+	Rnd    *rand.Rand
+	Stages []Stage
+}
+
+// Next returns the index of the training sample for the step
+func (c *StagedCurriculum) Next(step int) int {
+	stage := c.Stages[len(c.Stages)-1]
+	for _, s := range c.Stages {
+		if step < s.Steps {
+			stage = s
+			break
+		}
+	}
+	return stage.Begin + c.Rnd.Intn(stage.End-stage.Begin)
+}
+
+// EntropyCurriculum samples the training samples in proportion to their current entropy,
+// so the samples the network hasn't organized yet are seen more often.
+// The entropies are recomputed every Interval steps.
+type EntropyCurriculum struct {
+	Rnd        *rand.Rand
+	Network    *Network
+	Samples    [][]float64
+	Interval   int
+	cumulative []float64
+	last       int
+}
+
+// Next returns the index of the training sample for the step
+func (c *EntropyCurriculum) Next(step int) int {
+	if c.cumulative == nil || step-c.last >= c.Interval {
+		c.cumulative = make([]float64, len(c.Samples))
+		sum := 0.0
+		for i, sample := range c.Samples {
+			entropy := float64(c.Network.entropy(sample))
+			if math.IsNaN(entropy) || entropy < 0 {
+				entropy = 0
+			}
+			sum += entropy
+			c.cumulative[i] = sum
+		}
+		c.last = step
+	}
+	total := c.cumulative[len(c.cumulative)-1]
+	if total == 0 {
+		return c.Rnd.Intn(len(c.Samples))
+	}
+	return sort.SearchFloat64s(c.cumulative, c.Rnd.Float64()*total)
+}
+
+// entropy computes the entropy of a single sample
+func (n *Network) entropy(sample []float64) float32 {
+	for i, measure := range sample {
+		n.Input.X[i] = float32(measure)
+	}
+	entropy := float32(0.0)
+	n.Cost(func(a *tf32.V) bool {
+		entropy = a.X[0]
+		return true
+	})
+	return entropy
+}
+
+// TrainCurriculum trains the network until steps using the curriculum to select the samples
+func (n *Network) TrainCurriculum(samples [][]float64, steps int, curriculum Curriculum) {
+	for n.I < steps {
+		total := n.Iterate(samples[curriculum.Next(n.I)])
+		if !finite(total) {
+			break
+		}
+	}
+}
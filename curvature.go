@@ -0,0 +1,113 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// gradient returns the gradient of the cost summed over the samples with respect to the points
+func (n *Network) gradient(samples [][]float64) []float32 {
+	for _, sample := range samples {
+		for i, measure := range sample {
+			n.Input.X[i] = float32(measure)
+		}
+		tf32.Gradient(n.Cost)
+	}
+	gradient := make([]float32, len(n.Point.D))
+	copy(gradient, n.Point.D)
+	n.Set.Zero()
+	n.Others.Zero()
+	return gradient
+}
+
+// FisherDiagonal estimates the diagonal of the empirical fisher information of the cost with respect
+// to the points as the average of the squared per sample gradients
+func (n *Network) FisherDiagonal(samples [][]float64) []float32 {
+	fisher := make([]float32, len(n.Point.X))
+	for _, sample := range samples {
+		gradient := n.gradient([][]float64{sample})
+		for i, g := range gradient {
+			fisher[i] += g * g
+		}
+	}
+	for i := range fisher {
+		fisher[i] /= float32(len(samples))
+	}
+	return fisher
+}
+
+// HessianDiagonal estimates the diagonal of the hessian of the cost summed over the samples with
+// respect to the points. Hutchinson's estimator is used with probes rademacher vectors, and the hessian
+// vector products are computed with central differences of the gradient with step size epsilon.
+func (n *Network) HessianDiagonal(samples [][]float64, probes int, epsilon float32) []float32 {
+	diagonal := make([]float32, len(n.Point.X))
+	probe := make([]float32, len(n.Point.X))
+	original := make([]float32, len(n.Point.X))
+	copy(original, n.Point.X)
+	for p := 0; p < probes; p++ {
+		for i := range probe {
+			probe[i] = 1
+			if n.Rnd.Intn(2) == 0 {
+				probe[i] = -1
+			}
+		}
+		for i := range n.Point.X {
+			n.Point.X[i] = original[i] + epsilon*probe[i]
+		}
+		plus := n.gradient(samples)
+		for i := range n.Point.X {
+			n.Point.X[i] = original[i] - epsilon*probe[i]
+		}
+		minus := n.gradient(samples)
+		for i := range diagonal {
+			diagonal[i] += probe[i] * (plus[i] - minus[i]) / (2 * epsilon)
+		}
+	}
+	copy(n.Point.X, original)
+	for i := range diagonal {
+		diagonal[i] /= float32(probes)
+	}
+	return diagonal
+}
+
+// Sensitivity is the fisher information of each point accumulated from the squared gradients during training
+type Sensitivity struct {
+	Sum   []float32
+	Count int
+}
+
+// accumulate adds the squared gradients of the points to the sums
+func (s *Sensitivity) accumulate(n *Network) {
+	if s.Sum == nil {
+		s.Sum = make([]float32, n.Length)
+	}
+	for i, d := range n.Point.D {
+		s.Sum[i/n.Width] += d * d
+	}
+	s.Count++
+}
+
+// PointFisher returns the fisher information of each point averaged over the training steps so far.
+// Points with a score near zero aren't doing any work and are candidates for pruning.
+func (n *Network) PointFisher() []float32 {
+	scores := make([]float32, n.Length)
+	if n.Sensitivity.Count == 0 {
+		return scores
+	}
+	for i, sum := range n.Sensitivity.Sum {
+		scores[i] = sum / float32(n.Sensitivity.Count)
+	}
+	return scores
+}
+
+// PointFisherOf returns the fisher information of each point computed from the samples
+func (n *Network) PointFisherOf(samples [][]float64) []float32 {
+	scores := make([]float32, n.Length)
+	for i, f := range n.FisherDiagonal(samples) {
+		scores[i/n.Width] += f
+	}
+	return scores
+}
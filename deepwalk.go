@@ -0,0 +1,145 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// DeepWalk is a deepwalk embedding of the attention graph of the points
+type DeepWalk struct {
+	// Dimensions is the number of dimensions of the embedding
+	Dimensions int
+	// Walks is the number of random walks starting from each point
+	Walks int
+	// Length is the length of each random walk
+	Length int
+	// Window is the skip gram context window
+	Window int
+	// Negative is the number of negative samples per context
+	Negative int
+	// Eta is the learning rate
+	Eta float64
+}
+
+// DefaultDeepWalk returns the default deepwalk parameters
+func DefaultDeepWalk() DeepWalk {
+	return DeepWalk{
+		Dimensions: 2,
+		Walks:      16,
+		Length:     16,
+		Window:     4,
+		Negative:   4,
+		Eta:        .025,
+	}
+}
+
+// graph returns the transition probabilities of the attention graph of the points, where each point
+// attends to the other points with a softmax of the dot products
+func (n *Network) graph() [][]float64 {
+	transitions := make([][]float64, n.Length)
+	for i := range transitions {
+		a := n.Point.X[i*n.Width : (i+1)*n.Width]
+		row, max := make([]float64, n.Length), math.Inf(-1)
+		for j := range row {
+			if i == j {
+				continue
+			}
+			b := n.Point.X[j*n.Width : (j+1)*n.Width]
+			dot := 0.0
+			for k := range a {
+				dot += float64(a[k] * b[k])
+			}
+			row[j] = dot
+			if dot > max {
+				max = dot
+			}
+		}
+		sum := 0.0
+		for j := range row {
+			if i == j {
+				continue
+			}
+			row[j] = math.Exp(row[j] - max)
+			sum += row[j]
+		}
+		for j := range row {
+			row[j] /= sum
+		}
+		transitions[i] = row
+	}
+	return transitions
+}
+
+// Embed computes a low dimensional embedding of each point using random walks over the attention graph
+// of the points and skip gram with negative sampling
+func (n *Network) Embed(d DeepWalk) [][]float64 {
+	transitions := n.graph()
+	sample := func(row []float64) int {
+		r, sum := n.Rnd.Float64(), 0.0
+		for j, p := range row {
+			sum += p
+			if r < sum {
+				return j
+			}
+		}
+		return len(row) - 1
+	}
+
+	embedding, context := make([][]float64, n.Length), make([][]float64, n.Length)
+	for i := range embedding {
+		embedding[i], context[i] = make([]float64, d.Dimensions), make([]float64, d.Dimensions)
+		for j := range embedding[i] {
+			embedding[i][j] = (n.Rnd.Float64() - .5) / float64(d.Dimensions)
+		}
+	}
+	if n.Length < 2 {
+		return embedding
+	}
+
+	sigmoid := func(x float64) float64 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	gradient := make([]float64, d.Dimensions)
+	train := func(a, b int, label float64) {
+		dot := 0.0
+		for k := range embedding[a] {
+			dot += embedding[a][k] * context[b][k]
+		}
+		g := d.Eta * (label - sigmoid(dot))
+		for k := range gradient {
+			gradient[k] += g * context[b][k]
+			context[b][k] += g * embedding[a][k]
+		}
+	}
+
+	walk := make([]int, d.Length)
+	for w := 0; w < d.Walks; w++ {
+		for start := 0; start < n.Length; start++ {
+			walk[0] = start
+			for i := 1; i < len(walk); i++ {
+				walk[i] = sample(transitions[walk[i-1]])
+			}
+			for i, a := range walk {
+				for j := i - d.Window; j <= i+d.Window; j++ {
+					if j < 0 || j >= len(walk) || j == i {
+						continue
+					}
+					for k := range gradient {
+						gradient[k] = 0
+					}
+					train(a, walk[j], 1)
+					for k := 0; k < d.Negative; k++ {
+						train(a, n.Rnd.Intn(n.Length), 0)
+					}
+					for k, g := range gradient {
+						embedding[a][k] += g
+					}
+				}
+			}
+		}
+	}
+	return embedding
+}
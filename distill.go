@@ -0,0 +1,96 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// KL computes the kl divergence of the rows of b from the rows of a, where b is the target distribution
+func KL(k tf32.Continuation, node int, a, b *tf32.V, options ...map[string]interface{}) bool {
+	const E = 1e-8
+	if len(a.S) != 2 || len(b.S) != 2 {
+		panic("tensor needs to have two dimensions")
+	}
+	width := a.S[0]
+	if width != b.S[0] || a.S[1] != b.S[1] {
+		panic("dimensions are not the same")
+	}
+	c, size := tf32.NewV(a.S[1]), len(a.X)
+	for i := 0; i < size; i += width {
+		av, bv, sum := a.X[i:i+width], b.X[i:i+width], float32(0.0)
+		for j, ax := range av {
+			bx := bv[j]
+			if bx > 0 {
+				sum += bx * float32(math.Log(float64(bx)/float64(ax+E)))
+			}
+		}
+		c.X = append(c.X, sum)
+	}
+	if k(&c) {
+		return true
+	}
+	index := 0
+	for i := 0; i < size; i += width {
+		av, bv, ad, d := a.X[i:i+width], b.X[i:i+width], a.D[i:i+width], c.D[index]
+		for j, ax := range av {
+			ad[j] -= d * bv[j] / (ax + E)
+		}
+		index++
+	}
+	return false
+}
+
+// Distillation is the graph for distilling a teacher network into a student network
+type Distillation struct {
+	Target *tf32.V
+	Weight float32
+	Cost   tf32.Meta
+}
+
+// distillation builds the distillation graph
+func (n *Network) distillation() *Distillation {
+	if n.Distillation != nil {
+		return n.Distillation
+	}
+	d := Distillation{}
+	n.Others.Add("distill", n.Width, 1)
+	d.Target = n.Others.ByName["distill"]
+	d.Target.X = d.Target.X[:cap(d.Target.X)]
+	kl, scale := tf32.B(KL), tf32.U(Scale)
+	d.Cost = tf32.Add(n.Cost, scale(kl(n.L2, n.Others.Get("distill")), map[string]interface{}{
+		"scale": &d.Weight,
+	}))
+	n.Distillation = &d
+	return n.Distillation
+}
+
+// Distill does a gradient descent operation on the sample where the l2 output of the teacher is
+// a soft target for the l2 output of the network. The teacher can have a different number of points
+// than the network, so a large converged network can be compressed into a smaller one.
+func (n *Network) Distill(teacher *Network, data []float64, weight float32) float32 {
+	if teacher.Width != n.Width {
+		panic("teacher and student must have the same width")
+	}
+	d := n.distillation()
+	d.Weight = weight
+	for i, measure := range data {
+		teacher.Input.X[i] = float32(measure)
+		n.Input.X[i] = float32(measure)
+	}
+	teacher.L2(func(a *tf32.V) bool {
+		copy(d.Target.X, a.X)
+		return true
+	})
+
+	total, _ := n.guard(n.Config.rate, func() float32 {
+		// Calculate the gradients
+		return tf32.Gradient(d.Cost).X[0]
+	})
+
+	return total
+}
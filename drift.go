@@ -0,0 +1,231 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// DriftStatistics are the changes in the clustering of the window between two reclusterings
+type DriftStatistics struct {
+	// Sample is the number of samples seen
+	Sample int
+	// Movement is the average distance the points moved
+	Movement float64
+	// Churn is the fraction of the samples in the window that changed cluster
+	Churn float64
+}
+
+// DriftEventType is the type of a drift event
+type DriftEventType int
+
+const (
+	// DriftEventEntropy is a sample with an unusually high entropy, a new high entropy region
+	DriftEventEntropy DriftEventType = iota
+	// DriftEventDrift is a reclustering with movement or churn above the thresholds
+	DriftEventDrift
+	// DriftEventSplit is a reclustering with more occupied clusters than the previous one
+	DriftEventSplit
+	// DriftEventMerge is a reclustering with fewer occupied clusters than the previous one
+	DriftEventMerge
+)
+
+// String returns the name of the drift event type
+func (t DriftEventType) String() string {
+	switch t {
+	case DriftEventEntropy:
+		return "entropy"
+	case DriftEventDrift:
+		return "drift"
+	case DriftEventSplit:
+		return "split"
+	case DriftEventMerge:
+		return "merge"
+	}
+	return "unknown"
+}
+
+// DriftEvent is a change in the distribution of the samples
+type DriftEvent struct {
+	Type DriftEventType
+	// Sample is the number of samples seen
+	Sample int
+	// Entropy is the entropy of the sample for entropy events
+	Entropy float64
+	// Clusters is the number of occupied clusters after the reclustering
+	Clusters int
+	// Statistics are the drift statistics of the reclustering
+	Statistics DriftStatistics
+}
+
+// Drift maintains a window of recent samples and periodically reclusters them, measuring how much the
+// structure of the clusters has changed
+type Drift struct {
+	Network *Network
+	// Window is the number of recent samples kept
+	Window int
+	// Interval is the number of samples between reclusterings, the window isn't reclustered if it is zero
+	Interval int
+	// Steps is the number of training iterations for each reclustering
+	Steps int
+	// Movement and Churn are the thresholds above which the drift callback is called
+	Movement float64
+	Churn    float64
+	// OnDrift is called when the movement or the churn exceed their thresholds
+	OnDrift func(statistics DriftStatistics)
+	// Deviations is the number of standard deviations above the mean entropy for a sample to be an entropy event
+	Deviations float64
+	// Warmup is the number of samples used to estimate the mean and variance of the entropy before entropy
+	// events are emitted, at least 2
+	Warmup int
+	// Events receives the drift events if not nil, events are dropped if the channel is full
+	Events   chan DriftEvent
+	samples  [][]float64
+	seen     int
+	mean     float64
+	variance float64
+	clusters int
+}
+
+// NewDrift creates a new drift detector that reclusters the last window samples with the network every
+// interval samples. The window and interval are at least 1.
+func NewDrift(n *Network, window, interval, steps int) *Drift {
+	if window < 1 {
+		window = 1
+	}
+	if interval < 1 {
+		interval = 1
+	}
+	return &Drift{
+		Network:    n,
+		Window:     window,
+		Interval:   interval,
+		Steps:      steps,
+		Movement:   .1,
+		Churn:      .1,
+		Deviations: 3,
+		Warmup:     10,
+		samples:    make([][]float64, 0, window),
+	}
+}
+
+// emit sends an event without blocking
+func (d *Drift) emit(event DriftEvent) {
+	if d.Events == nil {
+		return
+	}
+	select {
+	case d.Events <- event:
+	default:
+	}
+}
+
+// Stream adds the samples from the channel until it is closed, and returns a channel of drift events
+// which is closed after the last sample
+func (d *Drift) Stream(samples <-chan []float64, buffer int) <-chan DriftEvent {
+	d.Events = make(chan DriftEvent, buffer)
+	go func() {
+		for sample := range samples {
+			d.Add(sample)
+		}
+		close(d.Events)
+	}()
+	return d.Events
+}
+
+// Add adds a sample to the window, and if a reclustering is due reclusters the window and returns the
+// drift statistics
+func (d *Drift) Add(sample []float64) (DriftStatistics, bool) {
+	if len(d.samples) == d.Window {
+		copy(d.samples, d.samples[1:])
+		d.samples = d.samples[:d.Window-1]
+	}
+	d.samples = append(d.samples, sample)
+	d.seen++
+
+	// Running mean and variance of the entropy of the samples
+	n := d.Network
+	entropy := float64(n.entropy(sample))
+	warmup := d.Warmup
+	if warmup < 2 {
+		warmup = 2
+	}
+	if d.seen > warmup && entropy > d.mean+d.Deviations*math.Sqrt(d.variance/float64(d.seen-1)) {
+		d.emit(DriftEvent{
+			Type:    DriftEventEntropy,
+			Sample:  d.seen,
+			Entropy: entropy,
+		})
+	}
+	delta := entropy - d.mean
+	d.mean += delta / float64(d.seen)
+	d.variance += delta * (entropy - d.mean)
+
+	if d.Interval < 1 || d.seen%d.Interval != 0 {
+		return DriftStatistics{}, false
+	}
+
+	before := make([]int, len(d.samples))
+	for i, sample := range d.samples {
+		before[i] = n.Cluster(sample)
+	}
+	points := make([]float32, len(n.Point.X))
+	copy(points, n.Point.X)
+
+	for i := 0; i < d.Steps; i++ {
+		total := n.Iterate(d.samples[n.Rnd.Intn(len(d.samples))])
+		if math.IsNaN(float64(total)) {
+			break
+		}
+	}
+
+	statistics := DriftStatistics{
+		Sample: d.seen,
+	}
+	for i := 0; i < n.Length; i++ {
+		distance := 0.0
+		for j := i * n.Width; j < (i+1)*n.Width; j++ {
+			difference := float64(n.Point.X[j] - points[j])
+			distance += difference * difference
+		}
+		statistics.Movement += math.Sqrt(distance)
+	}
+	statistics.Movement /= float64(n.Length)
+	occupied := make(map[int]bool)
+	for i, sample := range d.samples {
+		cluster := n.Cluster(sample)
+		if cluster != before[i] {
+			statistics.Churn++
+		}
+		occupied[cluster] = true
+	}
+	statistics.Churn /= float64(len(d.samples))
+
+	if statistics.Movement > d.Movement || statistics.Churn > d.Churn {
+		if d.OnDrift != nil {
+			d.OnDrift(statistics)
+		}
+		d.emit(DriftEvent{
+			Type:       DriftEventDrift,
+			Sample:     d.seen,
+			Clusters:   len(occupied),
+			Statistics: statistics,
+		})
+	}
+	if d.clusters > 0 && len(occupied) != d.clusters {
+		event := DriftEvent{
+			Type:       DriftEventSplit,
+			Sample:     d.seen,
+			Clusters:   len(occupied),
+			Statistics: statistics,
+		}
+		if len(occupied) < d.clusters {
+			event.Type = DriftEventMerge
+		}
+		d.emit(event)
+	}
+	d.clusters = len(occupied)
+	return statistics, true
+}
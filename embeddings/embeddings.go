@@ -0,0 +1,269 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package embeddings loads word vectors in the fastText text and word2vec binary formats
+package embeddings
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Vector is a word vector
+type Vector struct {
+	Word   string
+	Vector []float32
+}
+
+// Vectors is a set of word vectors
+type Vectors struct {
+	List       []Vector
+	Dictionary map[string]Vector
+}
+
+// Source is a set of word vectors that can be read by index, either in memory or on disk
+type Source interface {
+	Len() int
+	Vector(i int) (Vector, error)
+}
+
+// Len returns the number of word vectors
+func (v *Vectors) Len() int {
+	return len(v.List)
+}
+
+// Vector returns the ith word vector
+func (v *Vectors) Vector(i int) (Vector, error) {
+	if i < 0 || i >= len(v.List) {
+		return Vector{}, fmt.Errorf("index %d out of range", i)
+	}
+	return v.List[i], nil
+}
+
+// Options are the options for loading word vectors
+type Options struct {
+	// MaxRows is the maximum number of vectors loaded, 0 loads all of the vectors
+	MaxRows int
+	// Lower converts the words to lower case
+	Lower bool
+	// Normalize divides each vector by its largest absolute value
+	Normalize bool
+}
+
+// normalize applies the options to a word and its vector
+func (o Options) normalize(word string, vector []float32) Vector {
+	if o.Lower {
+		word = strings.ToLower(word)
+	}
+	if o.Normalize {
+		max := float32(0)
+		for _, value := range vector {
+			if value < 0 {
+				value = -value
+			}
+			if value > max {
+				max = value
+			}
+		}
+		if max > 0 {
+			for i, value := range vector {
+				vector[i] = value / max
+			}
+		}
+	}
+	return Vector{
+		Word:   word,
+		Vector: vector,
+	}
+}
+
+// open opens a file of word vectors and returns a function that reads its entries. The format is chosen
+// by the extension of the file.
+func open(path string) (io.ReadCloser, func(io.Reader, Options, func(Vector) error) error, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var reader io.ReadCloser = in
+	name := path
+	if strings.HasSuffix(name, ".gz") {
+		gzipReader, err := gzip.NewReader(in)
+		if err != nil {
+			in.Close()
+			return nil, nil, err
+		}
+		reader = struct {
+			io.Reader
+			io.Closer
+		}{gzipReader, in}
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	switch {
+	case strings.HasSuffix(name, ".vec"):
+		return reader, eachText, nil
+	case strings.HasSuffix(name, ".bin"):
+		return reader, eachBinary, nil
+	}
+	in.Close()
+	return nil, nil, fmt.Errorf("unknown format of %s", path)
+}
+
+// Each calls f for each word vector of a file without holding the vectors in memory. The format is
+// chosen by the extension of the file like Load.
+func Each(path string, options Options, f func(Vector) error) error {
+	in, each, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return each(in, options, f)
+}
+
+// Load loads word vectors from a file, the format is chosen by the extension of the file: .vec is the
+// fastText text format and .bin is the word2vec binary format. Both formats can be gzip compressed with
+// an additional .gz extension.
+func Load(path string, options Options) (*Vectors, error) {
+	in, each, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return collect(in, options, each)
+}
+
+// collect collects the word vectors read by each into a set
+func collect(r io.Reader, options Options, each func(io.Reader, Options, func(Vector) error) error) (*Vectors, error) {
+	vectors := &Vectors{
+		Dictionary: make(map[string]Vector),
+	}
+	err := each(r, options, func(vector Vector) error {
+		vectors.List = append(vectors.List, vector)
+		vectors.Dictionary[vector.Word] = vector
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// ReadText reads word vectors in the fastText text format, one word followed by its vector per line.
+// The optional header with the number of words and dimensions is skipped.
+func ReadText(r io.Reader, options Options) (*Vectors, error) {
+	return collect(r, options, eachText)
+}
+
+// eachText calls f for each word vector in the fastText text format
+func eachText(r io.Reader, options Options, f func(Vector) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	first, rows := true, 0
+	for (options.MaxRows <= 0 || rows < options.MaxRows) && scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if first {
+			first = false
+			if len(parts) == 2 {
+				continue
+			}
+		}
+		if len(parts) < 2 {
+			continue
+		}
+		vector := make([]float32, 0, len(parts)-1)
+		for _, part := range parts[1:] {
+			value, err := strconv.ParseFloat(part, 32)
+			if err != nil {
+				return err
+			}
+			vector = append(vector, float32(value))
+		}
+		err := f(options.normalize(parts[0], vector))
+		if err != nil {
+			return err
+		}
+		rows++
+	}
+	return scanner.Err()
+}
+
+// ReadBinary reads word vectors in the word2vec binary format, a header line with the number of words
+// and dimensions followed by each word and its little endian float32 vector
+func ReadBinary(r io.Reader, options Options) (*Vectors, error) {
+	return collect(r, options, eachBinary)
+}
+
+// eachBinary calls f for each word vector in the word2vec binary format
+func eachBinary(r io.Reader, options Options, f func(Vector) error) error {
+	reader := bufio.NewReader(r)
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	var words, dimensions int
+	_, err = fmt.Sscanf(header, "%d %d", &words, &dimensions)
+	if err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	if options.MaxRows > 0 && options.MaxRows < words {
+		words = options.MaxRows
+	}
+	buffer := make([]byte, 4*dimensions)
+	for i := 0; i < words; i++ {
+		word, err := reader.ReadString(' ')
+		if err != nil {
+			return err
+		}
+		word = strings.TrimSpace(word)
+		if word == "" {
+			return errors.New("empty word")
+		}
+		_, err = io.ReadFull(reader, buffer)
+		if err != nil {
+			return err
+		}
+		vector := make([]float32, dimensions)
+		for j := range vector {
+			vector[j] = math.Float32frombits(binary.LittleEndian.Uint32(buffer[4*j:]))
+		}
+		err = f(options.normalize(word, vector))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lazy is a set of word vectors that is loaded the first time it is used
+type Lazy struct {
+	Path    string
+	Options Options
+	once    sync.Once
+	vectors *Vectors
+	err     error
+}
+
+// NewLazy creates a set of word vectors that is loaded from path the first time it is used
+func NewLazy(path string, options Options) *Lazy {
+	return &Lazy{
+		Path:    path,
+		Options: options,
+	}
+}
+
+// Vectors returns the word vectors, loading them if they haven't been loaded
+func (l *Lazy) Vectors() (*Vectors, error) {
+	l.once.Do(func() {
+		l.vectors, l.err = Load(l.Path, l.Options)
+	})
+	return l.vectors, l.err
+}
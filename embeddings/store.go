@@ -0,0 +1,241 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package embeddings
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+// Magic is the first bytes of a store file
+const Magic = "OCCAMVEC"
+
+// headerSize is the size of the header of a store file: the magic, the number of words, and the number of
+// dimensions
+const headerSize = len(Magic) + 4 + 4
+
+// ErrNotFound is returned when a word isn't in a store
+var ErrNotFound = errors.New("word not found")
+
+// CreateStore converts the word vectors of source into a store file at path. The vectors are streamed, so
+// only the words are held in memory. The first vector of a word that occurs more than once is kept.
+func CreateStore(path, source string, options Options) (err error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	writer := bufio.NewWriter(out)
+	_, err = writer.Write(make([]byte, headerSize))
+	if err != nil {
+		return err
+	}
+	words, seen, dimensions := []string{}, make(map[string]bool), 0
+	buffer := []byte{}
+	err = Each(source, options, func(vector Vector) error {
+		if seen[vector.Word] {
+			return nil
+		}
+		if dimensions == 0 {
+			dimensions = len(vector.Vector)
+			buffer = make([]byte, 4*dimensions)
+		} else if len(vector.Vector) != dimensions {
+			return fmt.Errorf("word %d has %d dimensions instead of %d", len(words), len(vector.Vector), dimensions)
+		}
+		for i, value := range vector.Vector {
+			binary.LittleEndian.PutUint32(buffer[4*i:], math.Float32bits(value))
+		}
+		_, err := writer.Write(buffer)
+		if err != nil {
+			return err
+		}
+		seen[vector.Word] = true
+		words = append(words, vector.Word)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	length := make([]byte, 4)
+	for _, word := range words {
+		binary.LittleEndian.PutUint32(length, uint32(len(word)))
+		_, err = writer.Write(length)
+		if err != nil {
+			return err
+		}
+		_, err = writer.WriteString(word)
+		if err != nil {
+			return err
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, Magic)
+	binary.LittleEndian.PutUint32(header[len(Magic):], uint32(len(words)))
+	binary.LittleEndian.PutUint32(header[len(Magic)+4:], uint32(dimensions))
+	_, err = out.WriteAt(header, 0)
+	return err
+}
+
+// entry is an entry of the cache of a store
+type entry struct {
+	Word   string
+	Vector []float32
+}
+
+// Store is an on-disk set of word vectors indexed by word. Only the words and a least recently used cache
+// of the vectors are held in memory, and the vectors are read from the file as they are needed.
+type Store struct {
+	Dimensions int
+	Words      []string
+	Index      map[string]int
+	// Capacity is the maximum number of vectors in the cache
+	Capacity int
+	file     *os.File
+	mutex    sync.Mutex
+	cache    map[string]*list.Element
+	recent   *list.List
+}
+
+// OpenStore opens a store file created by CreateStore with a cache of capacity vectors
+func OpenStore(path string, capacity int) (*Store, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	store, err := newStore(file, capacity)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// newStore reads the header and the words of a store file
+func newStore(file *os.File, capacity int) (*Store, error) {
+	header := make([]byte, headerSize)
+	_, err := io.ReadFull(file, header)
+	if err != nil {
+		return nil, err
+	}
+	if string(header[:len(Magic)]) != Magic {
+		return nil, errors.New("not a store file")
+	}
+	words := int(binary.LittleEndian.Uint32(header[len(Magic):]))
+	dimensions := int(binary.LittleEndian.Uint32(header[len(Magic)+4:]))
+	if capacity < 1 {
+		capacity = 1
+	}
+	store := &Store{
+		Dimensions: dimensions,
+		Words:      make([]string, 0, words),
+		Index:      make(map[string]int, words),
+		Capacity:   capacity,
+		file:       file,
+		cache:      make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+
+	_, err = file.Seek(int64(headerSize)+4*int64(words)*int64(dimensions), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	length := make([]byte, 4)
+	for i := 0; i < words; i++ {
+		_, err = io.ReadFull(reader, length)
+		if err != nil {
+			return nil, err
+		}
+		word := make([]byte, binary.LittleEndian.Uint32(length))
+		_, err = io.ReadFull(reader, word)
+		if err != nil {
+			return nil, err
+		}
+		store.Index[string(word)] = i
+		store.Words = append(store.Words, string(word))
+	}
+	return store, nil
+}
+
+// Len returns the number of words in the store
+func (s *Store) Len() int {
+	return len(s.Words)
+}
+
+// Lookup returns the vector of a word. The vector is shared with the cache and must not be modified.
+func (s *Store) Lookup(word string) ([]float32, error) {
+	index, ok := s.Index[word]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.read(index)
+}
+
+// Vector returns the ith word vector of the store. The vector is shared with the cache and must not be
+// modified.
+func (s *Store) Vector(i int) (Vector, error) {
+	if i < 0 || i >= len(s.Words) {
+		return Vector{}, fmt.Errorf("index %d out of range", i)
+	}
+	vector, err := s.read(i)
+	return Vector{
+		Word:   s.Words[i],
+		Vector: vector,
+	}, err
+}
+
+// read returns the ith vector from the cache or the file
+func (s *Store) read(i int) ([]float32, error) {
+	word := s.Words[i]
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.cache[word]; ok {
+		s.recent.MoveToFront(element)
+		return element.Value.(*entry).Vector, nil
+	}
+
+	buffer := make([]byte, 4*s.Dimensions)
+	_, err := s.file.ReadAt(buffer, int64(headerSize)+4*int64(i)*int64(s.Dimensions))
+	if err != nil {
+		return nil, err
+	}
+	vector := make([]float32, s.Dimensions)
+	for j := range vector {
+		vector[j] = math.Float32frombits(binary.LittleEndian.Uint32(buffer[4*j:]))
+	}
+
+	for s.recent.Len() >= s.Capacity {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.cache, oldest.Value.(*entry).Word)
+	}
+	s.cache[word] = s.recent.PushFront(&entry{
+		Word:   word,
+		Vector: vector,
+	})
+	return vector, nil
+}
+
+// Close closes the file of the store
+func (s *Store) Close() error {
+	return s.file.Close()
+}
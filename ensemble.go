@@ -0,0 +1,158 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"sync"
+)
+
+// Ensemble is a consensus clustering over networks trained with different seeds on noisy copies of the
+// samples
+type Ensemble struct {
+	// Config is the configuration of the networks
+	Config NetworkConfig
+	// Members is the number of networks
+	Members int
+	// Sigma is the standard deviation of the gaussian noise added to the samples of each network
+	Sigma float64
+	// Epochs and BatchSize are the parameters of Train, zero epochs clusters with the noisy samples as the
+	// points
+	Epochs, BatchSize int
+	// Threshold is the co-association above which two samples are linked into the same consensus cluster
+	Threshold float64
+	// Options are the options of the networks, each network is seeded with the seed of the options plus
+	// its index
+	Options []Option
+}
+
+// Consensus is the result of fitting an ensemble
+type Consensus struct {
+	// Networks are the trained members of the ensemble
+	Networks []*Network
+	// Assignments are the clusters of the samples assigned by each network
+	Assignments [][]int
+	// CoAssociation is the fraction of the networks that assign each pair of samples to the same cluster
+	CoAssociation [][]float64
+	// Clusters is the consensus cluster of each sample, numbered in order of first appearance
+	Clusters []int
+	// Agreement is the mean co-association of each sample with the other samples of its consensus cluster,
+	// 1 for a sample that is alone in its cluster
+	Agreement []float64
+}
+
+// NewEnsemble creates an ensemble of members networks with the configuration, a threshold of .5, and no
+// noise or training
+func NewEnsemble(members int, config NetworkConfig, options ...Option) *Ensemble {
+	return &Ensemble{
+		Config:    config,
+		Members:   members,
+		BatchSize: 1,
+		Threshold: .5,
+		Options:   options,
+	}
+}
+
+// Fit trains the networks of the ensemble concurrently and extracts the consensus clustering of the
+// samples. Each network has a point for each sample, which is initialized to the noisy copy of the sample
+// the network is trained on, and assigns each sample to the point with the most attention.
+func (e *Ensemble) Fit(samples [][]float64) *Consensus {
+	c := Consensus{
+		Networks:    make([]*Network, e.Members),
+		Assignments: make([][]int, e.Members),
+	}
+	if len(samples) == 0 || e.Members < 1 {
+		return &c
+	}
+	width := len(samples[0])
+
+	var wait sync.WaitGroup
+	for m := 0; m < e.Members; m++ {
+		wait.Add(1)
+		go func(m int) {
+			defer wait.Done()
+			n := NewNetworkWithConfig(width, len(samples), e.Config, e.Options...)
+			n.SetSeed(n.RandState().Seed + int64(m))
+			noisy := make([][]float64, len(samples))
+			for i, sample := range samples {
+				noisy[i] = make([]float64, len(sample))
+				for j, measure := range sample {
+					noisy[i][j] = measure + n.Rnd.NormFloat64()*e.Sigma
+					n.Point.X[width*i+j] = float32(noisy[i][j])
+				}
+			}
+			if e.Epochs > 0 {
+				n.Train(noisy, e.Epochs, e.BatchSize)
+			}
+			assignment := make([]int, len(samples))
+			for i, sample := range samples {
+				assignment[i] = n.Cluster(sample)
+			}
+			c.Networks[m], c.Assignments[m] = n, assignment
+		}(m)
+	}
+	wait.Wait()
+
+	c.CoAssociation = make([][]float64, len(samples))
+	for i := range c.CoAssociation {
+		c.CoAssociation[i] = make([]float64, len(samples))
+	}
+	for _, assignment := range c.Assignments {
+		for i, a := range assignment {
+			for j, b := range assignment {
+				if a == b {
+					c.CoAssociation[i][j]++
+				}
+			}
+		}
+	}
+	for _, row := range c.CoAssociation {
+		for j := range row {
+			row[j] /= float64(e.Members)
+		}
+	}
+
+	// The consensus clusters are the connected components of the samples linked by a co-association
+	// above the threshold
+	c.Clusters = make([]int, len(samples))
+	for i := range c.Clusters {
+		c.Clusters[i] = -1
+	}
+	clusters := 0
+	for i := range samples {
+		if c.Clusters[i] >= 0 {
+			continue
+		}
+		c.Clusters[i] = clusters
+		stack := []int{i}
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for k, association := range c.CoAssociation[j] {
+				if c.Clusters[k] < 0 && association > e.Threshold {
+					c.Clusters[k] = clusters
+					stack = append(stack, k)
+				}
+			}
+		}
+		clusters++
+	}
+
+	c.Agreement = make([]float64, len(samples))
+	for i, cluster := range c.Clusters {
+		sum, count := 0.0, 0
+		for j, other := range c.Clusters {
+			if j != i && other == cluster {
+				sum += c.CoAssociation[i][j]
+				count++
+			}
+		}
+		if count == 0 {
+			c.Agreement[i] = 1
+			continue
+		}
+		c.Agreement[i] = sum / float64(count)
+	}
+	return &c
+}
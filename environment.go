@@ -0,0 +1,33 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// Environment is an external system, such as a simulator, that the recurrent network interacts with
+type Environment interface {
+	// Observe returns the current observation, which is mixed into the state
+	Observe() []float64
+	// Act applies the new state of the network as an action and returns true if the run is done
+	Act(action []float64) bool
+}
+
+// Run runs the closed loop between the recurrent network and the environment for at most steps steps.
+// Each step the observation is mixed into the state, the network is trained on the state, and the
+// new state is the action. The number of steps taken is returned.
+func (r *RNN) Run(environment Environment, steps int) int {
+	for i := 0; i < steps; i++ {
+		total := r.Step(environment.Observe()...)
+		if math.IsNaN(float64(total)) {
+			return i
+		}
+		if environment.Act(r.State) {
+			return i + 1
+		}
+	}
+	return steps
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Evaluation is the quality of a network on a data set, computed without training
+type Evaluation struct {
+	// Samples is the number of samples
+	Samples int
+	// Entropy is the mean entropy of the samples
+	Entropy float64
+	// Clusters is the number of points with at least one sample assigned to them
+	Clusters int
+	// Purity is the fraction of samples with the majority label of their cluster
+	Purity float64
+	// NMI is the normalized mutual information between the clusters and the labels
+	NMI float64
+	// Accuracy is the leave one out nearest neighbor accuracy of the labels in attention space
+	Accuracy float64
+	// MDL is the minimum description length score
+	MDL MDL
+}
+
+// String returns the evaluation as a string
+func (e Evaluation) String() string {
+	return fmt.Sprintf("samples %d entropy %f clusters %d purity %f nmi %f accuracy %f mdl %f",
+		e.Samples, e.Entropy, e.Clusters, e.Purity, e.NMI, e.Accuracy, e.MDL.Total)
+}
+
+// Evaluate evaluates the network on the labeled inputs without updating the weights
+func (n *Network) Evaluate(inputs []Sample) Evaluation {
+	evaluation := Evaluation{
+		Samples: len(inputs),
+	}
+	if len(inputs) == 0 {
+		return evaluation
+	}
+
+	samples := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		samples[i] = input.Measures
+	}
+	for _, e := range n.GetEntropyParallel(inputs, 0) {
+		evaluation.Entropy += float64(e.Entropy)
+	}
+	evaluation.Entropy /= float64(len(inputs))
+	evaluation.MDL = n.MDL(samples)
+
+	// The contingency table of the clusters and the labels
+	attention := make([][]float32, len(inputs))
+	table := make(map[int]map[string]int)
+	labels := make(map[string]int)
+	for i, input := range inputs {
+		attention[i] = n.Attention(input.Measures)
+		cluster, max := 0, float32(-1)
+		for j, a := range attention[i] {
+			if a > max {
+				cluster, max = j, a
+			}
+		}
+		if table[cluster] == nil {
+			table[cluster] = make(map[string]int)
+		}
+		table[cluster][input.Label]++
+		labels[input.Label]++
+	}
+	evaluation.Clusters = len(table)
+
+	total := float64(len(inputs))
+	majority, information, clusterEntropy, labelEntropy := 0, 0.0, 0.0, 0.0
+	for _, counts := range table {
+		size, max := 0, 0
+		for _, count := range counts {
+			size += count
+			if count > max {
+				max = count
+			}
+		}
+		majority += max
+		p := float64(size) / total
+		clusterEntropy -= p * math.Log(p)
+		for label, count := range counts {
+			joint := float64(count) / total
+			information += joint * math.Log(joint/(p*float64(labels[label])/total))
+		}
+	}
+	for _, count := range labels {
+		p := float64(count) / total
+		labelEntropy -= p * math.Log(p)
+	}
+	evaluation.Purity = float64(majority) / total
+	if clusterEntropy+labelEntropy > 0 {
+		evaluation.NMI = 2 * information / (clusterEntropy + labelEntropy)
+	} else {
+		evaluation.NMI = 1
+	}
+
+	// The nearest neighbor is the input with the most similar attention
+	norms := make([]float64, len(attention))
+	for i, a := range attention {
+		for _, value := range a {
+			norms[i] += float64(value) * float64(value)
+		}
+		norms[i] = math.Sqrt(norms[i])
+	}
+	same := 0
+	for i, a := range attention {
+		neighbor, max := -1, math.Inf(-1)
+		for j, b := range attention {
+			if i == j {
+				continue
+			}
+			dot := 0.0
+			for k, value := range a {
+				dot += float64(value) * float64(b[k])
+			}
+			if norms[i] > 0 && norms[j] > 0 {
+				dot /= norms[i] * norms[j]
+			}
+			if dot > max {
+				neighbor, max = j, dot
+			}
+		}
+		if neighbor >= 0 && inputs[neighbor].Label == inputs[i].Label {
+			same++
+		}
+	}
+	evaluation.Accuracy = float64(same) / total
+
+	return evaluation
+}
+
+// OpenNetwork creates a network from a checkpoint saved by SaveCheckpoint. The width, length, and number
+// of layers are read from the checkpoint and the rest of the configuration is taken from config.
+func OpenNetwork(path string, config NetworkConfig) (*Network, error) {
+	set := tf32.NewSet()
+	_, _, err := set.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	points := set.ByName["points"]
+	if points == nil {
+		return nil, errors.New("checkpoint doesn't have points")
+	}
+	config.Layers = 1
+	for set.ByName[fmt.Sprintf("points%d", config.Layers)] != nil {
+		config.Layers++
+	}
+	n := NewNetworkWithConfig(points.S[0], points.S[1], config)
+	err = n.LoadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
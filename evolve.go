@@ -0,0 +1,159 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Individual is a network configuration and its cost
+type Individual struct {
+	Config NetworkConfig
+	Cost   float64
+}
+
+// Evolution is an evolutionary search over network configurations
+type Evolution struct {
+	Rnd *rand.Rand
+	// Population is the number of individuals in each generation
+	Population int
+	// Generations is the number of generations
+	Generations int
+	// Mutation is the probability of mutating each parameter
+	Mutation float64
+	// Evaluate returns the cost of a configuration, lower is better
+	Evaluate func(config NetworkConfig) float64
+}
+
+// mutate mutates the parameters of a configuration
+func (e *Evolution) mutate(config NetworkConfig) NetworkConfig {
+	if e.Rnd.Float64() < e.Mutation {
+		config.Eta *= float32(math.Exp(e.Rnd.NormFloat64() * .5))
+	}
+	if e.Rnd.Float64() < e.Mutation {
+		config.Temperature *= float32(math.Exp(e.Rnd.NormFloat64() * .25))
+	}
+	if e.Rnd.Float64() < e.Mutation {
+		config.Beta *= float32(math.Exp(e.Rnd.NormFloat64() * .25))
+	}
+	if e.Rnd.Float64() < e.Mutation {
+		config.Layers += e.Rnd.Intn(3) - 1
+		if config.Layers < 1 {
+			config.Layers = 1
+		}
+	}
+	if e.Rnd.Float64() < e.Mutation {
+		config.Residual = !config.Residual
+	}
+	if e.Rnd.Float64() < e.Mutation {
+		if config.Activation == ActivationSoftmax {
+			config.Activation = ActivationSpherical
+		} else {
+			config.Activation = ActivationSoftmax
+		}
+	}
+	return config
+}
+
+// crossover picks each parameter from one of the two parents
+func (e *Evolution) crossover(a, b NetworkConfig) NetworkConfig {
+	child := a
+	if e.Rnd.Intn(2) == 0 {
+		child.Eta = b.Eta
+	}
+	if e.Rnd.Intn(2) == 0 {
+		child.Temperature = b.Temperature
+	}
+	if e.Rnd.Intn(2) == 0 {
+		child.Beta = b.Beta
+	}
+	if e.Rnd.Intn(2) == 0 {
+		child.Layers = b.Layers
+	}
+	if e.Rnd.Intn(2) == 0 {
+		child.Residual = b.Residual
+	}
+	if e.Rnd.Intn(2) == 0 {
+		child.Activation = b.Activation
+	}
+	return child
+}
+
+// evaluate evaluates an individual, treating a NaN cost as infinitely bad
+func (e *Evolution) evaluate(config NetworkConfig) Individual {
+	cost := e.Evaluate(config)
+	if math.IsNaN(cost) {
+		cost = math.Inf(1)
+	}
+	return Individual{
+		Config: config,
+		Cost:   cost,
+	}
+}
+
+// Search evolves a population of configurations starting from seed and returns the final population
+// sorted from best to worst
+func (e *Evolution) Search(seed NetworkConfig) []Individual {
+	population := make([]Individual, 0, e.Population)
+	population = append(population, e.evaluate(seed))
+	for len(population) < e.Population {
+		population = append(population, e.evaluate(e.mutate(seed)))
+	}
+	sort.Slice(population, func(i, j int) bool {
+		return population[i].Cost < population[j].Cost
+	})
+
+	for g := 0; g < e.Generations; g++ {
+		// The best half survives and breeds the other half
+		elite := (len(population) + 1) / 2
+		next := make([]Individual, 0, e.Population)
+		next = append(next, population[:elite]...)
+		for len(next) < e.Population {
+			a, b := population[e.Rnd.Intn(elite)], population[e.Rnd.Intn(elite)]
+			next = append(next, e.evaluate(e.mutate(e.crossover(a.Config, b.Config))))
+		}
+		population = next
+		sort.Slice(population, func(i, j int) bool {
+			return population[i].Cost < population[j].Cost
+		})
+	}
+	return population
+}
+
+// initialize sets the points of each layer to random samples
+func (n *Network) initialize(samples [][]float64) {
+	for _, layer := range n.Layers {
+		for i := 0; i < n.Length; i++ {
+			sample := samples[n.Rnd.Intn(len(samples))]
+			for j, measure := range sample {
+				layer.X[i*n.Width+j] = float32(measure)
+			}
+		}
+	}
+}
+
+// EntropyCost returns an evaluation function that trains a network with the configuration on the samples
+// for steps iterations and returns the average entropy of the samples. The points are initialized with
+// random samples.
+func EntropyCost(samples [][]float64, length, steps int) func(config NetworkConfig) float64 {
+	return func(config NetworkConfig) float64 {
+		width := len(samples[0])
+		n := NewNetworkWithConfig(width, length, config)
+		n.initialize(samples)
+		for n.I <= steps {
+			total := n.Iterate(samples[n.Rnd.Intn(len(samples))])
+			if !finite(total) {
+				return math.NaN()
+			}
+		}
+		sum := 0.0
+		for _, sample := range samples {
+			sum += float64(n.entropy(sample))
+		}
+		return sum / float64(len(samples))
+	}
+}
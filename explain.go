@@ -0,0 +1,267 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Cluster returns the index of the point with the most attention for the input
+func (n *Network) Cluster(input []float64) int {
+	index, max := 0, float32(0.0)
+	for i, a := range n.Features(input, LayerL1) {
+		if a > max {
+			index, max = i, a
+		}
+	}
+	return index
+}
+
+// Condition is a threshold test on a feature
+type Condition struct {
+	Feature   int
+	Name      string
+	Threshold float64
+	// Greater is true if the feature must be greater than the threshold
+	Greater bool
+}
+
+// String returns the condition in human readable form
+func (c Condition) String() string {
+	name := c.Name
+	if name == "" {
+		name = fmt.Sprintf("x%d", c.Feature)
+	}
+	if c.Greater {
+		return fmt.Sprintf("%s > %g", name, c.Threshold)
+	}
+	return fmt.Sprintf("%s <= %g", name, c.Threshold)
+}
+
+// Rule is a conjunction of conditions that implies a cluster
+type Rule struct {
+	Conditions []Condition
+	Cluster    int
+	// Support is the number of samples matching the conditions
+	Support int
+	// Confidence is the fraction of the matching samples assigned to the cluster
+	Confidence float64
+}
+
+// String returns the rule in human readable form
+func (r Rule) String() string {
+	conditions := make([]string, len(r.Conditions))
+	for i, condition := range r.Conditions {
+		conditions[i] = condition.String()
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "true")
+	}
+	return fmt.Sprintf("%s -> cluster %d (support %d, confidence %.2f)",
+		strings.Join(conditions, " and "), r.Cluster, r.Support, r.Confidence)
+}
+
+// gini computes the gini impurity of the clusters of the samples
+func gini(clusters []int, indexes []int) float64 {
+	counts := make(map[int]int)
+	for _, index := range indexes {
+		counts[clusters[index]]++
+	}
+	impurity := 1.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(indexes))
+		impurity -= p * p
+	}
+	return impurity
+}
+
+// majority returns the most common cluster of the samples and its count
+func majority(clusters []int, indexes []int) (int, int) {
+	counts := make(map[int]int)
+	for _, index := range indexes {
+		counts[clusters[index]]++
+	}
+	cluster, max := 0, 0
+	for c, count := range counts {
+		if count > max || (count == max && c < cluster) {
+			cluster, max = c, count
+		}
+	}
+	return cluster, max
+}
+
+// grow recursively splits the samples on the feature threshold with the lowest gini impurity
+func grow(samples [][]float64, clusters []int, indexes []int, names []string,
+	conditions []Condition, depth int, rules []Rule) []Rule {
+	cluster, count := majority(clusters, indexes)
+	impurity := gini(clusters, indexes)
+	leaf := func() []Rule {
+		return append(rules, Rule{
+			Conditions: append([]Condition{}, conditions...),
+			Cluster:    cluster,
+			Support:    len(indexes),
+			Confidence: float64(count) / float64(len(indexes)),
+		})
+	}
+	if depth == 0 || impurity == 0 {
+		return leaf()
+	}
+
+	feature, threshold, best := -1, 0.0, impurity
+	sorted := make([]int, len(indexes))
+	for j := range samples[0] {
+		copy(sorted, indexes)
+		sort.Slice(sorted, func(a, b int) bool {
+			return samples[sorted[a]][j] < samples[sorted[b]][j]
+		})
+		for i := 1; i < len(sorted); i++ {
+			a, b := samples[sorted[i-1]][j], samples[sorted[i]][j]
+			if a == b {
+				continue
+			}
+			left, right := sorted[:i], sorted[i:]
+			weighted := (float64(len(left))*gini(clusters, left) +
+				float64(len(right))*gini(clusters, right)) / float64(len(sorted))
+			if weighted < best {
+				feature, threshold, best = j, (a+b)/2, weighted
+			}
+		}
+	}
+	if feature < 0 {
+		return leaf()
+	}
+
+	name := ""
+	if feature < len(names) {
+		name = names[feature]
+	}
+	left, right := make([]int, 0, len(indexes)), make([]int, 0, len(indexes))
+	for _, index := range indexes {
+		if samples[index][feature] > threshold {
+			right = append(right, index)
+		} else {
+			left = append(left, index)
+		}
+	}
+	condition := Condition{
+		Feature:   feature,
+		Name:      name,
+		Threshold: threshold,
+	}
+	rules = grow(samples, clusters, left, names, append(conditions, condition), depth-1, rules)
+	condition.Greater = true
+	return grow(samples, clusters, right, names, append(conditions, condition), depth-1, rules)
+}
+
+// Explain fits a decision tree of at most depth levels over the features of the samples that mimics the
+// cluster assignments of the network, and returns one rule per leaf. names are the optional names of
+// the features.
+func (n *Network) Explain(samples [][]float64, names []string, depth int) []Rule {
+	clusters := make([]int, len(samples))
+	indexes := make([]int, len(samples))
+	for i, sample := range samples {
+		clusters[i] = n.Cluster(sample)
+		indexes[i] = i
+	}
+	return grow(samples, clusters, indexes, names, nil, depth, nil)
+}
+
+// Prototype is an exemplar of a cluster
+type Prototype struct {
+	Cluster int
+	// Count is the number of samples assigned to the cluster
+	Count int
+	// Medoid is the index of the sample with the smallest total distance to the other samples of the cluster
+	Medoid int
+	// Centroid is the mean of the samples of the cluster
+	Centroid []float64
+	// Point is the learned point row of the cluster
+	Point []float32
+}
+
+// Prototypes returns the prototype of each cluster with at least one sample assigned to it
+func (n *Network) Prototypes(samples [][]float64) []Prototype {
+	members := make(map[int][]int)
+	for i, sample := range samples {
+		cluster := n.Cluster(sample)
+		members[cluster] = append(members[cluster], i)
+	}
+
+	prototypes := make([]Prototype, 0, len(members))
+	for cluster := 0; cluster < n.Length; cluster++ {
+		indexes, ok := members[cluster]
+		if !ok {
+			continue
+		}
+		width := len(samples[indexes[0]])
+		centroid := make([]float64, width)
+		for _, index := range indexes {
+			for j, measure := range samples[index] {
+				centroid[j] += measure
+			}
+		}
+		for j := range centroid {
+			centroid[j] /= float64(len(indexes))
+		}
+
+		medoid, min := indexes[0], math.MaxFloat64
+		for _, a := range indexes {
+			sum := 0.0
+			for _, b := range indexes {
+				distance := 0.0
+				for j := range samples[a] {
+					difference := samples[a][j] - samples[b][j]
+					distance += difference * difference
+				}
+				sum += math.Sqrt(distance)
+			}
+			if sum < min {
+				medoid, min = a, sum
+			}
+		}
+
+		point := make([]float32, n.Width)
+		copy(point, n.Point.X[cluster*n.Width:(cluster+1)*n.Width])
+		prototypes = append(prototypes, Prototype{
+			Cluster:  cluster,
+			Count:    len(indexes),
+			Medoid:   medoid,
+			Centroid: centroid,
+			Point:    point,
+		})
+	}
+	return prototypes
+}
+
+// Memberships returns the soft cluster membership probabilities of each sample. groups maps each point
+// to a group, such as the groups found by splitting the entropy, and the membership of a group is the
+// attention mass over its points. If groups is nil each point is its own group.
+func (n *Network) Memberships(samples [][]float64, groups []int) [][]float64 {
+	k := n.Length
+	if groups != nil {
+		k = 0
+		for _, group := range groups {
+			if group+1 > k {
+				k = group + 1
+			}
+		}
+	}
+	memberships := make([][]float64, len(samples))
+	for i, sample := range samples {
+		membership := make([]float64, k)
+		for j, a := range n.Features(sample, LayerL1) {
+			group := j
+			if groups != nil {
+				group = groups[j]
+			}
+			membership[group] += float64(a)
+		}
+		memberships[i] = membership
+	}
+	return memberships
+}
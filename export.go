@@ -0,0 +1,244 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ExportVersion is the version of the exported format
+const ExportVersion = 2
+
+// ExportMagic is the first bytes of the binary format
+const ExportMagic = "OCCM"
+
+// Format is a portable format a network is exported in
+type Format int
+
+const (
+	// FormatJSON is a json document
+	FormatJSON Format = iota
+	// FormatBinary is a little endian binary format
+	FormatBinary
+)
+
+// NormalizationKind is the normalization applied to the inputs of a network
+type NormalizationKind int
+
+const (
+	// NormalizeNone leaves the inputs unchanged
+	NormalizeNone NormalizationKind = iota
+	// NormalizeMaxAbs divides each input by its largest absolute value
+	NormalizeMaxAbs
+	// NormalizeUnit divides each input by its euclidean norm
+	NormalizeUnit
+	// NormalizeStandard subtracts Mean from each measure and divides it by Deviation, measures with a
+	// deviation of zero are zero
+	NormalizeStandard
+)
+
+// Normalization is the normalization applied to the inputs before they are given to the network. It is
+// exported with the network so the inputs can be prepared the same way elsewhere.
+type Normalization struct {
+	Kind      NormalizationKind
+	Mean      []float64
+	Deviation []float64
+}
+
+// Apply normalizes the measures of an input in place
+func (n Normalization) Apply(measures []float64) {
+	switch n.Kind {
+	case NormalizeMaxAbs, NormalizeUnit:
+		scale := 0.0
+		for _, measure := range measures {
+			if n.Kind == NormalizeUnit {
+				scale += measure * measure
+			} else if math.Abs(measure) > scale {
+				scale = math.Abs(measure)
+			}
+		}
+		if n.Kind == NormalizeUnit {
+			scale = math.Sqrt(scale)
+		}
+		if scale == 0 {
+			return
+		}
+		for i := range measures {
+			measures[i] /= scale
+		}
+	case NormalizeStandard:
+		for i, measure := range measures {
+			if i >= len(n.Mean) || n.Deviation[i] == 0 {
+				measures[i] = 0
+				continue
+			}
+			measures[i] = (measure - n.Mean[i]) / n.Deviation[i]
+		}
+	}
+}
+
+// exportedWeights is an exported weight matrix
+type exportedWeights struct {
+	Name   string    `json:"name"`
+	Shape  []int     `json:"shape"`
+	Values []float32 `json:"values"`
+}
+
+// exportedNormalization is the exported normalization of the inputs
+type exportedNormalization struct {
+	Kind      string    `json:"kind"`
+	Mean      []float64 `json:"mean,omitempty"`
+	Deviation []float64 `json:"deviation,omitempty"`
+}
+
+// exportedNetwork is the json format of an exported network
+type exportedNetwork struct {
+	Version       int                   `json:"version"`
+	Width         int                   `json:"width"`
+	Length        int                   `json:"length"`
+	Layers        int                   `json:"layers"`
+	Activation    string                `json:"activation"`
+	Kernel        string                `json:"kernel"`
+	Residual      bool                  `json:"residual"`
+	Bias          bool                  `json:"bias"`
+	Encoding      string                `json:"encoding"`
+	Positions     int                   `json:"positions"`
+	Epsilon       float32               `json:"epsilon"`
+	Gamma         float32               `json:"gamma"`
+	Temperature   float32               `json:"temperature"`
+	Normalization exportedNormalization `json:"normalization"`
+	Weights       []exportedWeights     `json:"weights"`
+}
+
+// Names of the enumerations in the exported formats
+var (
+	activationNames    = []string{"softmax", "spherical"}
+	kernelNames        = []string{"dot", "rbf"}
+	encodingNames      = []string{"none", "sinusoidal", "learned"}
+	normalizationNames = []string{"none", "maxabs", "unit", "standard"}
+)
+
+// enumName returns the name of the enumeration value i
+func enumName(names []string, i int) string {
+	if i < 0 || i >= len(names) {
+		return fmt.Sprintf("unknown(%d)", i)
+	}
+	return names[i]
+}
+
+// Export writes the weights of the network and everything needed to run inference in another language: the
+// dimensions, the softmax variant, the kernel, the stacking of the blocks, the temperature of the current
+// iteration, and the normalization of the inputs. The weights are row major with Shape[0] columns. With a
+// bias the weights biases, biases1, ... are added to the attention scores of the blocks before the softmax.
+//
+// FormatJSON is a json object with the fields version, width, length, layers, activation ("softmax" or
+// "spherical"), kernel ("dot" or "rbf"), residual, bias, encoding ("none", "sinusoidal", or "learned"),
+// positions, epsilon, gamma, temperature, normalization ({kind: "none", "maxabs", "unit", or "standard",
+// mean, deviation}), and weights ([{name, shape, values}]).
+//
+// FormatBinary is little endian with the same fields in the same order, strings are a uint32 length
+// followed by utf-8 bytes, and slices are a uint32 length followed by the elements:
+//
+//	magic       [4]byte "OCCM"
+//	version     uint32
+//	width       uint32
+//	length      uint32
+//	layers      uint32
+//	activation  uint32 0 softmax, 1 spherical
+//	kernel      uint32 0 dot, 1 rbf
+//	residual    uint32 0 or 1
+//	bias        uint32 0 or 1
+//	encoding    uint32 0 none, 1 sinusoidal, 2 learned
+//	positions   uint32
+//	epsilon     float32
+//	gamma       float32
+//	temperature float32
+//	kind        uint32 0 none, 1 maxabs, 2 unit, 3 standard
+//	mean        []float64
+//	deviation   []float64
+//	weights     uint32 count, then for each: name string, shape []uint32, values []float32
+func (n *Network) Export(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		exported := exportedNetwork{
+			Version:     ExportVersion,
+			Width:       n.Width,
+			Length:      n.Length,
+			Layers:      n.Config.Layers,
+			Activation:  enumName(activationNames, int(n.Config.Activation)),
+			Kernel:      enumName(kernelNames, int(n.Config.Kernel)),
+			Residual:    n.Config.Residual,
+			Bias:        n.Config.Bias,
+			Encoding:    enumName(encodingNames, int(n.Config.Encoding)),
+			Positions:   n.Config.Positions,
+			Epsilon:     n.Config.Epsilon,
+			Gamma:       n.Config.Gamma,
+			Temperature: n.temperature,
+			Normalization: exportedNormalization{
+				Kind:      enumName(normalizationNames, int(n.Normalization.Kind)),
+				Mean:      n.Normalization.Mean,
+				Deviation: n.Normalization.Deviation,
+			},
+		}
+		for _, weights := range n.Set.Weights {
+			exported.Weights = append(exported.Weights, exportedWeights{
+				Name:   weights.N,
+				Shape:  weights.S,
+				Values: weights.X,
+			})
+		}
+		return json.NewEncoder(w).Encode(&exported)
+	case FormatBinary:
+		return n.exportBinary(w)
+	}
+	return fmt.Errorf("unknown format %d", format)
+}
+
+// exportBinary writes the network in the binary format
+func (n *Network) exportBinary(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	var err error
+	write := func(values ...interface{}) {
+		for _, value := range values {
+			if err != nil {
+				return
+			}
+			err = binary.Write(writer, binary.LittleEndian, value)
+		}
+	}
+	flag := func(value bool) uint32 {
+		if value {
+			return 1
+		}
+		return 0
+	}
+	write([]byte(ExportMagic), uint32(ExportVersion),
+		uint32(n.Width), uint32(n.Length), uint32(n.Config.Layers),
+		uint32(n.Config.Activation), uint32(n.Config.Kernel), flag(n.Config.Residual), flag(n.Config.Bias),
+		uint32(n.Config.Encoding), uint32(n.Config.Positions),
+		n.Config.Epsilon, n.Config.Gamma, n.temperature,
+		uint32(n.Normalization.Kind),
+		uint32(len(n.Normalization.Mean)), n.Normalization.Mean,
+		uint32(len(n.Normalization.Deviation)), n.Normalization.Deviation,
+		uint32(len(n.Set.Weights)))
+	for _, weights := range n.Set.Weights {
+		shape := make([]uint32, len(weights.S))
+		for i, s := range weights.S {
+			shape[i] = uint32(s)
+		}
+		write(uint32(len(weights.N)), []byte(weights.N),
+			uint32(len(shape)), shape,
+			uint32(len(weights.X)), weights.X)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
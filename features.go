@@ -0,0 +1,66 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"sort"
+)
+
+// FeatureInformation computes the mutual information in bits between each input feature and the soft
+// cluster assignments given by the attention over the points. Each feature is discretized into bins
+// quantile bins.
+func (n *Network) FeatureInformation(samples [][]float64, bins int) []float64 {
+	attention := make([][]float32, len(samples))
+	for i, sample := range samples {
+		attention[i] = n.Features(sample, LayerL1)
+	}
+	marginal := make([]float64, n.Length)
+	for _, a := range attention {
+		for k, p := range a {
+			marginal[k] += float64(p) / float64(len(samples))
+		}
+	}
+
+	width := len(samples[0])
+	information := make([]float64, width)
+	for j := 0; j < width; j++ {
+		// Discretize the feature into quantile bins
+		values := make([]float64, len(samples))
+		for i, sample := range samples {
+			values[i] = sample[j]
+		}
+		sort.Float64s(values)
+		edges := make([]float64, 0, bins-1)
+		for b := 1; b < bins; b++ {
+			edges = append(edges, values[b*len(values)/bins])
+		}
+
+		joint := make([][]float64, bins)
+		for b := range joint {
+			joint[b] = make([]float64, n.Length)
+		}
+		counts := make([]float64, bins)
+		for i, sample := range samples {
+			b := sort.SearchFloat64s(edges, sample[j])
+			counts[b]++
+			for k, p := range attention[i] {
+				joint[b][k] += float64(p) / float64(len(samples))
+			}
+		}
+
+		sum := 0.0
+		for b := range joint {
+			pb := counts[b] / float64(len(samples))
+			for k, pbk := range joint[b] {
+				if pbk > 0 && marginal[k] > 0 {
+					sum += pbk * math.Log2(pbk/(pb*marginal[k]))
+				}
+			}
+		}
+		information[j] = sum
+	}
+	return information
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"fmt"
+)
+
+// average sets the weights of the network to the weighted average of the weights of the models
+func (n *Network) average(weights []float64, models []*Network) error {
+	sum := 0.0
+	for _, weight := range weights {
+		sum += weight
+	}
+	for _, w := range n.Set.Weights {
+		averaged := make([]float64, len(w.X))
+		for i, model := range models {
+			v := model.Set.ByName[w.N]
+			if v == nil {
+				return fmt.Errorf("model %d is missing %s", i, w.N)
+			}
+			if len(v.X) != len(w.X) {
+				return fmt.Errorf("size of %s of model %d is %d but should be %d", w.N, i, len(v.X), len(w.X))
+			}
+			for j, x := range v.X {
+				averaged[j] += weights[i] * float64(x) / sum
+			}
+		}
+		for j, x := range averaged {
+			w.X[j] = float32(x)
+		}
+	}
+	return nil
+}
+
+// Average returns a new network with the weights averaged over the models, which must all have the
+// same shape
+func Average(models ...*Network) (*Network, error) {
+	if len(models) == 0 {
+		return nil, errors.New("no models to average")
+	}
+	first := models[0]
+	n := NewNetworkWithConfig(first.Width, first.Length, first.Config)
+	weights := make([]float64, len(models))
+	for i := range weights {
+		weights[i] = 1
+	}
+	if err := n.average(weights, models); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Federate trains the global network with federated averaging over the silos of samples. Each round a
+// local copy of the global network is trained on each silo for steps iterations, and the global network
+// is set to the average of the local copies weighted by the number of samples in each silo. The raw
+// samples never leave their silo.
+func (n *Network) Federate(silos [][][]float64, rounds, steps int) error {
+	weights := make([]float64, len(silos))
+	for i, silo := range silos {
+		weights[i] = float64(len(silo))
+	}
+	for round := 0; round < rounds; round++ {
+		locals := make([]*Network, len(silos))
+		for i, silo := range silos {
+			local := NewNetworkWithConfig(n.Width, n.Length, n.Config)
+			local.SetSeed(n.RandState().Seed + int64(round*len(silos)+i))
+			if err := local.TransferFrom(n); err != nil {
+				return err
+			}
+			for local.I < steps {
+				total := local.Iterate(silo[local.Rnd.Intn(len(silo))])
+				if !finite(total) {
+					return fmt.Errorf("silo %d diverged in round %d", i, round)
+				}
+			}
+			locals[i] = local
+		}
+		if err := n.average(weights, locals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
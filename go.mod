@@ -1,6 +1,6 @@
 module github.com/pointlander/occam
 
-go 1.18
+go 1.21
 
 require (
 	github.com/go-echarts/go-echarts/v2 v2.2.4
@@ -10,6 +10,8 @@ require (
 	github.com/pointlander/pagerank v0.0.0-20210619221740-830548a59275
 	gonum.org/v1/gonum v0.12.0
 	gonum.org/v1/plot v0.12.0
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
@@ -19,9 +21,11 @@ require (
 	github.com/go-latex/latex v0.0.0-20210823091927-c0d11ff05a81 // indirect
 	github.com/go-pdf/fpdf v0.6.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/ziutek/blas v0.0.0-20190227122918-da4ca23e90bb // indirect
 	golang.org/x/image v0.0.0-20220902085622-e7cb96979f69 // indirect
-	golang.org/x/text v0.3.7 // indirect
-	google.golang.org/protobuf v1.24.0 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
 )
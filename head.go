@@ -0,0 +1,64 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math/cmplx"
+)
+
+// measures returns the measures of the samples
+func measures(inputs []Sample) [][]float64 {
+	rows := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		rows[i] = input.Measures
+	}
+	return rows
+}
+
+// FitHead trains a softmax classification head with classes outputs on the l2 output of the network for
+// the inputs and their labels with its own adam loop, the points of the network aren't updated. The head
+// replaces any previous head and the final cost is returned.
+func (n *Network) FitHead(inputs []Sample, labels []int, classes int) float32 {
+	n.Head = NewClassifier(n, LayerL2, classes)
+	return n.Head.Fit(measures(inputs), labels)
+}
+
+// PredictHead returns the class predicted by the head fit by FitHead and the class probabilities
+func (n *Network) PredictHead(sample []float64) (int, []float32) {
+	if n.Head == nil {
+		panic("the head has not been fit")
+	}
+	return n.Head.Predict(sample)
+}
+
+// FitHead trains a softmax classification head with classes outputs on the magnitudes of the l2 output
+// of the network like Network.FitHead
+func (n *ComplexNetwork) FitHead(inputs []Sample, labels []int, classes int) float32 {
+	n.Head = &Classifier{
+		Layer:   LayerL2,
+		Classes: classes,
+		Epochs:  8 * 1024,
+		Eta:     .1,
+		Width:   n.Width,
+		Features: func(input []float64) []float32 {
+			output := n.Features(Complex(input), LayerL2)
+			features := make([]float32, len(output))
+			for i, value := range output {
+				features[i] = float32(cmplx.Abs(value))
+			}
+			return features
+		},
+		Rnd: n.Rnd,
+	}
+	return n.Head.Fit(measures(inputs), labels)
+}
+
+// PredictHead returns the class predicted by the head fit by FitHead and the class probabilities
+func (n *ComplexNetwork) PredictHead(sample []float64) (int, []float32) {
+	if n.Head == nil {
+		panic("the head has not been fit")
+	}
+	return n.Head.Predict(sample)
+}
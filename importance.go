@@ -0,0 +1,89 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ImportanceSampler samples the training samples in proportion to their current score and returns
+// the importance weight that keeps the expected gradient equal to that of uniform sampling.
+// The scores are recomputed every Interval steps.
+type ImportanceSampler struct {
+	Rnd      *rand.Rand
+	Network  *Network
+	Samples  [][]float64
+	Interval int
+	// Smoothing is the fraction of uniform sampling mixed in, which bounds the importance weights
+	Smoothing float64
+	// Score returns the score of a sample, the entropy of the sample if nil
+	Score         func(sample []float64) float64
+	probabilities []float64
+	cumulative    []float64
+	last          int
+}
+
+// NewImportanceSampler creates a new importance sampler that samples in proportion to the entropy
+func NewImportanceSampler(rnd *rand.Rand, n *Network, samples [][]float64, interval int) *ImportanceSampler {
+	return &ImportanceSampler{
+		Rnd:       rnd,
+		Network:   n,
+		Samples:   samples,
+		Interval:  interval,
+		Smoothing: .1,
+	}
+}
+
+// Next returns the index of the training sample for the step and its importance weight
+func (s *ImportanceSampler) Next(step int) (int, float32) {
+	length := float64(len(s.Samples))
+	if s.cumulative == nil || step-s.last >= s.Interval {
+		scores, sum := make([]float64, len(s.Samples)), 0.0
+		for i, sample := range s.Samples {
+			score := 0.0
+			if s.Score != nil {
+				score = s.Score(sample)
+			} else {
+				score = float64(s.Network.entropy(sample))
+			}
+			if math.IsNaN(score) || score < 0 {
+				score = 0
+			}
+			scores[i] = score
+			sum += score
+		}
+		s.probabilities = make([]float64, len(s.Samples))
+		s.cumulative = make([]float64, len(s.Samples))
+		total := 0.0
+		for i, score := range scores {
+			p := 1 / length
+			if sum > 0 {
+				p = s.Smoothing/length + (1-s.Smoothing)*score/sum
+			}
+			s.probabilities[i] = p
+			total += p
+			s.cumulative[i] = total
+		}
+		s.last = step
+	}
+	index := sort.SearchFloat64s(s.cumulative, s.Rnd.Float64()*s.cumulative[len(s.cumulative)-1])
+	if index >= len(s.Samples) {
+		index = len(s.Samples) - 1
+	}
+	return index, float32(1 / (length * s.probabilities[index]))
+}
+
+// TrainImportance trains the network until steps using the importance sampler to select and weight the samples
+func (n *Network) TrainImportance(steps int, sampler *ImportanceSampler) {
+	for n.I < steps {
+		index, weight := sampler.Next(n.I)
+		total := n.IterateWithOptions(sampler.Samples[index], n.Config.rate(n.I), weight)
+		if !finite(total) {
+			break
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"sort"
+)
+
+// Index is a kd-tree over the points of a block for finding the points with the most attention without
+// scoring every point. The rbf kernel ranks the points by euclidean distance. The dot kernel ranks them by
+// inner product, which is reduced to euclidean distance by adding a dimension to the points that makes
+// their norms equal.
+// https://arxiv.org/abs/1405.5869
+type Index struct {
+	Width  int
+	Kernel Kernel
+	// points are the points with the added dimension for the dot kernel
+	points [][]float32
+	max    float32
+	root   *kdNode
+}
+
+// kdNode is a node of the kd-tree, which splits its points on an axis at the point of the node
+type kdNode struct {
+	Point       int
+	Axis        int
+	Left, Right *kdNode
+}
+
+// NewIndex creates an index over the points, which have width columns
+func NewIndex(points []float32, width int, kernel Kernel) *Index {
+	index := Index{
+		Width:  width,
+		Kernel: kernel,
+	}
+	dimensions := width
+	if kernel == KernelDot {
+		dimensions++
+	}
+	for i := 0; i < len(points); i += width {
+		point := make([]float32, dimensions)
+		copy(point, points[i:i+width])
+		index.points = append(index.points, point)
+		if kernel == KernelDot {
+			norm := float32(0)
+			for _, value := range point[:width] {
+				norm += value * value
+			}
+			if norm > index.max {
+				index.max = norm
+			}
+		}
+	}
+	if kernel == KernelDot {
+		for _, point := range index.points {
+			norm := float32(0)
+			for _, value := range point[:width] {
+				norm += value * value
+			}
+			point[width] = float32(math.Sqrt(float64(index.max - norm)))
+		}
+	}
+	rows := make([]int, len(index.points))
+	for i := range rows {
+		rows[i] = i
+	}
+	index.root = index.build(rows, 0)
+	return &index
+}
+
+// build builds the kd-tree for the rows splitting on the axis for the depth
+func (i *Index) build(rows []int, depth int) *kdNode {
+	if len(rows) == 0 {
+		return nil
+	}
+	axis := depth % len(i.points[rows[0]])
+	sort.Slice(rows, func(a, b int) bool {
+		return i.points[rows[a]][axis] < i.points[rows[b]][axis]
+	})
+	median := len(rows) / 2
+	return &kdNode{
+		Point: rows[median],
+		Axis:  axis,
+		Left:  i.build(rows[:median], depth+1),
+		Right: i.build(rows[median+1:], depth+1),
+	}
+}
+
+// candidate is a point found by a search and its squared distance to the query
+type candidate struct {
+	Point    int
+	Distance float32
+}
+
+// candidates is a max heap of the closest points found by a search
+type candidates []candidate
+
+func (c candidates) Len() int            { return len(c) }
+func (c candidates) Less(i, j int) bool  { return c[i].Distance > c[j].Distance }
+func (c candidates) Swap(i, j int)       { c[i], c[j] = c[j], c[i] }
+func (c *candidates) Push(x interface{}) { *c = append(*c, x.(candidate)) }
+func (c *candidates) Pop() interface{} {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
+
+// Search returns the rows of the k points with the highest score for the query in order of decreasing
+// score, or nil if k isn't positive or the index is empty. k is capped at the number of points.
+func (i *Index) Search(query []float32, k int) []int {
+	if k <= 0 || len(i.points) == 0 {
+		return nil
+	}
+	if k > len(i.points) {
+		k = len(i.points)
+	}
+	q := make([]float32, len(i.points[0]))
+	copy(q, query)
+	found := make(candidates, 0, k+1)
+	var search func(node *kdNode)
+	search = func(node *kdNode) {
+		if node == nil {
+			return
+		}
+		point, distance := i.points[node.Point], float32(0)
+		for j, value := range point {
+			difference := value - q[j]
+			distance += difference * difference
+		}
+		if len(found) < k {
+			heap.Push(&found, candidate{Point: node.Point, Distance: distance})
+		} else if distance < found[0].Distance {
+			found[0] = candidate{Point: node.Point, Distance: distance}
+			heap.Fix(&found, 0)
+		}
+		split := q[node.Axis] - point[node.Axis]
+		near, far := node.Left, node.Right
+		if split > 0 {
+			near, far = far, near
+		}
+		search(near)
+		if len(found) < k || split*split < found[0].Distance {
+			search(far)
+		}
+	}
+	search(i.root)
+	sort.Slice(found, func(a, b int) bool {
+		return found[a].Distance < found[b].Distance
+	})
+	rows := make([]int, len(found))
+	for j, c := range found {
+		rows[j] = c.Point
+	}
+	return rows
+}
+
+// indexable returns an error if the attention of the configuration can't be ranked by an index
+func (c NetworkConfig) indexable(layers int) error {
+	if layers != 1 || c.Activation != ActivationSoftmax || c.Softmax != nil || c.Encoding != EncodingNone || c.Bias {
+		return errors.New("an index needs a single block with the softmax activation, no positional encoding, and no bias")
+	}
+	return nil
+}
+
+// neighbors returns the neighbors found by the index for the input with the attention normalized over the
+// neighbors
+func (i *Index) neighbors(points, input []float32, k int, gamma, temperature float32) []Neighbor {
+	if gamma == 0 {
+		gamma = 1
+	}
+	if temperature == 0 {
+		temperature = 1
+	}
+	rows := i.Search(input, k)
+	neighbors, scores := make([]Neighbor, len(rows)), make([]float32, len(rows))
+	for j, row := range rows {
+		sum := float32(0)
+		for l, value := range points[row*i.Width : (row+1)*i.Width] {
+			if i.Kernel == KernelRBF {
+				difference := value - input[l]
+				sum += difference * difference
+			} else {
+				sum += value * input[l]
+			}
+		}
+		if i.Kernel == KernelRBF {
+			sum *= -gamma
+		}
+		scores[j] = sum
+	}
+	total := float32(0)
+	for j, row := range rows {
+		neighbors[j].Index = row
+		neighbors[j].Attention = float32(math.Exp(float64((scores[j] - scores[0]) / temperature)))
+		total += neighbors[j].Attention
+	}
+	for j := range neighbors {
+		neighbors[j].Attention /= total
+	}
+	return neighbors
+}
+
+// BuildIndex builds an index over the points of the network that Nearest uses instead of scoring every
+// point. The index has to be rebuilt after the points are trained. Only networks with a single block and
+// the softmax activation can be indexed.
+func (n *Network) BuildIndex() error {
+	err := n.Config.indexable(len(n.Layers))
+	if err != nil {
+		return err
+	}
+	n.Index = NewIndex(n.Point.X, n.Width, n.Config.Kernel)
+	return nil
+}
+
+// Nearest returns the k points with the most attention for the sample in order of decreasing attention.
+// If the network has an index only the k points found by the index are scored, and their attention is
+// normalized over them, which approximates the attention when the other points get little of it.
+func (n *Network) Nearest(sample []float64, k int) []Neighbor {
+	if n.Index != nil {
+		input := make([]float32, n.Width)
+		for i, measure := range sample {
+			input[i] = float32(measure)
+		}
+		return n.Index.neighbors(n.Point.X, input, k, n.Config.Gamma, n.temperature)
+	}
+	return nearest(n.Attention(sample), k)
+}
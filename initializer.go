@@ -0,0 +1,135 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Initializer sets the initial values of a weight matrix, such as the points of a layer, which has width
+// columns and length rows
+type Initializer interface {
+	Initialize(rnd *rand.Rand, weights []float32, width, length int)
+}
+
+// Uniform initializes the weights uniformly between Min and Max, -1 and 1 if both are zero
+type Uniform struct {
+	Min, Max float32
+}
+
+// Initialize initializes the weights
+func (u Uniform) Initialize(rnd *rand.Rand, weights []float32, width, length int) {
+	min, max := float64(u.Min), float64(u.Max)
+	if min == 0 && max == 0 {
+		min, max = -1, 1
+	}
+	for i := range weights {
+		weights[i] = float32(min + (max-min)*rnd.Float64())
+	}
+}
+
+// Xavier initializes the weights uniformly with the variance 2/(width+length) of glorot and bengio
+// https://proceedings.mlr.press/v9/glorot10a.html
+type Xavier struct{}
+
+// Initialize initializes the weights
+func (Xavier) Initialize(rnd *rand.Rand, weights []float32, width, length int) {
+	limit := float32(math.Sqrt(6 / float64(width+length)))
+	Uniform{Min: -limit, Max: limit}.Initialize(rnd, weights, width, length)
+}
+
+// He initializes the weights with a normal distribution with the variance 2/width of he et al.
+// https://arxiv.org/abs/1502.01852
+type He struct{}
+
+// Initialize initializes the weights
+func (He) Initialize(rnd *rand.Rand, weights []float32, width, length int) {
+	factor := math.Sqrt(2.0 / float64(width))
+	for i := range weights {
+		weights[i] = float32(rnd.NormFloat64() * factor)
+	}
+}
+
+// Data initializes row i of the weights to sample i, cycling through the samples if there are fewer
+// samples than rows
+type Data struct {
+	Samples [][]float64
+}
+
+// Initialize initializes the weights
+func (d Data) Initialize(rnd *rand.Rand, weights []float32, width, length int) {
+	if len(d.Samples) == 0 {
+		return
+	}
+	for i := 0; i < length; i++ {
+		for j, measure := range d.Samples[i%len(d.Samples)] {
+			weights[i*width+j] = float32(measure)
+		}
+	}
+}
+
+// KMeansPlusPlus initializes the rows of the weights to samples selected with the k-means++ seeding, so
+// each sample is selected with a probability proportional to its squared distance from the closest row
+// already selected
+// https://dl.acm.org/doi/10.5555/1283383.1283494
+type KMeansPlusPlus struct {
+	Samples [][]float64
+}
+
+// Initialize initializes the weights
+func (k KMeansPlusPlus) Initialize(rnd *rand.Rand, weights []float32, width, length int) {
+	samples := k.Samples
+	if len(samples) == 0 {
+		return
+	}
+	// distances are the squared distances of the samples from the closest selected sample
+	distances := make([]float64, len(samples))
+	for i := range distances {
+		distances[i] = math.Inf(1)
+	}
+	selected := samples[rnd.Intn(len(samples))]
+	for i := 0; i < length; i++ {
+		for j, measure := range selected {
+			weights[i*width+j] = float32(measure)
+		}
+		if i == length-1 {
+			break
+		}
+		sum := 0.0
+		for s, sample := range samples {
+			distance := 0.0
+			for j, measure := range sample {
+				difference := measure - selected[j]
+				distance += difference * difference
+			}
+			if distance < distances[s] {
+				distances[s] = distance
+			}
+			sum += distances[s]
+		}
+		// All of the samples have been selected if the distances are zero, so the sample is uniform
+		if sum == 0 {
+			selected = samples[rnd.Intn(len(samples))]
+			continue
+		}
+		target, index := sum*rnd.Float64(), len(samples)-1
+		for s, distance := range distances {
+			target -= distance
+			if target < 0 {
+				index = s
+				break
+			}
+		}
+		selected = samples[index]
+	}
+}
+
+// Initialize initializes the points of each layer of the network with the initializer
+func (n *Network) Initialize(initializer Initializer) {
+	for _, layer := range n.Layers {
+		initializer.Initialize(n.Rnd, layer.X, n.Width, n.Length)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+// Landscape is the entropy surface of an input perturbed along two features
+type Landscape struct {
+	// FeatureX and FeatureY are the perturbed features
+	FeatureX, FeatureY int
+	// Xs and Ys are the values of the perturbed features
+	Xs, Ys []float64
+	// Entropy is the entropy at each row (y) and column (x)
+	Entropy [][]float64
+}
+
+// Dims returns the dimensions of the grid
+func (l *Landscape) Dims() (c, r int) {
+	return len(l.Xs), len(l.Ys)
+}
+
+// Z returns the entropy at column c and row r
+func (l *Landscape) Z(c, r int) float64 {
+	return l.Entropy[r][c]
+}
+
+// X returns the value of feature x at column c
+func (l *Landscape) X(c int) float64 {
+	return l.Xs[c]
+}
+
+// Y returns the value of feature y at row r
+func (l *Landscape) Y(r int) float64 {
+	return l.Ys[r]
+}
+
+// EntropyLandscape sweeps features x and y of the input over a grid of steps values each within radius
+// of the input, and returns the entropy at each point of the grid
+func (n *Network) EntropyLandscape(input []float64, x, y int, radius float64, steps int) *Landscape {
+	landscape := &Landscape{
+		FeatureX: x,
+		FeatureY: y,
+		Xs:       make([]float64, steps),
+		Ys:       make([]float64, steps),
+		Entropy:  make([][]float64, steps),
+	}
+	for i := 0; i < steps; i++ {
+		offset := -radius
+		if steps > 1 {
+			offset += 2 * radius * float64(i) / float64(steps-1)
+		}
+		landscape.Xs[i] = input[x] + offset
+		landscape.Ys[i] = input[y] + offset
+	}
+
+	sample := make([]float64, len(input))
+	copy(sample, input)
+	for r, vy := range landscape.Ys {
+		landscape.Entropy[r] = make([]float64, steps)
+		for c, vx := range landscape.Xs {
+			sample[x], sample[y] = vx, vy
+			landscape.Entropy[r][c] = float64(n.entropy(sample))
+		}
+	}
+	return landscape
+}
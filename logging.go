@@ -0,0 +1,78 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// NewLogger creates a structured logger that writes text or json records at or above level to w.
+// A random run id is attached to every record so the output of concurrent runs can be separated.
+func NewLogger(w io.Writer, json bool, level slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level: level,
+	}
+	var handler slog.Handler = slog.NewTextHandler(w, options)
+	if json {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(handler).With("run", fmt.Sprintf("%016x", rand.Uint64()))
+}
+
+// discard is a handler that discards all records
+type discard struct{}
+
+// Enabled returns false so no records are created
+func (discard) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle discards the record
+func (discard) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs returns the handler
+func (d discard) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+// WithGroup returns the handler
+func (d discard) WithGroup(string) slog.Handler { return d }
+
+// NewNopLogger creates a logger that discards all records, which is the default logger of a network
+func NewNopLogger() *slog.Logger {
+	return slog.New(discard{})
+}
+
+// StepFunc is called after each training step with the iteration, the cost, and the duration of the step
+type StepFunc func(step int, cost float32, duration time.Duration)
+
+// LogSteps returns a step function that logs each step to the logger at the info level
+func LogSteps(logger *slog.Logger) StepFunc {
+	return func(step int, cost float32, duration time.Duration) {
+		if logger.Enabled(context.Background(), slog.LevelInfo) {
+			logger.Info("step", "step", step, "cost", cost, "duration", duration)
+		}
+	}
+}
+
+// Steps returns a step function that calls each of the step functions in order
+func Steps(steps ...StepFunc) StepFunc {
+	return func(step int, cost float32, duration time.Duration) {
+		for _, s := range steps {
+			s(step, cost, duration)
+		}
+	}
+}
+
+// History returns the step function that records the cost of each step in Points for plotting,
+// unless DisableHistory is set. It is the default step function of a network.
+func (n *Network) History() StepFunc {
+	return func(step int, cost float32, duration time.Duration) {
+		if !n.DisableHistory {
+			n.Points = append(n.Points, XY{X: float64(step), Y: float64(cost)})
+		}
+	}
+}
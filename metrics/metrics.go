@@ -0,0 +1,155 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metrics computes standard quality metrics of cluster assignments
+package metrics
+
+import (
+	"math"
+)
+
+// distance is the euclidean distance between a and b
+func distance(a, b []float64) float64 {
+	sum := 0.0
+	for i, value := range a {
+		difference := value - b[i]
+		sum += difference * difference
+	}
+	return math.Sqrt(sum)
+}
+
+// Silhouette is the mean silhouette score of the samples assigned to clusters, which is between -1 and 1
+// with higher being better. Samples in clusters of their own score zero, and the score is zero if there
+// are less than two clusters.
+// https://doi.org/10.1016/0377-0427(87)90125-7
+func Silhouette(samples [][]float64, clusters []int) float64 {
+	counts := make(map[int]int)
+	for _, cluster := range clusters {
+		counts[cluster]++
+	}
+	if len(counts) < 2 {
+		return 0
+	}
+	total := 0.0
+	for i, a := range samples {
+		if counts[clusters[i]] == 1 {
+			continue
+		}
+		sums := make(map[int]float64)
+		for j, b := range samples {
+			if i != j {
+				sums[clusters[j]] += distance(a, b)
+			}
+		}
+		inner := sums[clusters[i]] / float64(counts[clusters[i]]-1)
+		outer := math.Inf(1)
+		for cluster, sum := range sums {
+			if cluster == clusters[i] {
+				continue
+			}
+			if mean := sum / float64(counts[cluster]); mean < outer {
+				outer = mean
+			}
+		}
+		if max := math.Max(inner, outer); max > 0 {
+			total += (outer - inner) / max
+		}
+	}
+	return total / float64(len(samples))
+}
+
+// DaviesBouldin is the Davies-Bouldin index of the samples assigned to clusters, which is the mean
+// similarity of each cluster to its most similar cluster, lower is better. The index is zero if there
+// are less than two clusters.
+// https://doi.org/10.1109/TPAMI.1979.4766909
+func DaviesBouldin(samples [][]float64, clusters []int) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	width := len(samples[0])
+	centroids, counts := make(map[int][]float64), make(map[int]int)
+	for i, sample := range samples {
+		centroid := centroids[clusters[i]]
+		if centroid == nil {
+			centroid = make([]float64, width)
+			centroids[clusters[i]] = centroid
+		}
+		for j, value := range sample {
+			centroid[j] += value
+		}
+		counts[clusters[i]]++
+	}
+	if len(centroids) < 2 {
+		return 0
+	}
+	for cluster, centroid := range centroids {
+		for j := range centroid {
+			centroid[j] /= float64(counts[cluster])
+		}
+	}
+	scatter := make(map[int]float64)
+	for i, sample := range samples {
+		scatter[clusters[i]] += distance(sample, centroids[clusters[i]])
+	}
+	for cluster := range scatter {
+		scatter[cluster] /= float64(counts[cluster])
+	}
+	total := 0.0
+	for a, centroid := range centroids {
+		max := 0.0
+		for b, other := range centroids {
+			if a == b {
+				continue
+			}
+			d := distance(centroid, other)
+			if d == 0 {
+				max = math.Inf(1)
+				continue
+			}
+			if similarity := (scatter[a] + scatter[b]) / d; similarity > max {
+				max = similarity
+			}
+		}
+		total += max
+	}
+	return total / float64(len(centroids))
+}
+
+// AdjustedRand is the adjusted Rand index of the clusters against the ground truth labels, which is 1
+// for identical partitions and close to 0 for random partitions
+// https://doi.org/10.1007/BF01908075
+func AdjustedRand(labels []string, clusters []int) float64 {
+	if len(labels) < 2 {
+		return 1
+	}
+	type pair struct {
+		Label   string
+		Cluster int
+	}
+	choose := func(n int) float64 {
+		return float64(n) * float64(n-1) / 2
+	}
+	table, rows, columns := make(map[pair]int), make(map[string]int), make(map[int]int)
+	for i, label := range labels {
+		table[pair{Label: label, Cluster: clusters[i]}]++
+		rows[label]++
+		columns[clusters[i]]++
+	}
+	index, a, b := 0.0, 0.0, 0.0
+	for _, count := range table {
+		index += choose(count)
+	}
+	for _, count := range rows {
+		a += choose(count)
+	}
+	for _, count := range columns {
+		b += choose(count)
+	}
+	expected := a * b / choose(len(labels))
+	max := (a + b) / 2
+	if max == expected {
+		return 1
+	}
+	return (index - expected) / (max - expected)
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSilhouette(t *testing.T) {
+	cases := []struct {
+		name     string
+		samples  [][]float64
+		clusters []int
+		want     float64
+	}{
+		// (19/21 + 17/19) / 2
+		{"pairs", [][]float64{{0}, {1}, {10}, {11}}, []int{0, 0, 1, 1}, 0.8997493734335839},
+		// (4/5 + 3/4 + 0) / 3, the singleton scores zero like sklearn
+		{"singleton", [][]float64{{0}, {1}, {5}}, []int{0, 0, 1}, 0.5166666666666667},
+		// Every sample is closer to the other cluster, (-2/5 - 1/2 - 1/2 - 2/5) / 4
+		{"swapped", [][]float64{{0}, {10}, {1}, {11}}, []int{0, 0, 1, 1}, -0.45},
+		{"single cluster", [][]float64{{0}, {1}, {10}}, []int{3, 3, 3}, 0},
+	}
+	for _, c := range cases {
+		if got := Silhouette(c.samples, c.clusters); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("%s: the silhouette is %f but should be %f", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDaviesBouldin(t *testing.T) {
+	cases := []struct {
+		name     string
+		samples  [][]float64
+		clusters []int
+		want     float64
+	}{
+		// The scatters are 1/2 and the centroids are 10 apart
+		{"pairs", [][]float64{{0}, {1}, {10}, {11}}, []int{0, 0, 1, 1}, 0.1},
+		// (2*3/sqrt(85) + 2/sqrt(164)) / 3
+		{"three", [][]float64{{0, 0}, {2, 0}, {10, 0}, {10, 4}, {0, 10}}, []int{0, 0, 1, 1, 2}, 0.2689883784},
+		{"single cluster", [][]float64{{0}, {1}, {10}}, []int{3, 3, 3}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, c := range cases {
+		if got := DaviesBouldin(c.samples, c.clusters); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("%s: the index is %f but should be %f", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAdjustedRand(t *testing.T) {
+	cases := []struct {
+		name     string
+		labels   []string
+		clusters []int
+		want     float64
+	}{
+		{"identical", []string{"a", "a", "b", "b", "c"}, []int{0, 0, 1, 1, 2}, 1},
+		{"permuted", []string{"a", "a", "b", "b"}, []int{7, 7, 3, 3}, 1},
+		// The sklearn documentation examples
+		{"split", []string{"a", "a", "b", "b"}, []int{0, 0, 1, 2}, 0.5714285714285714},
+		{"crossed", []string{"a", "a", "b", "b"}, []int{0, 1, 0, 1}, -0.5},
+		{"single cluster", []string{"a", "a", "b", "b"}, []int{0, 0, 0, 0}, 0},
+		{"single label and cluster", []string{"a", "a", "a"}, []int{0, 0, 0}, 1},
+		{"single sample", []string{"a"}, []int{0}, 1},
+	}
+	for _, c := range cases {
+		if got := AdjustedRand(c.labels, c.clusters); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("%s: the adjusted rand index is %f but should be %f", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Neighbor is a point and the attention it gets from a sample
+type Neighbor struct {
+	Index     int
+	Attention float32
+}
+
+// Model is an inference only clustering model. It only has the points of a trained network, so it doesn't
+// have the graph, the optimizer states, or the cost history, and it is safe for concurrent use. The custom
+// softmax function and the positional encodings aren't supported.
+type Model struct {
+	Width  int
+	Length int
+	Config NetworkConfig
+	// Layers are the points of each block, which have Width columns and Length rows
+	Layers [][]float32
+	// Biases are the biases of the attention scores of each block if the configuration has a bias
+	Biases [][]float32
+	// Temperature divides the attention logits before the softmax
+	Temperature float32
+	// Index is the optional index used by Nearest
+	Index *Index
+}
+
+// NewModel creates a model from the points of a trained network at the current temperature
+func NewModel(n *Network) (*Model, error) {
+	if n.Config.Encoding != EncodingNone {
+		return nil, errors.New("positional encodings aren't supported")
+	}
+	m := Model{
+		Width:       n.Width,
+		Length:      n.Length,
+		Config:      n.Config,
+		Temperature: n.temperature,
+	}
+	m.Config.Softmax, m.Config.Optimizer, m.Config.Schedule = nil, nil, nil
+	m.Config.Annealing, m.Config.Backend, m.Config.Cost = nil, nil, nil
+	m.Config.Initializer = nil
+	for _, layer := range n.Layers {
+		points := make([]float32, len(layer.X))
+		copy(points, layer.X)
+		m.Layers = append(m.Layers, points)
+	}
+	for _, bias := range n.Biases {
+		values := make([]float32, len(bias.X))
+		copy(values, bias.X)
+		m.Biases = append(m.Biases, values)
+	}
+	return &m, nil
+}
+
+// LoadModel loads a model from a set saved by Set.Save or SaveCheckpoint. The configuration is the
+// configuration the network was trained with, and the number of layers is taken from the set.
+func LoadModel(path string, config NetworkConfig) (*Model, error) {
+	if config.Encoding != EncodingNone {
+		return nil, errors.New("positional encodings aren't supported")
+	}
+	set := tf32.NewSet()
+	_, iteration, err := set.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	points := set.ByName["points"]
+	if points == nil {
+		return nil, errors.New("set doesn't have points")
+	}
+	m := Model{
+		Width:       points.S[0],
+		Length:      points.S[1],
+		Config:      config,
+		Layers:      [][]float32{points.X},
+		Temperature: config.temperature(iteration),
+	}
+	for i := 1; ; i++ {
+		layer := set.ByName[fmt.Sprintf("points%d", i)]
+		if layer == nil {
+			break
+		}
+		m.Layers = append(m.Layers, layer.X)
+	}
+	m.Config.Layers = len(m.Layers)
+	if config.Bias {
+		for i := range m.Layers {
+			name := "biases"
+			if i > 0 {
+				name = fmt.Sprintf("biases%d", i)
+			}
+			bias := set.ByName[name]
+			if bias == nil {
+				return nil, fmt.Errorf("set doesn't have %s", name)
+			}
+			m.Biases = append(m.Biases, bias.X)
+		}
+	}
+	return &m, nil
+}
+
+// softmax applies the softmax variant of the model to the values in place after dividing them by the
+// temperature
+func (m *Model) softmax(values []float32, temperature float32) {
+	if temperature == 0 {
+		temperature = 1
+	}
+	sum := float32(0)
+	if m.Config.Activation == ActivationSpherical {
+		for i, value := range values {
+			value /= temperature
+			values[i] = value*value + m.Config.Epsilon
+			sum += values[i]
+		}
+	} else {
+		max := float32(math.Inf(-1))
+		for _, value := range values {
+			if value > max {
+				max = value
+			}
+		}
+		s := float64(max) * S
+		for i, value := range values {
+			values[i] = float32(math.Exp((float64(value) - s) / float64(temperature)))
+			sum += values[i]
+		}
+	}
+	for i := range values {
+		if sum == 0 {
+			// A row of zeros without an epsilon is uniform like SphericalSoftmax
+			values[i] = 1 / float32(len(values))
+			continue
+		}
+		values[i] /= sum
+	}
+}
+
+// forward returns the l1 attention and the l2 output of the last block for the sample
+func (m *Model) forward(sample []float64) (l1, l2 []float32) {
+	input := make([]float32, m.Width)
+	for i, measure := range sample {
+		input[i] = float32(measure)
+	}
+	gamma := m.Config.Gamma
+	if gamma == 0 {
+		gamma = 1
+	}
+	for i, points := range m.Layers {
+		l1 = make([]float32, m.Length)
+		for j := range l1 {
+			point, sum := points[j*m.Width:(j+1)*m.Width], float32(0)
+			for k, value := range point {
+				if m.Config.Kernel == KernelRBF {
+					difference := value - input[k]
+					sum += difference * difference
+				} else {
+					sum += value * input[k]
+				}
+			}
+			if m.Config.Kernel == KernelRBF {
+				sum *= -gamma
+			}
+			if m.Config.Bias {
+				sum += m.Biases[i][j]
+			}
+			l1[j] = sum
+		}
+		m.softmax(l1, m.Temperature)
+
+		l2 = make([]float32, m.Width)
+		for j, attention := range l1 {
+			for k, value := range points[j*m.Width : (j+1)*m.Width] {
+				l2[k] += attention * value
+			}
+		}
+		m.softmax(l2, 1)
+
+		if m.Config.Residual {
+			mean, variance := float32(0), float32(0)
+			for k := range l2 {
+				input[k] += l2[k]
+				mean += input[k]
+			}
+			mean /= float32(m.Width)
+			for _, value := range input {
+				variance += (value - mean) * (value - mean)
+			}
+			deviation := float32(math.Sqrt(float64(variance/float32(m.Width) + 1e-5)))
+			for k := range input {
+				input[k] = (input[k] - mean) / deviation
+			}
+			continue
+		}
+		input = l2
+	}
+	return l1, l2
+}
+
+// Entropy returns the self entropy of the l2 output for the sample, which is the cost of a network trained
+// on the entropy objective
+func (m *Model) Entropy(sample []float64) float32 {
+	_, l2 := m.forward(sample)
+	sum := float32(0)
+	for _, value := range l2 {
+		sum += value * float32(math.Log(float64(value)))
+	}
+	return -sum
+}
+
+// Attend returns the l1 attention over the points of the last block for the sample
+func (m *Model) Attend(sample []float64) []float32 {
+	l1, _ := m.forward(sample)
+	return l1
+}
+
+// BuildIndex builds an index over the points of the model that Nearest uses instead of scoring every
+// point. Only models with a single block and the softmax activation can be indexed.
+func (m *Model) BuildIndex() error {
+	err := m.Config.indexable(len(m.Layers))
+	if err != nil {
+		return err
+	}
+	m.Index = NewIndex(m.Layers[0], m.Width, m.Config.Kernel)
+	return nil
+}
+
+// Nearest returns the k points with the most attention for the sample in order of decreasing attention.
+// If the model has an index the attention is normalized over the k points like Network.Nearest.
+func (m *Model) Nearest(sample []float64, k int) []Neighbor {
+	if m.Index != nil {
+		input := make([]float32, m.Width)
+		for i, measure := range sample {
+			input[i] = float32(measure)
+		}
+		return m.Index.neighbors(m.Layers[0], input, k, m.Config.Gamma, m.Temperature)
+	}
+	return nearest(m.Attend(sample), k)
+}
+
+// nearest returns the k points with the most attention
+func nearest(attention []float32, k int) []Neighbor {
+	neighbors := make([]Neighbor, len(attention))
+	for i, a := range attention {
+		neighbors[i] = Neighbor{
+			Index:     i,
+			Attention: a,
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Attention > neighbors[j].Attention
+	})
+	if k < len(neighbors) {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"testing"
+)
+
+// near is true if a and b are equal to a relative tolerance
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) <= 1e-4*math.Max(1, math.Abs(float64(b)))
+}
+
+func TestModel(t *testing.T) {
+	spherical := DefaultNetworkConfig()
+	spherical.Activation, spherical.Epsilon = ActivationSpherical, 1e-3
+	rbf := DefaultNetworkConfig()
+	rbf.Kernel, rbf.Gamma = KernelRBF, .5
+	cases := []struct {
+		name    string
+		config  NetworkConfig
+		options []Option
+	}{
+		{"softmax", DefaultNetworkConfig(), nil},
+		{"temperature", DefaultNetworkConfig(), []Option{WithTemperature(2)}},
+		{"spherical", spherical, nil},
+		{"rbf", rbf, nil},
+		{"stacked", DefaultNetworkConfig(), []Option{WithDepth(3), WithBias()}},
+		{"residual", DefaultNetworkConfig(), []Option{WithDepth(2), WithResidual()}},
+	}
+	samples := make([]Sample, len(recoverySamples))
+	for i, measures := range recoverySamples {
+		samples[i] = Sample{Measures: measures}
+	}
+	for _, c := range cases {
+		n := NewNetworkWithConfig(3, len(recoverySamples), c.config, c.options...)
+		for i, sample := range recoverySamples {
+			for j, measure := range sample {
+				n.Point.X[i*3+j] = float32(measure)
+			}
+		}
+		for i := 0; i < 8; i++ {
+			n.Iterate(recoverySamples[i%len(recoverySamples)])
+		}
+		m, err := NewModel(n)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, entropy := range n.GetEntropy(samples) {
+			if got := m.Entropy(entropy.Measures); !near(got, entropy.Entropy) {
+				t.Errorf("%s: the entropy of %d is %f but should be %f", c.name, entropy.Index, got, entropy.Entropy)
+			}
+			want, got := n.Attention(entropy.Measures), m.Attend(entropy.Measures)
+			for i := range want {
+				if !near(got[i], want[i]) {
+					t.Errorf("%s: attention %d of %d is %f but should be %f", c.name, i, entropy.Index, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestModelSphericalZero(t *testing.T) {
+	config := DefaultNetworkConfig()
+	config.Activation, config.Epsilon = ActivationSpherical, 0
+	n := NewNetworkWithConfig(3, 2, config)
+	m, err := NewModel(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, value := range m.Attend([]float64{0, 0, 0}) {
+		if value != .5 {
+			t.Errorf("attention %d of the zero row is %f but should be uniform", i, value)
+		}
+	}
+	want := n.Attention([]float64{0, 0, 0})
+	for i, value := range m.Attend([]float64{0, 0, 0}) {
+		if value != want[i] {
+			t.Errorf("attention %d of the zero row is %f but the network has %f", i, value, want[i])
+		}
+	}
+}
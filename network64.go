@@ -0,0 +1,267 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pointlander/gradient/tf64"
+)
+
+// Softmax64 is the float64 softmax function for big numbers
+func Softmax64(k tf64.Continuation, node int, a *tf64.V, options ...map[string]interface{}) bool {
+	c, size, width := tf64.NewV(a.S...), len(a.X), a.S[0]
+	values := make([]float64, width)
+	for i := 0; i < size; i += width {
+		// Each row is shifted by its own max like Softmax
+		max := math.Inf(-1)
+		for _, v := range a.X[i : i+width] {
+			if v > max {
+				max = v
+			}
+		}
+		s := max * S
+		sum := 0.0
+		for j, ax := range a.X[i : i+width] {
+			values[j] = math.Exp(ax - s)
+			sum += values[j]
+		}
+		for _, cx := range values {
+			c.X = append(c.X, cx/sum)
+		}
+	}
+	if k(&c) {
+		return true
+	}
+	for i, d := range c.D {
+		cx := c.X[i]
+		a.D[i] += d * (cx - cx*cx)
+	}
+	return false
+}
+
+// SphericalSoftmax64 is the float64 spherical softmax function. The float64 pointed to by the optional
+// epsilon option is added to each squared value.
+// https://arxiv.org/abs/1511.05042
+func SphericalSoftmax64(k tf64.Continuation, node int, a *tf64.V, options ...map[string]interface{}) bool {
+	E := 0.0
+	if len(options) > 0 {
+		if e, ok := options[0]["epsilon"].(*float64); ok {
+			E = *e
+		}
+	}
+	c, size, width := tf64.NewV(a.S...), len(a.X), a.S[0]
+	values, sums, row := make([]float64, width), make([]float64, a.S[1]), 0
+	for i := 0; i < size; i += width {
+		sum := 0.0
+		for j, ax := range a.X[i : i+width] {
+			values[j] = ax*ax + E
+			sum += values[j]
+		}
+		for _, cx := range values {
+			c.X = append(c.X, cx/sum)
+		}
+		sums[row] = sum
+		row++
+	}
+	if k(&c) {
+		return true
+	}
+	for i, d := range c.D {
+		ax, sum := a.X[i], sums[i/width]
+		a.D[i] += d * (2 * ax * (sum - (ax*ax + E))) / (sum * sum)
+	}
+	return false
+}
+
+// Network64 is a clustering neural network backed by float64 tensors, which avoids the float32 underflow
+// of the entropy cost on small high precision data sets. Only the entropy objective, the dot product
+// kernel, and stacked blocks without residual connections are supported.
+type Network64 struct {
+	Rnd     *rand.Rand
+	Source  *Source
+	Width   int
+	Length  int
+	Config  NetworkConfig
+	Set     tf64.Set
+	Others  tf64.Set
+	Input   *tf64.V
+	Point   *tf64.V
+	Layers  []*tf64.V
+	L1      tf64.Meta
+	L2      tf64.Meta
+	Cost    tf64.Meta
+	I       int
+	Points  []XY
+	Logger  *slog.Logger
+	OnStep  StepFunc
+	epsilon float64
+}
+
+// NewNetwork64 creates a new float64 neural network with the default configuration modified by the options
+func NewNetwork64(width, length int, options ...Option) *Network64 {
+	return NewNetwork64WithConfig(width, length, DefaultNetworkConfig(), options...)
+}
+
+// NewNetwork64WithConfig creates a new float64 neural network from a configuration modified by the options.
+// The options are applied to a float32 network with the same configuration, so WithSoftmax,
+// WithOptimizer, WithTemperature, and WithAnnealing have no effect.
+func NewNetwork64WithConfig(width, length int, config NetworkConfig, options ...Option) *Network64 {
+	source := NewSource(1)
+	n := Network64{
+		Rnd:    rand.New(source),
+		Source: source,
+		Logger: NewNopLogger(),
+		Width:  width,
+		Length: length,
+		Config: config,
+		I:      1,
+	}
+	o := Network{
+		Rnd:    n.Rnd,
+		Source: source,
+		Config: config,
+	}
+	for _, option := range options {
+		option(&o)
+	}
+	n.Config = o.Config
+	config = n.Config
+	if config.Layers < 1 {
+		panic("a network needs at least one layer")
+	}
+	if config.Residual || config.Objective != ObjectiveEntropy || config.Variational ||
+		config.Kernel != KernelDot || config.Encoding != EncodingNone {
+		panic("float64 networks only support the entropy of stacked blocks")
+	}
+	n.epsilon = float64(config.Epsilon)
+
+	// Create the input data matrix
+	n.Others = tf64.NewSet()
+	n.Others.Add("input", width, 1)
+	n.Input = n.Others.ByName["input"]
+	n.Input.X = n.Input.X[:cap(n.Input.X)]
+
+	// Create the weight data matrix
+	n.Set = tf64.NewSet()
+	for i := 0; i < config.Layers; i++ {
+		name := "points"
+		if i > 0 {
+			name = fmt.Sprintf("points%d", i)
+		}
+		n.Set.Add(name, width, length)
+		layer := n.Set.ByName[name]
+		layer.X = layer.X[:cap(layer.X)]
+		if i > 0 {
+			for j := range layer.X {
+				layer.X[j] = 2*n.Rnd.Float64() - 1
+			}
+		}
+		layer.States = make([][]float64, StateTotal)
+		for j := range layer.States {
+			layer.States[j] = make([]float64, len(layer.X))
+		}
+		n.Layers = append(n.Layers, layer)
+	}
+	n.Point = n.Layers[0]
+
+	// The neural network is the attention model from attention is all you need
+	softmax := tf64.U(Softmax64)
+	if config.Activation == ActivationSpherical {
+		spherical := tf64.U(SphericalSoftmax64)
+		softmax = func(a tf64.Meta, options ...map[string]interface{}) tf64.Meta {
+			return spherical(a, map[string]interface{}{
+				"epsilon": &n.epsilon,
+			})
+		}
+	}
+	input := n.Others.Get("input")
+	for _, layer := range n.Layers {
+		points := layer.Meta()
+		n.L1 = softmax(tf64.Mul(points, input))
+		n.L2 = softmax(tf64.T(tf64.Mul(n.L1, tf64.T(points))))
+		input = n.L2
+	}
+	n.Cost = tf64.Entropy(n.L2)
+
+	n.Points = make([]XY, 0, 8)
+
+	return &n
+}
+
+// load loads the input
+func (n *Network64) load(data []float64) {
+	copy(n.Input.X, data)
+}
+
+// GetEntropy returns the entropy of the network
+func (n *Network64) GetEntropy(inputs []Sample) []Entropy {
+	outputs := make([]Entropy, 0, len(inputs))
+	for i, sample := range inputs {
+		n.load(sample.Measures)
+		n.Cost(func(a *tf64.V) bool {
+			outputs = append(outputs, Entropy{
+				Entropy:  float32(a.X[0]),
+				Label:    sample.Label,
+				Measures: sample.Measures,
+				Index:    i,
+			})
+			return true
+		})
+	}
+	return rank(outputs)
+}
+
+// Attention returns the l1 attention of the input over the points
+func (n *Network64) Attention(measures []float64) []float64 {
+	n.load(measures)
+	var attention []float64
+	n.L1(func(a *tf64.V) bool {
+		attention = make([]float64, len(a.X))
+		copy(attention, a.X)
+		return true
+	})
+	return attention
+}
+
+// Iterate does a gradient descent operation on the sample and returns the cost
+func (n *Network64) Iterate(data []float64) float64 {
+	n.load(data)
+
+	start := time.Now()
+	// Calculate the gradients
+	total := tf64.Gradient(n.Cost).X[0]
+
+	// Update the point weights with the partial derivatives using adam
+	b1, b2 := math.Pow(B1, float64(n.I)), math.Pow(B2, float64(n.I))
+	eta := float64(n.Config.rate(n.I))
+	for _, w := range n.Set.Weights {
+		for k, d := range w.D {
+			m := B1*w.States[StateM][k] + (1-B1)*d
+			v := B2*w.States[StateV][k] + (1-B2)*d*d
+			w.States[StateM][k] = m
+			w.States[StateV][k] = v
+			mhat := m / (1 - b1)
+			vhat := v / (1 - b2)
+			w.X[k] -= eta * mhat / (math.Sqrt(vhat) + 1e-8)
+		}
+	}
+
+	// Housekeeping
+	end := time.Since(start)
+	n.Set.Zero()
+	n.Others.Zero()
+	n.Points = append(n.Points, XY{X: float64(n.I), Y: total})
+	if n.OnStep != nil {
+		n.OnStep(n.I, float32(total), end)
+	}
+	n.I++
+
+	return total
+}
@@ -6,21 +6,13 @@ package occam
 
 import (
 	"fmt"
-	"io"
+	"log/slog"
 	"math"
 	"math/rand"
-	"os"
 	"sort"
 	"time"
 
-	"github.com/go-echarts/go-echarts/v2/charts"
-	"github.com/go-echarts/go-echarts/v2/components"
-	"github.com/go-echarts/go-echarts/v2/opts"
-	"github.com/pointlander/datum/iris"
 	"github.com/pointlander/gradient/tf32"
-	"github.com/pointlander/levenshtein"
-	"github.com/pointlander/pagerank"
-	"gonum.org/v1/plot/plotter"
 )
 
 const (
@@ -43,99 +35,354 @@ const (
 	StateTotal
 )
 
-// Softmax is the softmax function for big numbers
+// temperature returns the float32 pointed to by the temperature option, one if the option isn't set or is
+// zero
+func temperature(options []map[string]interface{}) float32 {
+	if len(options) > 0 {
+		if t, ok := options[0]["temperature"].(*float32); ok && t != nil && *t != 0 {
+			return *t
+		}
+	}
+	return 1
+}
+
+// Softmax is the softmax function for big numbers. The values are divided by the float32 pointed to by the
+// optional temperature option before exponentiation. The values are split between the number of workers
+// pointed to by the optional parallelism option.
 func Softmax(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
+	workers, t := parallelism(options), float64(temperature(options))
 	c, size, width := tf32.NewV(a.S...), len(a.X), a.S[0]
-	max := float32(0)
-	for _, v := range a.X {
-		if v > max {
-			max = v
+	// Each row is shifted by its own max, so a row of a batch isn't underflowed by the max of another row
+	maxes := make([]float64, a.S[1])
+	for i := range maxes {
+		max := float32(math.Inf(-1))
+		for _, v := range a.X[i*width : (i+1)*width] {
+			if v > max {
+				max = v
+			}
 		}
+		maxes[i] = float64(max) * S
 	}
-	values := make([]float64, width)
-	for i := 0; i < size; i += width {
-		s := float64(max) * S
-		sum := 0.0
-		for j, ax := range a.X[i : i+width] {
-			values[j] = math.Exp(float64(ax) - s)
-			sum += values[j]
+	values, sums := make([]float64, size), make([]float64, a.S[1])
+	partition(size, workers, func(begin, end int) {
+		for i, ax := range a.X[begin:end] {
+			values[begin+i] = math.Exp((float64(ax) - maxes[(begin+i)/width]) / t)
 		}
-		for _, cx := range values {
-			c.X = append(c.X, float32(cx/sum))
+	})
+	for i, value := range values {
+		sums[i/width] += value
+	}
+	c.X = c.X[:size]
+	partition(size, workers, func(begin, end int) {
+		for i := begin; i < end; i++ {
+			c.X[i] = float32(values[i] / sums[i/width])
 		}
+	})
+	if k(&c) {
+		return true
 	}
+	partition(size, workers, func(begin, end int) {
+		for i, d := range c.D[begin:end] {
+			cx := c.X[begin+i]
+			a.D[begin+i] += d * (cx - cx*cx) / float32(t)
+		}
+	})
+	return false
+}
+
+// SphericalSoftmax is the spherical softmax function. The float32 pointed to by the optional epsilon
+// option is added to each squared value to stabilize rows that are close to zero. The values are divided
+// by the float32 pointed to by the optional temperature option before they are squared. The values are
+// split between the number of workers pointed to by the optional parallelism option.
+// https://arxiv.org/abs/1511.05042
+func SphericalSoftmax(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
+	E := float32(0)
+	if len(options) > 0 {
+		if e, ok := options[0]["epsilon"].(*float32); ok {
+			E = *e
+		}
+	}
+	workers, t := parallelism(options), temperature(options)
+	c, size, width := tf32.NewV(a.S...), len(a.X), a.S[0]
+	values, sums := make([]float32, size), make([]float32, a.S[1])
+	partition(size, workers, func(begin, end int) {
+		for i, ax := range a.X[begin:end] {
+			ax /= t
+			values[begin+i] = ax*ax + E
+		}
+	})
+	for i, value := range values {
+		sums[i/width] += value
+	}
+	c.X = c.X[:size]
+	partition(size, workers, func(begin, end int) {
+		for i := begin; i < end; i++ {
+			if sum := sums[i/width]; sum == 0 {
+				// A row of zeros without an epsilon is uniform
+				c.X[i] = 1 / float32(width)
+			} else {
+				c.X[i] = values[i] / sum
+			}
+		}
+	})
 	if k(&c) {
 		return true
 	}
+	// The derivative of output j with respect to input i is 2 a_i (δ_ij - c_j) / sum, so the gradient of
+	// input i is 2 a_i (d_i - Σ_j d_j c_j) / sum
+	dots := make([]float32, a.S[1])
 	for i, d := range c.D {
-		cx := c.X[i]
-		a.D[i] += d * (cx - cx*cx)
+		dots[i/width] += d * c.X[i]
 	}
+	partition(size, workers, func(begin, end int) {
+		for i, d := range c.D[begin:end] {
+			ax, row := a.X[begin+i]/t, (begin+i)/width
+			if sums[row] == 0 {
+				continue
+			}
+			a.D[begin+i] += 2 * ax * (d - dots[row]) / (sums[row] * t)
+		}
+	})
 	return false
 }
 
-// SphericalSoftmax is the spherical softmax function
-// https://arxiv.org/abs/1511.05042
-func SphericalSoftmax(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
-	const E = .0
+// LayerNorm normalizes each row to have zero mean and unit variance
+// https://arxiv.org/abs/1607.06450
+func LayerNorm(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
+	const E = 1e-5
 	c, size, width := tf32.NewV(a.S...), len(a.X), a.S[0]
-	values, sums, row := make([]float32, width), make([]float32, a.S[1]), 0
+	deviations, row := make([]float32, a.S[1]), 0
+	for i := 0; i < size; i += width {
+		mean := float32(0.0)
+		for _, ax := range a.X[i : i+width] {
+			mean += ax
+		}
+		mean /= float32(width)
+		variance := float32(0.0)
+		for _, ax := range a.X[i : i+width] {
+			difference := ax - mean
+			variance += difference * difference
+		}
+		variance /= float32(width)
+		deviation := float32(math.Sqrt(float64(variance + E)))
+		for _, ax := range a.X[i : i+width] {
+			c.X = append(c.X, (ax-mean)/deviation)
+		}
+		deviations[row] = deviation
+		row++
+	}
+	if k(&c) {
+		return true
+	}
+	row = 0
 	for i := 0; i < size; i += width {
-		sum := float32(0.0)
-		for j, ax := range a.X[i : i+width] {
-			values[j] = ax*ax + E
-			sum += values[j]
+		d, x := c.D[i:i+width], c.X[i:i+width]
+		meanD, meanDX := float32(0.0), float32(0.0)
+		for j := range d {
+			meanD += d[j]
+			meanDX += d[j] * x[j]
 		}
-		for _, cx := range values {
-			c.X = append(c.X, (cx+E)/sum)
+		meanD /= float32(width)
+		meanDX /= float32(width)
+		for j := range d {
+			a.D[i+j] += (d[j] - meanD - x[j]*meanDX) / deviations[row]
 		}
-		sums[row] = sum
 		row++
 	}
+	return false
+}
+
+// Scale multiplies a tensor by the float32 pointed to by the scale option
+func Scale(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool {
+	scale := *options[0]["scale"].(*float32)
+	c := tf32.NewV(a.S...)
+	for _, ax := range a.X {
+		c.X = append(c.X, ax*scale)
+	}
 	if k(&c) {
 		return true
 	}
-	// (2 a (b^2 + c^2 + d^2 + 0.003))/(a^2 + b^2 + c^2 + d^2 + 0.004)^2
 	for i, d := range c.D {
-		ax, sum := a.X[i], sums[i/width]
-		//a.D[i] += d*(2*ax*(sum-(ax*ax+E)))/(sum*sum) - d*cx*2*ax/sum
-		a.D[i] += d * (2 * ax * (sum - (ax*ax + E))) / (sum * sum)
+		a.D[i] += d * scale
+	}
+	return false
+}
+
+// Distance computes the negative squared euclidean distance between the rows of a and the rows of b
+// scaled by the float32 pointed to by the gamma option. The shape of the output is the same as Mul.
+// A gamma of zero is treated as one.
+func Distance(k tf32.Continuation, node int, a, b *tf32.V, options ...map[string]interface{}) bool {
+	if len(a.S) != 2 || len(b.S) != 2 {
+		panic("tensor needs to have two dimensions")
+	}
+	width := a.S[0]
+	if width != b.S[0] {
+		panic("first dimension is not the same")
+	}
+	gamma := float32(1)
+	if len(options) > 0 {
+		if g, ok := options[0]["gamma"].(*float32); ok && *g != 0 {
+			gamma = *g
+		}
+	}
+	c := tf32.NewV(a.S[1], b.S[1])
+	for i := 0; i < len(b.X); i += width {
+		bv := b.X[i : i+width]
+		for j := 0; j < len(a.X); j += width {
+			sum := float32(0.0)
+			for k, ax := range a.X[j : j+width] {
+				difference := ax - bv[k]
+				sum += difference * difference
+			}
+			c.X = append(c.X, -gamma*sum)
+		}
+	}
+	if k(&c) {
+		return true
+	}
+	index := 0
+	for i := 0; i < len(b.X); i += width {
+		for j := 0; j < len(a.X); j += width {
+			d := 2 * gamma * c.D[index]
+			for k := 0; k < width; k++ {
+				difference := a.X[j+k] - b.X[i+k]
+				a.D[j+k] -= d * difference
+				b.D[i+k] += d * difference
+			}
+			index++
+		}
 	}
 	return false
 }
 
+// XY is a point of the cost history
+type XY struct {
+	X, Y float64
+}
+
 // Network is a clustering neural network
 type Network struct {
 	Rnd    *rand.Rand
+	Source *Source
 	Width  int
 	Length int
+	Config NetworkConfig
 	Set    tf32.Set
 	Others tf32.Set
 	Input  *tf32.V
 	Point  *tf32.V
-	L1     tf32.Meta
-	L2     tf32.Meta
-	Cost   tf32.Meta
-	I      int
-	Points plotter.XYs
+	Layers []*tf32.V
+	// Biases are the biases of the attention scores of each block if the configuration has a bias
+	Biases   []*tf32.V
+	L1       tf32.Meta
+	L2       tf32.Meta
+	Cost     tf32.Meta
+	I        int
+	Points   []XY
+	Position Position
+	// Contrastive is the graph for must link and cannot link pairs
+	Contrastive *Contrastive
+	// Distillation is the graph for distilling a teacher network
+	Distillation *Distillation
+	// Supervision is the graph for the classification head
+	Supervision *Supervision
+	// Head is the classification head trained by FitHead
+	Head *Classifier
+	// Frozen are the rows of the weights that aren't updated
+	Frozen map[string][]bool
+	// Sensitivity is the accumulated fisher information of the points
+	Sensitivity Sensitivity
+	// Trajectory is the recorded positions of the tracked points
+	Trajectory *Trajectory
+	// Snapshots are the copies of the network taken during training
+	Snapshots *Snapshots
+	// Recovery rolls the network back when the cost isn't finite
+	Recovery *Recovery
+	// Stream is the state of the online clustering mode
+	Stream *Stream
+	// Normalization is the normalization applied to the inputs, which is exported with the network
+	Normalization Normalization
+	// Index is the optional index of the points used by Nearest
+	Index *Index
+	// Logger is the structured logger of the network, which discards all records by default
+	Logger *slog.Logger
+	// OnStep is called after each training step, History by default
+	OnStep StepFunc
+	// DisableHistory stops the cost of each step from being recorded in Points
+	DisableHistory bool
+	// temperature is the annealed temperature of the attention for the current iteration
+	temperature float32
+	// norm is the global norm of the gradients of the last step before clipping
+	norm float32
 }
 
-func (n *Network) pow(x float32) float32 {
-	y := math.Pow(float64(x), float64(n.I))
+func pow(x float32, i int) float32 {
+	y := math.Pow(float64(x), float64(i))
 	if math.IsNaN(y) || math.IsInf(y, 0) {
 		return 0
 	}
 	return float32(y)
 }
 
-// Creates a new neural network
-func NewNetwork(width, length int) *Network {
+// Creates a new neural network with the default configuration modified by the options
+func NewNetwork(width, length int, options ...Option) *Network {
+	return NewNetworkWithConfig(width, length, DefaultNetworkConfig(), options...)
+}
+
+// NewPositionalNetwork creates a new neural network with a positional encoding added to the input
+func NewPositionalNetwork(width, length int, encoding Encoding, positions int) *Network {
+	config := DefaultNetworkConfig()
+	config.Encoding, config.Positions = encoding, positions
+	return NewNetworkWithConfig(width, length, config)
+}
+
+// NewStackedNetwork creates a new neural network with layers attention blocks stacked on top of each other.
+// Each block has its own points matrix and the entropy is computed at the top block.
+func NewStackedNetwork(width, length, layers int) *Network {
+	config := DefaultNetworkConfig()
+	config.Layers = layers
+	return NewNetworkWithConfig(width, length, config)
+}
+
+// NewResidualNetwork creates a new stacked neural network where the input of each block is added to
+// its output and then layer normalized before being passed to the next block
+func NewResidualNetwork(width, length, layers int) *Network {
+	config := DefaultNetworkConfig()
+	config.Layers, config.Residual = layers, true
+	return NewNetworkWithConfig(width, length, config)
+}
+
+// NewNetworkWithConfig creates a new neural network from a configuration modified by the options
+func NewNetworkWithConfig(width, length int, config NetworkConfig, options ...Option) *Network {
+	source := NewSource(1)
 	n := Network{
-		Rnd:    rand.New(rand.NewSource(1)),
+		Rnd:    rand.New(source),
+		Source: source,
+		Logger: NewNopLogger(),
 		Width:  width,
 		Length: length,
+		Config: config,
 		I:      1,
 	}
+	for _, option := range options {
+		option(&n)
+	}
+	if n.Config.Layers < 1 {
+		panic("a network needs at least one layer")
+	}
+	n.build()
+
+	n.Points = make([]XY, 0, 8)
+	if n.OnStep == nil {
+		n.OnStep = n.History()
+	}
+
+	return &n
+}
+
+// build creates the weights and the graph of the network for its width, length, and configuration
+func (n *Network) build() {
+	width, length, config := n.Width, n.Length, n.Config
 
 	// Create the input data matrix
 	n.Others = tf32.NewSet()
@@ -152,33 +399,209 @@ func NewNetwork(width, length int) *Network {
 	for i := range n.Point.States {
 		n.Point.States[i] = make([]float32, len(n.Point.X))
 	}
+	n.Layers = []*tf32.V{n.Point}
+	for i := 1; i < config.Layers; i++ {
+		name := fmt.Sprintf("points%d", i)
+		n.Set.Add(name, width, length)
+		layer := n.Set.ByName[name]
+		for j := 0; j < cap(layer.X); j++ {
+			layer.X = append(layer.X, float32(2*n.Rnd.Float64()-1))
+		}
+		layer.States = make([][]float32, StateTotal)
+		for j := range layer.States {
+			layer.States[j] = make([]float32, len(layer.X))
+		}
+		n.Layers = append(n.Layers, layer)
+	}
+	n.Biases = nil
+	if config.Bias {
+		for i := range n.Layers {
+			name := "biases"
+			if i > 0 {
+				name = fmt.Sprintf("biases%d", i)
+			}
+			n.Set.Add(name, length, 1)
+			bias := n.Set.ByName[name]
+			bias.X = bias.X[:cap(bias.X)]
+			bias.States = make([][]float32, StateTotal)
+			for j := range bias.States {
+				bias.States[j] = make([]float32, len(bias.X))
+			}
+			n.Biases = append(n.Biases, bias)
+		}
+	}
+	if config.Initializer != nil {
+		n.Initialize(config.Initializer)
+	}
 
 	// The neural network is the attention model from attention is all you need
+	n.anneal()
+	input := n.encode(n.Others.Get("input"), config.Encoding, config.Positions)
+	n.L1, n.L2 = n.attention(input)
+	n.Cost = n.objective()
+}
+
+// softmax returns the softmax function used for the attention
+func (n *Network) softmax() func(a tf32.Meta, options ...map[string]interface{}) tf32.Meta {
+	softmax := tf32.U(Softmax)
+	if n.Config.Softmax != nil {
+		softmax = tf32.U(tf32.Unary(n.Config.Softmax))
+	} else if n.Config.Activation == ActivationSpherical {
+		softmax = tf32.U(SphericalSoftmax)
+	}
+	return func(a tf32.Meta, options ...map[string]interface{}) tf32.Meta {
+		return softmax(a, map[string]interface{}{
+			"epsilon":     &n.Config.Epsilon,
+			"parallelism": &n.Config.Parallelism,
+		})
+	}
+}
+
+// attend returns the softmax function used for the attention of the points, which divides the scores by
+// the annealed temperature of the network
+func (n *Network) attend() func(a tf32.Meta, options ...map[string]interface{}) tf32.Meta {
 	softmax := tf32.U(Softmax)
-	_ = softmax
-	spherical := tf32.U(SphericalSoftmax)
-	_ = spherical
-	n.L1 = softmax(tf32.Mul(n.Set.Get("points"), n.Others.Get("input")))
-	n.L2 = softmax(tf32.T(tf32.Mul(n.L1, tf32.T(n.Set.Get("points")))))
-	n.Cost = tf32.Entropy(n.L2)
+	if n.Config.Softmax != nil {
+		softmax = tf32.U(tf32.Unary(n.Config.Softmax))
+	} else if n.Config.Activation == ActivationSpherical {
+		softmax = tf32.U(SphericalSoftmax)
+	}
+	return func(a tf32.Meta, options ...map[string]interface{}) tf32.Meta {
+		return softmax(a, map[string]interface{}{
+			"epsilon":     &n.Config.Epsilon,
+			"parallelism": &n.Config.Parallelism,
+			"temperature": &n.temperature,
+		})
+	}
+}
 
-	n.Points = make(plotter.XYs, 0, 8)
+// anneal sets the temperature of the attention for the current iteration
+func (n *Network) anneal() {
+	n.temperature = n.Config.temperature(n.I)
+}
 
-	return &n
+// score scores the points against the input with the kernel of the network
+func (n *Network) score(points, input tf32.Meta) tf32.Meta {
+	if n.Config.Kernel == KernelRBF {
+		return tf32.B(Distance)(points, input, map[string]interface{}{
+			"gamma": &n.Config.Gamma,
+		})
+	}
+	return n.mul(points, input)
+}
+
+// attention builds the stacked attention blocks over the rows of the input and returns the l1 and l2
+// outputs of the last block
+func (n *Network) attention(input tf32.Meta) (l1, l2 tf32.Meta) {
+	softmax, attend := n.softmax(), n.attend()
+	norm := tf32.U(LayerNorm)
+	for i, layer := range n.Layers {
+		points := layer.Meta()
+		scores := n.score(points, input)
+		if n.Config.Bias {
+			scores = tf32.Add(scores, n.Biases[i].Meta())
+		}
+		l1 = attend(scores)
+		l2 = softmax(tf32.T(n.mul(l1, tf32.T(points))))
+		if n.Config.Residual {
+			input = norm(tf32.Add(l2, input))
+			continue
+		}
+		input = l2
+	}
+	return l1, l2
+}
+
+// constant adds a scalar constant to the others set
+func (n *Network) constant(name string, value float32) tf32.Meta {
+	n.Others.Add(name, 1, 1)
+	n.Others.ByName[name].X = append(n.Others.ByName[name].X, value)
+	return n.Others.Get(name)
+}
+
+// objective builds the cost the network is trained on
+func (n *Network) objective() tf32.Meta {
+	cost := tf32.Entropy(n.L2)
+	if n.Config.Cost != nil {
+		cost = n.Config.Cost(n)
+	} else if n.Config.Objective != ObjectiveEntropy {
+		var loss tf32.Meta
+		switch n.Config.Reconstruction {
+		case ReconstructionMSE:
+			loss = tf32.Quadratic(n.L2, n.Others.Get("input"))
+		case ReconstructionCosine:
+			loss = tf32.Sub(n.constant("one", 1), tf32.Similarity(n.L2, n.Others.Get("input")))
+		default:
+			panic("unknown reconstruction loss")
+		}
+		if n.Config.Objective == ObjectiveReconstruction {
+			cost = loss
+		} else {
+			cost = tf32.Add(cost, loss)
+		}
+	}
+	if n.Config.Variational {
+		// The kl divergence between the attention distribution and the uniform prior
+		scale := tf32.U(Scale)
+		logK := n.constant("logK", float32(math.Log(float64(n.Length))))
+		kl := tf32.Sub(logK, tf32.Entropy(n.L1))
+		cost = tf32.Add(cost, scale(kl, map[string]interface{}{
+			"scale": &n.Config.Beta,
+		}))
+	}
+	return cost
 }
 
 // Entropy is the self entropy of a point
 type Entropy struct {
-	Entropy   float32
-	Label     string
-	Measures  []float64
-	Index     int
-	Order     int
+	Entropy  float32
+	Label    string
+	Measures []float64
+	// Index is the index of the input
+	Index int
+	// Order is the rank of the input in order of decreasing entropy, which is its position after a
+	// stable sort of the entropies in descending order
+	Order int
+	// Optimized is the entropy of the input after optimization, which is set by EntropyDelta
 	Optimized float32
 }
 
-// GetEntropy returns the entropy of the network
-func (n *Network) GetEntropy(inputs []iris.Iris) []Entropy {
+// rank sets the Order of the entropies, which are in the order of the inputs
+func rank(entropy []Entropy) []Entropy {
+	order := make([]int, len(entropy))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return entropy[order[i]].Entropy > entropy[order[j]].Entropy
+	})
+	for i, index := range order {
+		entropy[index].Order = i
+	}
+	return entropy
+}
+
+// EntropyDelta returns the entropies before optimization with Optimized set to the entropy of the same
+// input after optimization, which is matched by Index. The entropies are sorted by decreasing drop in
+// entropy, Entropy - Optimized.
+func (n *Network) EntropyDelta(before, after []Entropy) []Entropy {
+	optimized := make(map[int]float32, len(after))
+	for _, e := range after {
+		optimized[e.Index] = e.Entropy
+	}
+	delta := make([]Entropy, len(before))
+	copy(delta, before)
+	for i, e := range delta {
+		delta[i].Optimized = optimized[e.Index]
+	}
+	sort.SliceStable(delta, func(i, j int) bool {
+		return delta[i].Entropy-delta[i].Optimized > delta[j].Entropy-delta[j].Optimized
+	})
+	return delta
+}
+
+// GetEntropy returns the entropy of the network for each input in the order of the inputs
+func (n *Network) GetEntropy(inputs []Sample) []Entropy {
 	outputs := make([]Entropy, 0, len(inputs))
 	for i := 0; i < len(inputs); i++ {
 		// Load the input
@@ -197,311 +620,122 @@ func (n *Network) GetEntropy(inputs []iris.Iris) []Entropy {
 			return true
 		})
 	}
-	return outputs
+	return rank(outputs)
 }
 
-// GetGradients returns the gradients of the network
-func (n *Network) GetGradients(inputs []iris.Iris) [][]float32 {
+// Gradient is the variable the gradients of the cost are taken with respect to
+type Gradient int
+
+const (
+	// GradientPoints are the gradients with respect to the points of the first block
+	GradientPoints Gradient = iota
+	// GradientInput are the gradients with respect to the input
+	GradientInput
+)
+
+// GetGradients returns the gradient of the cost for each input without updating the weights. The
+// gradients are in the order of the inputs. The gradient of an input is with respect to the points by
+// default, ordered by point and then by measure, so the partial derivative for measure j of point i
+// is at index i*Width + j. If GradientInput is given the gradient is with respect to the measures of
+// the input instead.
+func (n *Network) GetGradients(inputs []Sample, gradient ...Gradient) [][]float32 {
+	wrt := GradientPoints
+	if len(gradient) > 0 {
+		wrt = gradient[0]
+	}
+	gradients := make([][]float32, 0, len(inputs))
 	for _, input := range inputs {
+		if wrt == GradientPoints {
+			gradients = append(gradients, n.gradient([][]float64{input.Measures}))
+			continue
+		}
 		for i, measure := range input.Measures {
 			n.Input.X[i] = float32(measure)
 		}
-
-		// Calculate the gradients
 		tf32.Gradient(n.Cost)
-	}
-
-	gradients := make([][]float32, 0, n.Length)
-	for _, w := range n.Set.Weights {
-		grad := make([]float32, 0, n.Width)
-		for k, d := range w.D {
-			if k%n.Width == 0 && k != 0 {
-				gradients = append(gradients, grad)
-				grad = make([]float32, 0, n.Width)
-			}
-			grad = append(grad, d)
-		}
+		grad := make([]float32, len(input.Measures))
+		copy(grad, n.Input.D)
 		gradients = append(gradients, grad)
+		n.Set.Zero()
+		n.Others.Zero()
 	}
-
-	n.Set.Zero()
-	n.Others.Zero()
-
 	return gradients
 }
 
-// Iterate does a gradient descent operation
+// Iterate does a gradient descent operation. If the network has recovery enabled a nan or infinite cost
+// rolls the network back, see TryIterate. IterateBatch, IterateWithOptions, IterateContrastive,
+// IterateComposite, IterateLabeled, IteratePrivate, and Distill handle a nan or infinite cost the same way.
 func (n *Network) Iterate(data []float64) float32 {
+	total, _ := n.TryIterate(data)
+	return total
+}
+
+// IterateWithOptions does a gradient descent operation with the learning rate eta scaled by weight,
+// without changing the configuration of the network. The weight scales the step instead of the gradient,
+// which adam would normalize away, so a weight of 2 takes twice the step of a weight of 1.
+func (n *Network) IterateWithOptions(data []float64, eta, weight float32) float32 {
 	for i, measure := range data {
 		n.Input.X[i] = float32(measure)
 	}
 
-	start := time.Now()
-	// Calculate the gradients
-	total := tf32.Gradient(n.Cost).X[0]
-
-	// Update the point weights with the partial derivatives using adam
-	b1, b2 := n.pow(B1), n.pow(B2)
-	for j, w := range n.Set.Weights {
-		for k, d := range w.D {
-			g := d
-			m := B1*w.States[StateM][k] + (1-B1)*g
-			v := B2*w.States[StateV][k] + (1-B2)*g*g
-			w.States[StateM][k] = m
-			w.States[StateV][k] = v
-			mhat := m / (1 - b1)
-			vhat := v / (1 - b2)
-			n.Set.Weights[j].X[k] -= Eta * mhat / (float32(math.Sqrt(float64(vhat))) + 1e-8)
-		}
-	}
-
-	// Housekeeping
-	end := time.Since(start)
-	fmt.Println(n.I, total, end)
-	n.Set.Zero()
-	n.Others.Zero()
-	n.Points = append(n.Points, plotter.XY{X: float64(n.I), Y: float64(total)})
-	n.I++
+	total, _ := n.guard(func(step int) float32 {
+		return eta * weight
+	}, func() float32 {
+		// Calculate the gradients
+		return tf32.Gradient(n.Cost).X[0]
+	})
 
 	return total
 }
 
-func (n *Network) GetVectors(inputs []iris.Iris) []iris.Iris {
-	outputs := make([]iris.Iris, 0, len(inputs))
-	for i := 0; i < n.Length; i++ {
-		// Load the input
-		sample := inputs[i]
-		for i, measure := range sample.Measures {
-			n.Input.X[i] = float32(measure)
-		}
-		// Calculate the l1 output of the neural network
-		n.L1(func(a *tf32.V) bool {
-			vectors := make([]float64, len(a.X))
-			for i, x := range a.X {
-				vectors[i] = float64(x)
-			}
-			outputs = append(outputs, iris.Iris{
-				Measures: vectors,
-				Label:    sample.Label,
-			})
-			return true
-		})
+// zero zeros the partial derivatives of the weights, the inputs, and the classification head
+func (n *Network) zero() {
+	n.Set.Zero()
+	n.Others.Zero()
+	if n.Supervision != nil {
+		n.Supervision.Set.Zero()
 	}
-	return outputs
 }
 
-func (n *Network) GetVectors2(inputs []iris.Iris) []iris.Iris {
-	outputs := make([]iris.Iris, 0, len(inputs))
-	for i := 0; i < n.Length; i++ {
-		// Load the input
-		sample := inputs[i]
-		for i, measure := range sample.Measures {
-			n.Input.X[i] = float32(measure)
-		}
-		// Calculate the l1 output of the neural network
-		n.L2(func(a *tf32.V) bool {
-			vectors := make([]float64, len(a.X))
-			for i, x := range a.X {
-				vectors[i] = float64(x)
-			}
-			outputs = append(outputs, iris.Iris{
-				Measures: vectors,
-				Label:    sample.Label,
-			})
-			return true
-		})
-	}
-	return outputs
-}
+// step updates the weights with the learning rate eta and does the housekeeping
+func (n *Network) step(start time.Time, total, eta float32) {
+	n.Sensitivity.accumulate(n)
+	n.Trajectory.record(n)
+	n.clip()
 
-// Analyzer calculates properties of the network
-func (n *Network) Analyzer(in []iris.Iris) {
-	// For each input, label and sort the points in terms of distance to the input
-	type Point struct {
-		Index int
-		Rank  float32
-	}
-	type Input struct {
-		Points []Point
-		Label  string
-	}
-	type Node struct {
-		Nodes map[int]*Node
-		Ranks []float32
-		Label []string
+	// Update the point weights with the partial derivatives using adam
+	optimizer := n.Config.Optimizer
+	if optimizer == nil {
+		optimizer = NewAdam()
 	}
-	var build func(input Input, depth int, node *Node)
-	build = func(input Input, depth int, node *Node) {
-		length := n.Length
-		if depth >= length {
-			return
-		}
-		if node.Nodes == nil {
-			node.Nodes = make(map[int]*Node)
-		}
-		n := node.Nodes[input.Points[depth].Index]
-		if n == nil {
-			n = &Node{}
-		}
-		n.Ranks = append(n.Ranks, input.Points[depth].Rank)
-		if depth == length-1 {
-			n.Label = append(n.Label, input.Label)
-		}
-		node.Nodes[input.Points[depth].Index] = n
-		build(input, depth+1, n)
-	}
-	inputs := make([]Input, 0, n.Length)
-	vectors := n.GetVectors(in)
-	for i := 0; i < n.Length; i++ {
-		vector := vectors[i]
-		points := make([]Point, 0, n.Length)
-		for j, value := range vector.Measures {
-			points = append(points, Point{
-				Index: j,
-				Rank:  float32(value),
-			})
-		}
-		sort.Slice(points, func(i, j int) bool {
-			return points[i].Rank > points[j].Rank
-		})
-		inputs = append(inputs, Input{
-			Points: points,
-			Label:  vector.Label,
-		})
+	optimizer.Update(&n.Set, n.I, eta, n.Frozen)
+	if n.Supervision != nil && n.Supervision.labeled {
+		optimizer.Update(&n.Supervision.Set, n.I, eta, nil)
 	}
-	// Sort the inputs by the point indexes
-	sort.Slice(inputs, func(i, j int) bool {
-		index := 0
-		for inputs[i].Points[index].Index == inputs[j].Points[index].Index {
-			index++
-			if index == n.Length {
-				return false
-			}
-		}
-		return inputs[i].Points[index].Index < inputs[j].Points[index].Index
-	})
+	n.Snapshots.record(n)
 
-	node := &Node{}
-	for _, input := range inputs {
-		build(input, 0, node)
-	}
-	var translate func(node *Node, tree *[]*opts.TreeData)
-	tree := make([]*opts.TreeData, 0, 8)
-	translate = func(node *Node, tree *[]*opts.TreeData) {
-		if node == nil || tree == nil {
-			return
-		}
-		if len(node.Nodes) == 1 {
-			for i, n := range node.Nodes {
-				if n.Nodes == nil {
-					label := ""
-					for _, l := range n.Label {
-						label += fmt.Sprintf("%s-", l)
-					}
-					t := opts.TreeData{
-						Name: fmt.Sprintf("%d-%s", i, label),
-					}
-					*tree = append(*tree, &t)
-					break
-				}
-				translate(n, tree)
-			}
-			return
-		}
-		for i, n := range node.Nodes {
-			t := opts.TreeData{
-				Name:     fmt.Sprintf("%d", i),
-				Children: make([]*opts.TreeData, 0, 8),
-			}
-			translate(n, &t.Children)
-			*tree = append(*tree, &t)
-		}
-	}
-	translate(node, &tree)
-	t := []opts.TreeData{
-		{
-			Name:     "Root",
-			Children: tree,
-		},
-	}
-	graph := charts.NewTree()
-	graph.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{Width: "100%", Height: "500vh"}),
-		charts.WithTitleOpts(opts.Title{Title: "basic tree example"}),
-		charts.WithTooltipOpts(opts.Tooltip{Show: false}),
-	)
-	graph.AddSeries("tree", t).
-		SetSeriesOptions(
-			charts.WithTreeOpts(
-				opts.TreeChart{
-					Layout:           "orthogonal",
-					Orient:           "LR",
-					InitialTreeDepth: -1,
-					Leaves: &opts.TreeLeaves{
-						Label: &opts.Label{Show: true, Position: "right", Color: "Black"},
-					},
-				},
-			),
-			charts.WithLabelOpts(opts.Label{Show: true, Position: "top", Color: "Black"}),
-		)
-	page := components.NewPage()
-	page.AddCharts(
-		graph,
-	)
-	f, err := os.Create("tree.html")
-	if err != nil {
-		panic(err)
-
-	}
-	page.Render(io.MultiWriter(f))
-
-	// Count how many inputs have the same label as their nearest neighbor
-	same := 0
-	for i, label := range inputs {
-		min, index := math.MaxInt, 0
-		for j, l := range inputs {
-			if i == j {
-				continue
-			}
-			a, b := make([]int, 0, 8), make([]int, 0, 8)
-			for k, value := range label.Points {
-				a = append(a, value.Index)
-				b = append(b, l.Points[k].Index)
-			}
-			total := levenshtein.ComputeDistance(a, b)
-			if total < min {
-				min, index = total, j
-			}
-		}
-		for _, rank := range label.Points[:18] {
-			fmt.Printf("%03d ", rank.Index)
-		}
-		fmt.Println(label.Label, inputs[index].Label)
-		if label.Label == inputs[index].Label {
-			same++
-		}
+	// Housekeeping
+	end := time.Since(start)
+	n.zero()
+	if n.OnStep != nil {
+		n.OnStep(n.I, total, end)
 	}
-	fmt.Println(same, n.Length, float64(same)/float64(n.Length))
+	n.I++
+	n.anneal()
+}
 
-	type Point64 struct {
-		Index int
-		Rank  float64
-	}
-	g := pagerank.NewGraph64()
-	for i, vector := range vectors {
-		for j, weight := range vector.Measures {
-			g.Link(uint64(i), uint64(j), weight)
+// GetVectors returns the l1 attention of the network for each input
+func (n *Network) GetVectors(inputs []Sample) []Sample {
+	outputs := make([]Sample, 0, len(inputs))
+	for i, attention := range n.Project(inputs, LayerL1) {
+		vectors := make([]float64, len(attention))
+		for j, x := range attention {
+			vectors[j] = float64(x)
 		}
+		outputs = append(outputs, Sample{
+			Measures: vectors,
+			Label:    inputs[i].Label,
+		})
 	}
-	ranks := make([]Point64, n.Length)
-	g.Rank(0.85, 0.000001, func(node uint64, rank float64) {
-		ranks[node].Rank = rank
-		ranks[node].Index = int(node)
-	})
-	sort.Slice(ranks, func(i, j int) bool {
-		return ranks[i].Rank > ranks[j].Rank
-	})
-	for _, rank := range ranks {
-		fmt.Printf("%03d %.16f\n", rank.Index, rank.Rank)
-	}
+	return outputs
 }
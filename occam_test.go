@@ -0,0 +1,180 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pointlander/gradient/tf32"
+	"github.com/pointlander/gradient/tf64"
+)
+
+// testNetwork returns a small network with the points set to the samples
+func testNetwork(samples [][]float64, options ...Option) *Network {
+	width := len(samples[0])
+	n := NewNetwork(width, len(samples), options...)
+	for i, sample := range samples {
+		for j, measure := range sample {
+			n.Point.X[i*width+j] = float32(measure)
+		}
+	}
+	return n
+}
+
+// spherical runs the spherical softmax on the rows and returns the output and the gradient of the cost
+// sum(weights * output) with respect to the rows
+func spherical(rows []float32, width int, weights []float32, epsilon, t float32) (output, gradient []float32) {
+	a := tf32.NewV(width, len(rows)/width)
+	a.X = append(a.X, rows...)
+	a.D = make([]float32, len(rows))
+	options := map[string]interface{}{
+		"epsilon":     &epsilon,
+		"temperature": &t,
+	}
+	SphericalSoftmax(func(c *tf32.V) bool {
+		output = append(output, c.X...)
+		copy(c.D, weights)
+		return false
+	}, 0, &a, options)
+	return output, a.D
+}
+
+func TestSphericalSoftmax(t *testing.T) {
+	const width = 4
+	weights := []float32{.3, -1.2, .7, 2, -.4, .9, 1.5, -.8}
+	cases := []struct {
+		name        string
+		rows        []float32
+		epsilon     float32
+		temperature float32
+	}{
+		{"plain", []float32{.5, -1, 2, .25, 1, 1.5, -.5, .75}, 0, 1},
+		{"epsilon", []float32{.5, -1, 2, .25, 1, 1.5, -.5, .75}, .1, 1},
+		{"temperature", []float32{.5, -1, 2, .25, 1, 1.5, -.5, .75}, .01, 2},
+		{"near zero", []float32{1e-3, -2e-3, 1.5e-3, 5e-4, 2e-3, -1e-3, 3e-3, 1e-3}, 0, 1},
+		{"near zero epsilon", []float32{1e-3, -2e-3, 1.5e-3, 5e-4, 2e-3, -1e-3, 3e-3, 1e-3}, 1e-6, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			output, gradient := spherical(c.rows, width, weights, c.epsilon, c.temperature)
+
+			// The forward pass is the squared values plus epsilon normalized over each row
+			for row := 0; row < len(c.rows)/width; row++ {
+				sum, values := 0.0, make([]float64, width)
+				for j := range values {
+					x := float64(c.rows[row*width+j]) / float64(c.temperature)
+					values[j] = x*x + float64(c.epsilon)
+					sum += values[j]
+				}
+				for j, value := range values {
+					if got, want := float64(output[row*width+j]), value/sum; math.Abs(got-want) > 1e-5 {
+						t.Errorf("output %d of row %d is %f but should be %f", j, row, got, want)
+					}
+				}
+			}
+
+			// The backward pass matches the central finite difference of the cost
+			cost := func(rows []float32) float64 {
+				output, _ := spherical(rows, width, weights, c.epsilon, c.temperature)
+				sum := 0.0
+				for i, value := range output {
+					sum += float64(weights[i]) * float64(value)
+				}
+				return sum
+			}
+			for i := range c.rows {
+				h := float32(math.Max(1e-2*math.Abs(float64(c.rows[i])), 1e-6))
+				rows := append([]float32(nil), c.rows...)
+				up, down := c.rows[i]+h, c.rows[i]-h
+				rows[i] = up
+				plus := cost(rows)
+				rows[i] = down
+				minus := cost(rows)
+				want := (plus - minus) / (float64(up) - float64(down))
+				got := float64(gradient[i])
+				if math.IsNaN(got) || math.Abs(got-want) > 1e-2*math.Max(1, math.Abs(want)) {
+					t.Errorf("gradient %d is %f but should be %f", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSphericalSoftmaxZero(t *testing.T) {
+	const width = 4
+	weights := []float32{1, 2, 3, 4}
+	for _, epsilon := range []float32{0, 1e-3} {
+		output, gradient := spherical(make([]float32, width), width, weights, epsilon, 1)
+		for i, value := range output {
+			if math.Abs(float64(value)-1.0/width) > 1e-6 {
+				t.Errorf("output %d for epsilon %g is %f but should be uniform", i, epsilon, value)
+			}
+		}
+		for i, value := range gradient {
+			if value != 0 {
+				t.Errorf("gradient %d for epsilon %g is %f but should be zero", i, epsilon, value)
+			}
+		}
+	}
+}
+
+func TestIterateWithOptionsWeight(t *testing.T) {
+	samples := [][]float64{{1, 0, .5}, {0, 1, .25}, {.5, .5, 1}}
+	steps := make([][]float32, 0, 2)
+	for _, weight := range []float32{1, 2} {
+		n := testNetwork(samples)
+		points := append([]float32(nil), n.Point.X...)
+		n.IterateWithOptions([]float64{.3, .6, .9}, .01, weight)
+		step := make([]float32, len(points))
+		for i, x := range n.Point.X {
+			step[i] = x - points[i]
+		}
+		steps = append(steps, step)
+	}
+	moved := false
+	for i, single := range steps[0] {
+		if single == 0 {
+			continue
+		}
+		moved = true
+		if ratio := steps[1][i] / single; math.Abs(float64(ratio)-2) > 1e-3 {
+			t.Errorf("the step of point %d with a weight of 2 is %f times the step with a weight of 1", i, ratio)
+		}
+	}
+	if !moved {
+		t.Fatal("the points didn't move")
+	}
+}
+
+func TestSoftmaxRows(t *testing.T) {
+	a := tf32.NewV(2, 2)
+	a.X = append(a.X, 1000, 1001, 0, 1)
+	a.D = make([]float32, len(a.X))
+	Softmax(func(c *tf32.V) bool {
+		want := float32(1 / (1 + math.E))
+		for row := 0; row < 2; row++ {
+			if got := c.X[row*2]; math.IsNaN(float64(got)) || math.Abs(float64(got-want)) > 1e-6 {
+				t.Errorf("output of row %d is %f but should be %f", row, got, want)
+			}
+		}
+		return true
+	}, 0, &a)
+}
+
+func TestSoftmax64Rows(t *testing.T) {
+	a := tf64.NewV(2, 2)
+	a.X = append(a.X, 1000, 1001, 0, 1)
+	a.D = make([]float64, len(a.X))
+	Softmax64(func(c *tf64.V) bool {
+		want := 1 / (1 + math.E)
+		for row := 0; row < 2; row++ {
+			if got := c.X[row*2]; math.IsNaN(got) || math.Abs(got-want) > 1e-12 {
+				t.Errorf("output of row %d is %f but should be %f", row, got, want)
+			}
+		}
+		return true
+	}, 0, &a)
+}
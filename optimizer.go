@@ -0,0 +1,143 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Optimizer updates the weights of a set with their partial derivatives
+type Optimizer interface {
+	// Update updates the weights at iteration i with learning rate eta, skipping the frozen rows
+	Update(set *tf32.Set, i int, eta float32, frozen map[string][]bool)
+}
+
+// Adam is the adam optimizer
+// https://arxiv.org/abs/1412.6980
+type Adam struct {
+	// B1 is the exponential decay rate of the first moment estimates
+	B1 float32
+	// B2 is the exponential decay rate of the second moment estimates
+	B2 float32
+	// Epsilon prevents division by zero
+	Epsilon float32
+}
+
+// NewAdam creates a new adam optimizer with the default decay rates
+func NewAdam() Adam {
+	return Adam{
+		B1:      B1,
+		B2:      B2,
+		Epsilon: 1e-8,
+	}
+}
+
+// Update updates the weights with the partial derivatives using adam, skipping the frozen rows
+func (a Adam) Update(set *tf32.Set, i int, eta float32, frozen map[string][]bool) {
+	a.update(set, i, eta, 0, frozen)
+}
+
+// update updates the weights using adam with the weights decayed by decay
+func (a Adam) update(set *tf32.Set, i int, eta, decay float32, frozen map[string][]bool) {
+	b1, b2 := pow(a.B1, i), pow(a.B2, i)
+	for j, w := range set.Weights {
+		rows := frozen[w.N]
+		for k, d := range w.D {
+			if rows != nil && rows[k/w.S[0]] {
+				continue
+			}
+			g := d
+			m := a.B1*w.States[StateM][k] + (1-a.B1)*g
+			v := a.B2*w.States[StateV][k] + (1-a.B2)*g*g
+			w.States[StateM][k] = m
+			w.States[StateV][k] = v
+			mhat := m / (1 - b1)
+			vhat := v / (1 - b2)
+			set.Weights[j].X[k] -= eta * (mhat/(float32(math.Sqrt(float64(vhat)))+a.Epsilon) + decay*w.X[k])
+		}
+	}
+}
+
+// AdamW is adam with decoupled weight decay
+// https://arxiv.org/abs/1711.05101
+type AdamW struct {
+	Adam
+	// WeightDecay is the rate at which the weights decay towards zero
+	WeightDecay float32
+}
+
+// NewAdamW creates a new adamw optimizer with the default decay rates and weight decay
+func NewAdamW(decay float32) AdamW {
+	return AdamW{
+		Adam:        NewAdam(),
+		WeightDecay: decay,
+	}
+}
+
+// Update updates the weights with the partial derivatives using adamw, skipping the frozen rows
+func (a AdamW) Update(set *tf32.Set, i int, eta float32, frozen map[string][]bool) {
+	a.update(set, i, eta, a.WeightDecay, frozen)
+}
+
+// SGD is stochastic gradient descent with momentum
+type SGD struct {
+	// Momentum is the fraction of the previous update added to the current update
+	Momentum float32
+}
+
+// NewSGD creates a new stochastic gradient descent optimizer with momentum
+func NewSGD(momentum float32) SGD {
+	return SGD{
+		Momentum: momentum,
+	}
+}
+
+// Update updates the weights with the partial derivatives using momentum, skipping the frozen rows
+func (s SGD) Update(set *tf32.Set, i int, eta float32, frozen map[string][]bool) {
+	for _, w := range set.Weights {
+		rows := frozen[w.N]
+		for k, d := range w.D {
+			if rows != nil && rows[k/w.S[0]] {
+				continue
+			}
+			v := s.Momentum*w.States[StateM][k] + d
+			w.States[StateM][k] = v
+			w.X[k] -= eta * v
+		}
+	}
+}
+
+// RMSProp divides the learning rate by a running average of the magnitude of the partial derivatives
+type RMSProp struct {
+	// Decay is the exponential decay rate of the running average
+	Decay float32
+	// Epsilon prevents division by zero
+	Epsilon float32
+}
+
+// NewRMSProp creates a new rmsprop optimizer with the default decay rate
+func NewRMSProp() RMSProp {
+	return RMSProp{
+		Decay:   .9,
+		Epsilon: 1e-8,
+	}
+}
+
+// Update updates the weights with the partial derivatives using rmsprop, skipping the frozen rows
+func (r RMSProp) Update(set *tf32.Set, i int, eta float32, frozen map[string][]bool) {
+	for _, w := range set.Weights {
+		rows := frozen[w.N]
+		for k, d := range w.D {
+			if rows != nil && rows[k/w.S[0]] {
+				continue
+			}
+			v := r.Decay*w.States[StateV][k] + (1-r.Decay)*d*d
+			w.States[StateV][k] = v
+			w.X[k] -= eta * d / (float32(math.Sqrt(float64(v))) + r.Epsilon)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// SoftmaxFunc is a softmax function used for the attention
+type SoftmaxFunc func(k tf32.Continuation, node int, a *tf32.V, options ...map[string]interface{}) bool
+
+// Option is an option for creating a network
+type Option func(n *Network)
+
+// WithLearningRate sets the learning rate
+func WithLearningRate(eta float32) Option {
+	return func(n *Network) {
+		n.Config.Eta = eta
+	}
+}
+
+// WithSoftmax sets the softmax function used for the attention, overriding the activation
+func WithSoftmax(softmax SoftmaxFunc) Option {
+	return func(n *Network) {
+		n.Config.Softmax = softmax
+	}
+}
+
+// WithParallelism sets the number of workers the softmax splits its values between
+func WithParallelism(n int) Option {
+	return func(network *Network) {
+		network.Config.Parallelism = n
+	}
+}
+
+// WithBackend sets the backend that multiplies the matrices of the attention, for example BLAS{}
+func WithBackend(backend Backend) Option {
+	return func(n *Network) {
+		n.Config.Backend = backend
+	}
+}
+
+// WithSeed seeds the random number generator used to initialize and train the network
+func WithSeed(seed int64) Option {
+	return func(n *Network) {
+		n.SetSeed(seed)
+	}
+}
+
+// WithTemperature sets the temperature that divides the attention logits before the softmax
+func WithTemperature(temperature float32) Option {
+	return func(n *Network) {
+		n.Config.Temperature = temperature
+	}
+}
+
+// WithAnnealing sets the schedule that anneals the temperature from its initial value, for example
+// Cosine{Steps: steps, Min: .1} sharpens the attention over training
+func WithAnnealing(annealing Schedule) Option {
+	return func(n *Network) {
+		n.Config.Annealing = annealing
+	}
+}
+
+// WithSchedule sets the learning rate schedule
+func WithSchedule(schedule Schedule) Option {
+	return func(n *Network) {
+		n.Config.Schedule = schedule
+	}
+}
+
+// WithOptimizer sets the optimizer used to update the weights
+func WithOptimizer(optimizer Optimizer) Option {
+	return func(n *Network) {
+		n.Config.Optimizer = optimizer
+	}
+}
+
+// WithCost sets the cost the network is trained on, overriding the objective
+func WithCost(cost CostFunc) Option {
+	return func(n *Network) {
+		n.Config.Cost = cost
+	}
+}
+
+// WithBias adds a learned bias for each point to the attention scores of each block
+func WithBias() Option {
+	return func(n *Network) {
+		n.Config.Bias = true
+	}
+}
+
+// WithDepth sets the number of attention blocks stacked before the cost
+func WithDepth(depth int) Option {
+	return func(n *Network) {
+		n.Config.Layers = depth
+	}
+}
+
+// WithResidual adds the input of each block to its output followed by layer normalization
+func WithResidual() Option {
+	return func(n *Network) {
+		n.Config.Residual = true
+	}
+}
+
+// WithRecovery rolls the network back to a checkpoint taken every interval iterations and multiplies the
+// learning rate by decay when the cost isn't finite, giving up after retries rollbacks
+func WithRecovery(interval, retries int, decay float32) Option {
+	return func(n *Network) {
+		n.Recover(interval, retries, decay)
+	}
+}
+
+// WithInitializer sets the initializer of the points of each layer, for example Xavier{} or
+// KMeansPlusPlus{Samples: samples}
+func WithInitializer(initializer Initializer) Option {
+	return func(n *Network) {
+		n.Config.Initializer = initializer
+	}
+}
+
+// WithClipNorm scales the gradients of each step down to the global norm if their norm is greater
+func WithClipNorm(norm float32) Option {
+	return func(n *Network) {
+		n.Config.ClipNorm = norm
+	}
+}
+
+// WithClipValue clips each partial derivative of each step to plus or minus the value
+func WithClipValue(value float32) Option {
+	return func(n *Network) {
+		n.Config.ClipValue = value
+	}
+}
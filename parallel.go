@@ -0,0 +1,112 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// minParallel is the minimum number of values that are split between workers
+const minParallel = 4096
+
+// parallelism returns the number of workers pointed to by the parallelism option, the number of cpus
+// if the option isn't set or is less than 1
+func parallelism(options []map[string]interface{}) int {
+	if len(options) > 0 {
+		if p, ok := options[0]["parallelism"].(*int); ok && *p > 0 {
+			return *p
+		}
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// partition calls f concurrently on up to workers contiguous ranges of the size values. Small
+// sizes are processed by the calling goroutine.
+func partition(size, workers int, f func(begin, end int)) {
+	if workers > size/minParallel {
+		workers = size / minParallel
+	}
+	if workers < 2 {
+		f(0, size)
+		return
+	}
+	var wait sync.WaitGroup
+	step := (size + workers - 1) / workers
+	for begin := 0; begin < size; begin += step {
+		end := begin + step
+		if end > size {
+			end = size
+		}
+		wait.Add(1)
+		go func(begin, end int) {
+			defer wait.Done()
+			f(begin, end)
+		}(begin, end)
+	}
+	wait.Wait()
+}
+
+// clone returns a network with its own graph and input buffer that shares the weights of the network,
+// so the clone can be evaluated concurrently with other clones as long as the weights aren't updated
+func (n *Network) clone() *Network {
+	c := NewNetworkWithConfig(n.Width, n.Length, n.Config)
+	for _, w := range c.Set.Weights {
+		if v := n.Set.ByName[w.N]; v != nil && len(v.X) == len(w.X) {
+			w.X = v.X
+		}
+	}
+	for _, w := range c.Others.Weights {
+		if w.N == "input" {
+			continue
+		}
+		if v := n.Others.ByName[w.N]; v != nil && len(v.X) == len(w.X) {
+			w.X = v.X
+		}
+	}
+	c.Position.Begin, c.Position.End = n.Position.Begin, n.Position.End
+	return c
+}
+
+// GetEntropyParallel returns the entropy of the network like GetEntropy, but the inputs are evaluated
+// by workers concurrently. If workers is less than 1 the number of cpus is used. The outputs are in
+// the same order as the inputs.
+func (n *Network) GetEntropyParallel(inputs []Sample, workers int) []Entropy {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	outputs := make([]Entropy, len(inputs))
+	indexes := make(chan int, workers)
+	var wait sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wait.Add(1)
+		go func(c *Network) {
+			defer wait.Done()
+			for i := range indexes {
+				sample := inputs[i]
+				for j, measure := range sample.Measures {
+					c.Input.X[j] = float32(measure)
+				}
+				c.Cost(func(a *tf32.V) bool {
+					outputs[i] = Entropy{
+						Entropy:  a.X[0],
+						Label:    sample.Label,
+						Measures: sample.Measures,
+						Index:    i,
+					}
+					return true
+				})
+			}
+		}(n.clone())
+	}
+	for i := range inputs {
+		indexes <- i
+	}
+	close(indexes)
+	wait.Wait()
+	return rank(outputs)
+}
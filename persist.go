@@ -0,0 +1,158 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// savedWeights are the values and optimizer states of a saved weight matrix
+type savedWeights struct {
+	Name   string
+	Shape  []int
+	Values []float32
+	States [][]float32
+}
+
+// savedNetwork is the saved state of a network
+type savedNetwork struct {
+	Width   int
+	Length  int
+	Config  NetworkConfig
+	I       int
+	Rand    RandState
+	Weights []savedWeights
+	Points  []XY
+	// Normalization is the normalization of the inputs
+	Normalization Normalization
+}
+
+// Save saves the configuration, the weights and their optimizer states, the iteration, the random number
+// generator state, and the cost history of the network, so training can be resumed with LoadNetwork
+func (n *Network) Save(path string) error {
+	saved := savedNetwork{
+		Width:  n.Width,
+		Length: n.Length,
+		Config: n.Config,
+		I:      n.I,
+		Rand:   n.RandState(),
+		Points: n.Points,
+	}
+	saved.Normalization = n.Normalization
+	// Functions, optimizers, and schedules can't be saved
+	saved.Config.Softmax, saved.Config.Optimizer, saved.Config.Schedule = nil, nil, nil
+	saved.Config.Annealing, saved.Config.Backend, saved.Config.Cost = nil, nil, nil
+	saved.Config.Initializer = nil
+	for _, w := range n.Set.Weights {
+		saved.Weights = append(saved.Weights, savedWeights{
+			Name:   w.N,
+			Shape:  w.S,
+			Values: w.X,
+			States: w.States,
+		})
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	writer := bufio.NewWriter(out)
+	err = gob.NewEncoder(writer).Encode(&saved)
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// LoadNetwork loads a network saved by Save. The custom softmax function, the cost, the optimizer, and the
+// learning rate schedule aren't saved, so they have to be given again as options. The weights saved by
+// Set.Save or SaveCheckpoint can also be loaded, then the dimensions, the number of blocks, the bias, and the
+// learned positional encoding are read from the weights and the rest of the configuration is the default.
+func LoadNetwork(path string, options ...Option) (*Network, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	var saved savedNetwork
+	err = gob.NewDecoder(bufio.NewReader(in)).Decode(&saved)
+	if err != nil {
+		n, e := loadSet(path, options...)
+		if e != nil {
+			return nil, fmt.Errorf("%s isn't a saved network or a set of weights: %w", path, err)
+		}
+		return n, nil
+	}
+
+	n := NewNetworkWithConfig(saved.Width, saved.Length, saved.Config, options...)
+	for _, w := range saved.Weights {
+		v := n.Set.ByName[w.Name]
+		if v == nil {
+			continue
+		}
+		if len(v.X) != len(w.Values) {
+			return nil, fmt.Errorf("size of %s is %d but should be %d", w.Name, len(w.Values), len(v.X))
+		}
+		copy(v.X, w.Values)
+		for i := range v.States {
+			if i < len(w.States) {
+				copy(v.States[i], w.States[i])
+			}
+		}
+	}
+	n.SetRandState(saved.Rand)
+	n.I = saved.I
+	n.anneal()
+	n.Points = append(n.Points, saved.Points...)
+	n.Normalization = saved.Normalization
+	return n, nil
+}
+
+// loadSet creates a network from a set of weights with the configuration read from the weights
+func loadSet(path string, options ...Option) (*Network, error) {
+	set := tf32.NewSet()
+	_, iteration, err := set.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	points := set.ByName["points"]
+	if points == nil {
+		return nil, errors.New("set doesn't have points")
+	}
+	config := DefaultNetworkConfig()
+	for set.ByName[fmt.Sprintf("points%d", config.Layers)] != nil {
+		config.Layers++
+	}
+	config.Bias = set.ByName["biases"] != nil
+	if positions := set.ByName["positions"]; positions != nil {
+		config.Encoding, config.Positions = EncodingLearned, positions.S[1]
+	}
+	n := NewNetworkWithConfig(points.S[0], points.S[1], config, options...)
+	for _, w := range set.Weights {
+		v := n.Set.ByName[w.N]
+		if v == nil {
+			continue
+		}
+		if len(v.X) != len(w.X) {
+			return nil, fmt.Errorf("size of %s is %d but should be %d", w.N, len(w.X), len(v.X))
+		}
+		copy(v.X, w.X)
+		for i := range v.States {
+			if i < len(w.States) {
+				copy(v.States[i], w.States[i])
+			}
+		}
+	}
+	n.I = iteration
+	n.anneal()
+	return n, nil
+}
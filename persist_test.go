@@ -0,0 +1,84 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// trained returns a network with a bias and two blocks trained on the recovery samples
+func trained() *Network {
+	n := testNetwork(recoverySamples, WithBias(), WithDepth(2))
+	for i := 0; i < 8; i++ {
+		n.Iterate(recoverySamples[i%len(recoverySamples)])
+	}
+	return n
+}
+
+// equivalent checks that the weights, the step, and the entropy of the networks are the same
+func equivalent(t *testing.T, a, b *Network) {
+	t.Helper()
+	if a.I != b.I {
+		t.Errorf("the step is %d but should be %d", b.I, a.I)
+	}
+	if len(a.Set.Weights) != len(b.Set.Weights) {
+		t.Fatalf("there are %d weights but should be %d", len(b.Set.Weights), len(a.Set.Weights))
+	}
+	for _, w := range a.Set.Weights {
+		v := b.Set.ByName[w.N]
+		if v == nil {
+			t.Fatalf("%s wasn't loaded", w.N)
+		}
+		for j, x := range w.X {
+			if v.X[j] != x {
+				t.Fatalf("weight %d of %s is %f but should be %f", j, w.N, v.X[j], x)
+			}
+		}
+	}
+	samples := make([]Sample, len(recoverySamples))
+	for i, measures := range recoverySamples {
+		samples[i] = Sample{Measures: measures}
+	}
+	want, got := a.GetEntropy(samples), b.GetEntropy(samples)
+	for i := range want {
+		if got[i].Entropy != want[i].Entropy || got[i].Index != want[i].Index {
+			t.Errorf("entropy %d is %f of %d but should be %f of %d",
+				i, got[i].Entropy, got[i].Index, want[i].Entropy, want[i].Index)
+		}
+	}
+}
+
+func TestSaveLoadNetwork(t *testing.T) {
+	n := trained()
+	path := filepath.Join(t.TempDir(), "network.gob")
+	if err := n.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadNetwork(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Config.Layers != 2 || !loaded.Config.Bias {
+		t.Errorf("the loaded network has %d layers and bias %t", loaded.Config.Layers, loaded.Config.Bias)
+	}
+	equivalent(t, n, loaded)
+}
+
+func TestSaveLoadCheckpoint(t *testing.T) {
+	n := trained()
+	path := filepath.Join(t.TempDir(), "checkpoint.w")
+	if err := n.SaveCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := testNetwork(recoverySamples, WithBias(), WithDepth(2))
+	if err := loaded.LoadCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	equivalent(t, n, loaded)
+	if loaded.RandState() != n.RandState() {
+		t.Errorf("the random state is %v but should be %v", loaded.RandState(), n.RandState())
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Encoding is a type of positional encoding
+type Encoding int
+
+const (
+	// EncodingNone is no positional encoding
+	EncodingNone Encoding = iota
+	// EncodingSinusoidal is the sinusoidal positional encoding from attention is all you need
+	EncodingSinusoidal
+	// EncodingLearned is a positional encoding that is trained with the points
+	EncodingLearned
+)
+
+// Position is the state of the positional encoding
+type Position struct {
+	Encoding  Encoding
+	Positions *tf32.V
+	Begin     int
+	End       int
+	Options   map[string]interface{}
+}
+
+// encode adds the positional encoding to the input
+func (n *Network) encode(input tf32.Meta, encoding Encoding, positions int) tf32.Meta {
+	n.Position.Encoding = encoding
+	if encoding == EncodingNone {
+		return input
+	}
+	n.Position.Begin, n.Position.End = 0, n.Width
+	n.Position.Options = map[string]interface{}{
+		"begin": &n.Position.Begin,
+		"end":   &n.Position.End,
+	}
+	switch encoding {
+	case EncodingSinusoidal:
+		n.Others.Add("positions", n.Width, positions)
+		p := n.Others.ByName["positions"]
+		for pos := 0; pos < positions; pos++ {
+			for i := 0; i < n.Width; i++ {
+				angle := float64(pos) / math.Pow(10000, float64(2*(i/2))/float64(n.Width))
+				if i%2 == 0 {
+					p.X = append(p.X, float32(math.Sin(angle)))
+				} else {
+					p.X = append(p.X, float32(math.Cos(angle)))
+				}
+			}
+		}
+		n.Position.Positions = p
+		return tf32.Add(input, tf32.Slice(n.Others.Get("positions"), n.Position.Options))
+	case EncodingLearned:
+		n.Set.Add("positions", n.Width, positions)
+		p := n.Set.ByName["positions"]
+		for i := 0; i < cap(p.X); i++ {
+			p.X = append(p.X, float32(n.Rnd.NormFloat64()*.01))
+		}
+		p.States = make([][]float32, StateTotal)
+		for i := range p.States {
+			p.States[i] = make([]float32, len(p.X))
+		}
+		n.Position.Positions = p
+		return tf32.Add(input, tf32.Slice(n.Set.Get("positions"), n.Position.Options))
+	}
+	panic("unknown positional encoding")
+}
+
+// SetPosition sets the position of the next input
+func (n *Network) SetPosition(position int) {
+	if n.Position.Encoding == EncodingNone {
+		return
+	}
+	n.Position.Begin = position * n.Width
+	n.Position.End = n.Position.Begin + n.Width
+}
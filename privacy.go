@@ -0,0 +1,99 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Privacy are the parameters of differentially private training and its privacy accountant
+type Privacy struct {
+	// Clip is the maximum l2 norm of the gradient of each sample
+	Clip float64
+	// Noise is the standard deviation of the gaussian noise as a multiple of Clip
+	Noise float64
+	// Delta is the delta of the (epsilon, delta) guarantee
+	Delta float64
+	// Steps is the number of private steps taken
+	Steps int
+}
+
+// NewPrivacy creates new differential privacy parameters
+func NewPrivacy(clip, noise, delta float64) *Privacy {
+	return &Privacy{
+		Clip:  clip,
+		Noise: noise,
+		Delta: delta,
+	}
+}
+
+// Epsilon returns the epsilon spent so far for the delta. The renyi differential privacy of the
+// composed gaussian mechanism is converted to (epsilon, delta), without any amplification by
+// subsampling, so the bound is conservative.
+func (p *Privacy) Epsilon() float64 {
+	if p.Steps == 0 {
+		return 0
+	}
+	epsilon := math.Inf(1)
+	for alpha := 1.25; alpha <= 256; alpha *= 1.25 {
+		rdp := float64(p.Steps) * alpha / (2 * p.Noise * p.Noise)
+		e := rdp + math.Log(1/p.Delta)/(alpha-1)
+		if e < epsilon {
+			epsilon = e
+		}
+	}
+	return epsilon
+}
+
+// IteratePrivate takes a differentially private step on the batch: the gradient of each sample is
+// clipped to the clip norm, the gradients are summed, gaussian noise is added, and the result is
+// averaged over the batch. A nan or infinite cost is handled like TryIterate. Each attempt at the step
+// is counted by the accountant, including the attempts that are rolled back.
+func (n *Network) IteratePrivate(batch [][]float64, p *Privacy) (float32, error) {
+	return n.guard(n.Config.rate, func() float32 {
+		sums := make([][]float32, len(n.Set.Weights))
+		for i, w := range n.Set.Weights {
+			sums[i] = make([]float32, len(w.D))
+		}
+
+		total := float32(0.0)
+		for _, data := range batch {
+			for i, measure := range data {
+				n.Input.X[i] = float32(measure)
+			}
+			total += tf32.Gradient(n.Cost).X[0]
+
+			norm := 0.0
+			for _, w := range n.Set.Weights {
+				for _, d := range w.D {
+					norm += float64(d * d)
+				}
+			}
+			scale := float32(1.0)
+			if norm = math.Sqrt(norm); norm > p.Clip {
+				scale = float32(p.Clip / norm)
+			}
+			for i, w := range n.Set.Weights {
+				for j, d := range w.D {
+					sums[i][j] += scale * d
+				}
+			}
+			n.Set.Zero()
+			n.Others.Zero()
+		}
+
+		size := float32(len(batch))
+		for i, w := range n.Set.Weights {
+			for j := range w.D {
+				noise := float32(n.Rnd.NormFloat64() * p.Noise * p.Clip)
+				w.D[j] = (sums[i][j] + noise) / size
+			}
+		}
+		p.Steps++
+		return total / size
+	})
+}
@@ -0,0 +1,254 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Projection is a projection onto the first principal components of a set of rows
+type Projection struct {
+	// Mean is the mean of the rows, which is subtracted before projecting
+	Mean []float64
+	// Components are the principal components, a column for each component
+	Components *mat.Dense
+}
+
+// NewProjection computes the projection onto the first k principal components of the rows. Fewer
+// components are kept if the rows have fewer dimensions or there are fewer rows.
+func NewProjection(rows [][]float64, k int) (*Projection, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("no rows to project")
+	}
+	width := len(rows[0])
+	data := mat.NewDense(len(rows), width, nil)
+	for i, row := range rows {
+		data.SetRow(i, row)
+	}
+	var pc stat.PC
+	if ok := pc.PrincipalComponents(data, nil); !ok {
+		return nil, errors.New("principal components analysis failed")
+	}
+	var vectors mat.Dense
+	pc.VectorsTo(&vectors)
+	if _, c := vectors.Dims(); k > c {
+		k = c
+	}
+	p := Projection{
+		Mean:       make([]float64, width),
+		Components: mat.DenseCopyOf(vectors.Slice(0, width, 0, k)),
+	}
+	for _, row := range rows {
+		for j, value := range row {
+			p.Mean[j] += value
+		}
+	}
+	for j := range p.Mean {
+		p.Mean[j] /= float64(len(rows))
+	}
+	return &p, nil
+}
+
+// Project projects the rows onto the principal components
+func (p *Projection) Project(rows [][]float64) [][]float64 {
+	width, k := p.Components.Dims()
+	projected := make([][]float64, len(rows))
+	for i, row := range rows {
+		projected[i] = make([]float64, k)
+		for c := 0; c < k; c++ {
+			sum := 0.0
+			for j := 0; j < width && j < len(row); j++ {
+				sum += (row[j] - p.Mean[j]) * p.Components.At(j, c)
+			}
+			projected[i][c] = sum
+		}
+	}
+	return projected
+}
+
+// Principal projects the rows onto their first k principal components
+func Principal(rows [][]float64, k int) ([][]float64, error) {
+	p, err := NewProjection(rows, k)
+	if err != nil {
+		return nil, err
+	}
+	return p.Project(rows), nil
+}
+
+// points returns the rows of the points of the first block of the network
+func (n *Network) points() [][]float64 {
+	rows := make([][]float64, n.Length)
+	for i := range rows {
+		rows[i] = make([]float64, n.Width)
+		for j, value := range n.Point.X[i*n.Width : (i+1)*n.Width] {
+			rows[i][j] = float64(value)
+		}
+	}
+	return rows
+}
+
+// PCA projects the points of the network onto their first k principal components, a row for each point
+func PCA(n *Network, k int) ([][]float64, error) {
+	return Principal(n.points(), k)
+}
+
+// ProjectSamples projects the samples onto the first k principal components of the points of the network,
+// so they can be plotted together with the projection of the points returned by PCA
+func ProjectSamples(n *Network, samples [][]float64, k int) ([][]float64, error) {
+	p, err := NewProjection(n.points(), k)
+	if err != nil {
+		return nil, err
+	}
+	return p.Project(samples), nil
+}
+
+// Embed computes a neighbor embedding of the rows in dimensions dimensions with t-SNE, which keeps rows that
+// are neighbors close together. perplexity is the effective number of neighbors of each row and iterations
+// is the number of gradient descent steps. The exact gradient is computed, so the cost is quadratic in the
+// number of rows.
+// https://www.jmlr.org/papers/v9/vandermaaten08a.html
+func Embed(rnd *rand.Rand, rows [][]float64, dimensions int, perplexity float64, iterations int) [][]float64 {
+	size := len(rows)
+	distances := make([][]float64, size)
+	for i := range distances {
+		distances[i] = make([]float64, size)
+		for j := range distances[i] {
+			sum := 0.0
+			for k, value := range rows[i] {
+				difference := value - rows[j][k]
+				sum += difference * difference
+			}
+			distances[i][j] = sum
+		}
+	}
+
+	// The conditional probabilities of the neighbors of each row have the target perplexity
+	p := make([][]float64, size)
+	entropy := math.Log(perplexity)
+	for i := range p {
+		p[i] = make([]float64, size)
+		beta, min, max := 1.0, 0.0, math.Inf(1)
+		for step := 0; step < 64; step++ {
+			sum, weighted := 0.0, 0.0
+			for j, distance := range distances[i] {
+				if j == i {
+					p[i][j] = 0
+					continue
+				}
+				p[i][j] = math.Exp(-distance * beta)
+				sum += p[i][j]
+				weighted += distance * p[i][j]
+			}
+			if sum == 0 {
+				sum = math.SmallestNonzeroFloat64
+			}
+			h := math.Log(sum) + beta*weighted/sum
+			for j := range p[i] {
+				p[i][j] /= sum
+			}
+			if math.Abs(h-entropy) < 1e-5 {
+				break
+			}
+			if h > entropy {
+				min = beta
+				if math.IsInf(max, 1) {
+					beta *= 2
+				} else {
+					beta = (beta + max) / 2
+				}
+			} else {
+				max = beta
+				beta = (beta + min) / 2
+			}
+		}
+	}
+	// The joint probabilities are the symmetrized conditional probabilities
+	joint := make([][]float64, size)
+	for i := range joint {
+		joint[i] = make([]float64, size)
+		for j := range joint[i] {
+			joint[i][j] = math.Max((p[i][j]+p[j][i])/(2*float64(size)), 1e-12)
+		}
+	}
+
+	y := make([][]float64, size)
+	velocities, gains := make([][]float64, size), make([][]float64, size)
+	for i := range y {
+		y[i], velocities[i], gains[i] = make([]float64, dimensions), make([]float64, dimensions), make([]float64, dimensions)
+		for d := range y[i] {
+			y[i][d] = rnd.NormFloat64() * 1e-4
+			gains[i][d] = 1
+		}
+	}
+	const eta = 200
+	numerators := make([][]float64, size)
+	for i := range numerators {
+		numerators[i] = make([]float64, size)
+	}
+	gradient := make([]float64, dimensions)
+	for iteration := 0; iteration < iterations; iteration++ {
+		// The early exaggeration of the joint probabilities forms tight clusters
+		exaggeration, momentum := 1.0, .8
+		if iteration < 100 {
+			exaggeration, momentum = 4, .5
+		}
+		sum := 0.0
+		for i := range y {
+			for j := range y {
+				if i == j {
+					numerators[i][j] = 0
+					continue
+				}
+				distance := 0.0
+				for d, value := range y[i] {
+					difference := value - y[j][d]
+					distance += difference * difference
+				}
+				numerators[i][j] = 1 / (1 + distance)
+				sum += numerators[i][j]
+			}
+		}
+		for i := range y {
+			for d := range gradient {
+				gradient[d] = 0
+			}
+			for j := range y {
+				if i == j {
+					continue
+				}
+				q := math.Max(numerators[i][j]/sum, 1e-12)
+				scale := 4 * (exaggeration*joint[i][j] - q) * numerators[i][j]
+				for d, value := range y[i] {
+					gradient[d] += scale * (value - y[j][d])
+				}
+			}
+			for d, g := range gradient {
+				if (g > 0) != (velocities[i][d] > 0) {
+					gains[i][d] += .2
+				} else {
+					gains[i][d] = math.Max(gains[i][d]*.8, .01)
+				}
+				velocities[i][d] = momentum*velocities[i][d] - eta*gains[i][d]*g
+			}
+		}
+		for d := 0; d < dimensions; d++ {
+			mean := 0.0
+			for i := range y {
+				y[i][d] += velocities[i][d]
+				mean += y[i][d]
+			}
+			mean /= float64(size)
+			for i := range y {
+				y[i][d] -= mean
+			}
+		}
+	}
+	return y
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"math"
+)
+
+// Prune returns a new network without the redundant points and the indexes of the removed points. A
+// point is redundant if its average attention over the samples is less than mass, or if it is within
+// distance of a point that is kept. An error is returned if every point is redundant.
+func (n *Network) Prune(samples [][]float64, mass, distance float64) (*Network, []int, error) {
+	attention := make([]float64, n.Length)
+	for _, sample := range samples {
+		for i, a := range n.Features(sample, LayerL1) {
+			attention[i] += float64(a) / float64(len(samples))
+		}
+	}
+
+	kept, removed := make([]int, 0, n.Length), make([]int, 0, 8)
+	for i := 0; i < n.Length; i++ {
+		if attention[i] < mass {
+			removed = append(removed, i)
+			continue
+		}
+		a := n.Point.X[i*n.Width : (i+1)*n.Width]
+		duplicate := false
+		for _, j := range kept {
+			b := n.Point.X[j*n.Width : (j+1)*n.Width]
+			sum := 0.0
+			for k := range a {
+				difference := float64(a[k] - b[k])
+				sum += difference * difference
+			}
+			if math.Sqrt(sum) < distance {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			removed = append(removed, i)
+			continue
+		}
+		kept = append(kept, i)
+	}
+	if len(kept) == 0 {
+		return nil, removed, errors.New("every point is redundant")
+	}
+
+	// The rows of the points of each layer and their biases are copied for the kept points
+	pruned := NewNetworkWithConfig(n.Width, len(kept), n.Config)
+	for l, layer := range n.Layers {
+		for i, row := range kept {
+			copy(pruned.Layers[l].X[i*n.Width:(i+1)*n.Width], layer.X[row*n.Width:(row+1)*n.Width])
+		}
+	}
+	for l, bias := range n.Biases {
+		for i, row := range kept {
+			pruned.Biases[l].X[i] = bias.X[row]
+		}
+	}
+	return pruned, removed, nil
+}
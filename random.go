@@ -0,0 +1,145 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// RandState is the state of a random source, the seed and the number of values drawn since seeding
+type RandState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+// Source is a random source that counts the values drawn from it so its state can be saved and restored
+type Source struct {
+	source rand.Source64
+	state  RandState
+}
+
+// NewSource creates a new counting random source with seed
+func NewSource(seed int64) *Source {
+	return &Source{
+		source: rand.NewSource(seed).(rand.Source64),
+		state: RandState{
+			Seed: seed,
+		},
+	}
+}
+
+// Seed seeds the source and resets the number of draws
+func (s *Source) Seed(seed int64) {
+	s.source.Seed(seed)
+	s.state = RandState{
+		Seed: seed,
+	}
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (s *Source) Int63() int64 {
+	s.state.Draws++
+	return s.source.Int63()
+}
+
+// Uint64 returns a pseudo-random 64-bit integer
+func (s *Source) Uint64() uint64 {
+	s.state.Draws++
+	return s.source.Uint64()
+}
+
+// State returns the state of the source
+func (s *Source) State() RandState {
+	return s.state
+}
+
+// Restore reseeds the source and advances it to state
+func (s *Source) Restore(state RandState) {
+	s.Seed(state.Seed)
+	for i := uint64(0); i < state.Draws; i++ {
+		s.source.Uint64()
+	}
+	s.state.Draws = state.Draws
+}
+
+// SetSeed seeds the random number generator of the network, which initializes the weights, shuffles and
+// samples the inputs, and injects noise
+func (n *Network) SetSeed(seed int64) {
+	n.Rnd.Seed(seed)
+}
+
+// RandState returns the state of the random number generator of the network.
+// Values buffered by Rnd.Read aren't part of the state.
+func (n *Network) RandState() RandState {
+	return n.Source.State()
+}
+
+// SetRandState restores the random number generator of the network to state, so the network draws
+// the same values it would have drawn when the state was saved
+func (n *Network) SetRandState(state RandState) {
+	n.Source.Restore(state)
+	n.Rnd = rand.New(n.Source)
+}
+
+// SaveCheckpoint saves the weights, the iteration, and the random number generator state of the
+// network. The weights are saved to path and the random number generator state to path + ".rnd".
+func (n *Network) SaveCheckpoint(path string) error {
+	cost := float32(0)
+	if length := len(n.Points); length > 0 {
+		cost = float32(n.Points[length-1].Y)
+	}
+	err := n.Set.Save(path, cost, n.I)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(n.RandState())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".rnd", data, 0644)
+}
+
+// LoadCheckpoint restores the weights, the iteration, and the random number generator state of the
+// network saved by SaveCheckpoint, so a resumed run continues exactly where the saved run stopped
+func (n *Network) LoadCheckpoint(path string) error {
+	set := tf32.NewSet()
+	_, i, err := set.Open(path)
+	if err != nil {
+		return err
+	}
+	for _, w := range set.Weights {
+		v := n.Set.ByName[w.N]
+		if v == nil {
+			continue
+		}
+		if len(v.X) != len(w.X) {
+			return fmt.Errorf("size of %s is %d but should be %d", w.N, len(w.X), len(v.X))
+		}
+		copy(v.X, w.X)
+		for j := range v.States {
+			if j < len(w.States) {
+				copy(v.States[j], w.States[j])
+			}
+		}
+	}
+	data, err := os.ReadFile(path + ".rnd")
+	if err != nil {
+		return err
+	}
+	var state RandState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return err
+	}
+	n.SetRandState(state)
+	n.I = i
+	n.anneal()
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSourceRestore(t *testing.T) {
+	source := NewSource(7)
+	rnd := rand.New(source)
+	// Int63, Uint64, and Float64 all draw from the source
+	for i := 0; i < 100; i++ {
+		rnd.Int63()
+		rnd.Uint64()
+		rnd.Float64()
+	}
+	state := source.State()
+	want := make([]float64, 50)
+	for i := range want {
+		want[i] = rnd.NormFloat64()
+	}
+
+	source.Restore(state)
+	if source.State() != state {
+		t.Errorf("the state is %v but should be %v", source.State(), state)
+	}
+	for i, value := range want {
+		if got := rnd.NormFloat64(); got != value {
+			t.Fatalf("value %d is %f but should be %f", i, got, value)
+		}
+	}
+
+	// A new network restored to the state draws the same values
+	n := NewNetwork(3, 3)
+	n.SetRandState(state)
+	for i, value := range want {
+		if got := n.Rnd.NormFloat64(); got != value {
+			t.Fatalf("value %d of the network is %f but should be %f", i, got, value)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// NaNError is returned when the cost of a step isn't finite. It matches ErrNaN with errors.Is.
+type NaNError struct {
+	// Step is the iteration of the step
+	Step int
+	// Cost is the nan or infinite cost
+	Cost float32
+	// Retries is the number of times training was rolled back before giving up
+	Retries int
+}
+
+// Error returns the error message
+func (e *NaNError) Error() string {
+	if e.Retries > 0 {
+		return fmt.Sprintf("cost is %v at step %d after %d retries", e.Cost, e.Step, e.Retries)
+	}
+	return fmt.Sprintf("cost is %v at step %d", e.Cost, e.Step)
+}
+
+// Is is true for ErrNaN
+func (e *NaNError) Is(target error) bool {
+	return target == ErrNaN
+}
+
+// finite is true if the cost isn't nan or infinite
+func finite(cost float32) bool {
+	return !math.IsNaN(float64(cost)) && !math.IsInf(float64(cost), 0)
+}
+
+// Recovery rolls the network back to the last good checkpoint and reduces the learning rate when the cost
+// of a step isn't finite, so training continues instead of stopping
+type Recovery struct {
+	// Interval is the number of iterations between checkpoints
+	Interval int
+	// MaxRetries is the number of rollbacks without a new checkpoint before giving up
+	MaxRetries int
+	// Decay multiplies the learning rate after each rollback
+	Decay float32
+	// Scale is the factor of the learning rate after the rollbacks so far
+	Scale float32
+	// Retries is the number of rollbacks since the last checkpoint
+	Retries int
+	// Rollbacks is the total number of rollbacks
+	Rollbacks int
+	// iteration is the iteration of the checkpoint
+	iteration int
+	// weights and states are copies of the weights and optimizer states of the checkpoint
+	weights [][]float32
+	states  [][][]float32
+}
+
+// Recover enables recovery from nan and infinite costs with a checkpoint every interval iterations. The
+// learning rate is multiplied by decay after each rollback, and training gives up after retries rollbacks
+// without reaching a new checkpoint. A decay of zero halves the learning rate.
+func (n *Network) Recover(interval, retries int, decay float32) {
+	if interval < 1 {
+		interval = 1
+	}
+	if decay <= 0 {
+		decay = .5
+	}
+	n.Recovery = &Recovery{
+		Interval:   interval,
+		MaxRetries: retries,
+		Decay:      decay,
+		Scale:      1,
+	}
+}
+
+// scale returns the factor of the learning rate
+func (r *Recovery) scale() float32 {
+	if r == nil {
+		return 1
+	}
+	return r.Scale
+}
+
+// checkpoint copies the weights and optimizer states of the network
+func (r *Recovery) checkpoint(n *Network) {
+	r.iteration = n.I
+	r.weights = r.weights[:0]
+	r.states = r.states[:0]
+	for _, w := range n.Set.Weights {
+		r.weights = append(r.weights, append([]float32(nil), w.X...))
+		states := make([][]float32, len(w.States))
+		for i, state := range w.States {
+			states[i] = append([]float32(nil), state...)
+		}
+		r.states = append(r.states, states)
+	}
+	r.Retries = 0
+}
+
+// record takes a checkpoint of the network if there isn't one or the iteration is on the interval. It is
+// called after the cost of the step is found to be finite, before the weights are updated. The checkpoint
+// isn't retaken after rolling back to it, so the retries accumulate.
+func (r *Recovery) record(n *Network) {
+	if r == nil || (len(r.weights) > 0 && (n.I%r.Interval != 0 || n.I == r.iteration)) {
+		return
+	}
+	r.checkpoint(n)
+}
+
+// rollback restores the network to the checkpoint and reduces the learning rate. A NaNError is returned
+// if there isn't a checkpoint or there have been too many retries.
+func (r *Recovery) rollback(n *Network, cost float32) error {
+	n.zero()
+	if len(r.weights) == 0 {
+		return &NaNError{Step: n.I, Cost: cost}
+	}
+	step := n.I
+	for i, w := range n.Set.Weights {
+		copy(w.X, r.weights[i])
+		for j, state := range w.States {
+			copy(state, r.states[i][j])
+		}
+	}
+	n.I = r.iteration
+	n.anneal()
+	if r.Retries >= r.MaxRetries {
+		return &NaNError{Step: step, Cost: cost, Retries: r.Retries}
+	}
+	n.Logger.Warn("rolling back", "step", step, "cost", cost, "checkpoint", r.iteration)
+	r.Scale *= r.Decay
+	r.Retries++
+	r.Rollbacks++
+	return nil
+}
+
+// guard calculates the gradients with gradient, which returns the cost, and updates the weights with the
+// learning rate of the step if the cost is finite. Otherwise the gradients are discarded without updating the weights and a NaNError is returned,
+// or with recovery enabled the network is rolled back and the gradients are recalculated at the reduced
+// learning rate.
+func (n *Network) guard(rate func(step int) float32, gradient func() float32) (float32, error) {
+	for {
+		start := time.Now()
+		total := gradient()
+		if finite(total) {
+			n.Recovery.record(n)
+			n.step(start, total, rate(n.I)*n.Recovery.scale())
+			return total, nil
+		}
+		if n.Recovery == nil {
+			n.zero()
+			return total, &NaNError{Step: n.I, Cost: total}
+		}
+		err := n.Recovery.rollback(n, total)
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// TryIterate does a gradient descent operation like Iterate and returns a NaNError if the cost isn't
+// finite, in which case the weights aren't updated. With recovery enabled the network is rolled back and
+// the step is retried at the reduced learning rate, and the error is only returned when recovery gives up,
+// which leaves the network at the last good checkpoint.
+func (n *Network) TryIterate(data []float64) (float32, error) {
+	for i, measure := range data {
+		n.Input.X[i] = float32(measure)
+	}
+
+	return n.guard(n.Config.rate, func() float32 {
+		// Calculate the gradients
+		return tf32.Gradient(n.Cost).X[0]
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var recoverySamples = [][]float64{{1, 0, .5}, {0, 1, .25}, {.5, .5, 1}}
+
+func TestTryIterateNaN(t *testing.T) {
+	n := testNetwork(recoverySamples)
+	n.Iterate(recoverySamples[0])
+	points, step := append([]float32(nil), n.Point.X...), n.I
+	cost, err := n.TryIterate([]float64{math.NaN(), 0, 0})
+	if !errors.Is(err, ErrNaN) {
+		t.Fatalf("the error is %v for a cost of %f", err, cost)
+	}
+	if n.I != step {
+		t.Errorf("the step is %d but should be %d", n.I, step)
+	}
+	for i, x := range n.Point.X {
+		if x != points[i] {
+			t.Fatalf("point %d was updated to %f", i, x)
+		}
+	}
+	for _, w := range n.Set.Weights {
+		for i, d := range w.D {
+			if d != 0 {
+				t.Fatalf("gradient %d of %s is %f", i, w.N, d)
+			}
+		}
+	}
+	if cost := n.Iterate(recoverySamples[1]); !finite(cost) {
+		t.Errorf("the cost after the nan is %f", cost)
+	}
+}
+
+func TestIterateBatchRecovery(t *testing.T) {
+	n := testNetwork(recoverySamples, WithRecovery(1, 2, .5))
+	// The checkpoint is taken before the first update
+	points, step := append([]float32(nil), n.Point.X...), n.I
+	n.IterateBatch(recoverySamples)
+	n.IterateBatch([][]float64{recoverySamples[0], {math.NaN(), 0, 0}})
+	if n.Recovery.Rollbacks != 2 {
+		t.Errorf("there are %d rollbacks but should be 2", n.Recovery.Rollbacks)
+	}
+	if n.I != step {
+		t.Errorf("the step is %d but should be the checkpoint %d", n.I, step)
+	}
+	for i, x := range n.Point.X {
+		if x != points[i] {
+			t.Fatalf("point %d is %f but should be restored to %f", i, x, points[i])
+		}
+	}
+}
+
+func TestIteratePrivateNaN(t *testing.T) {
+	n := testNetwork(recoverySamples)
+	points := append([]float32(nil), n.Point.X...)
+	_, err := n.IteratePrivate([][]float64{recoverySamples[0], {math.NaN(), 0, 0}}, NewPrivacy(1, 1, 1e-5))
+	if !errors.Is(err, ErrNaN) {
+		t.Fatalf("the error is %v but should be a nan error", err)
+	}
+	for i, x := range n.Point.X {
+		if x != points[i] {
+			t.Fatalf("point %d was updated to %f", i, x)
+		}
+	}
+}
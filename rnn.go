@@ -0,0 +1,91 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Replay is a buffer of past states which are periodically retrained on
+type Replay struct {
+	// Capacity is the maximum number of states stored
+	Capacity int
+	// Interval is the number of steps between replays
+	Interval int
+	// Batch is the number of states retrained on in each replay
+	Batch  int
+	States [][]float64
+	next   int
+}
+
+// add adds a copy of the state to the buffer, replacing the oldest state if the buffer is full
+func (r *Replay) add(state []float64) {
+	stored := make([]float64, len(state))
+	copy(stored, state)
+	if len(r.States) < r.Capacity {
+		r.States = append(r.States, stored)
+		return
+	}
+	r.States[r.next] = stored
+	r.next = (r.next + 1) % r.Capacity
+}
+
+// RNN is a recurrent network that feeds the l2 output back in as the next state
+type RNN struct {
+	*Network
+	State  []float64
+	Replay *Replay
+	steps  int
+}
+
+// NewRNN creates a new recurrent network with a square points matrix
+func NewRNN(width int) *RNN {
+	return &RNN{
+		Network: NewNetwork(width, width),
+		State:   make([]float64, width),
+	}
+}
+
+// EnableReplay stores up to capacity past states and retrains on batch of them every interval steps,
+// which stabilizes the feedback loop. The capacity and interval are at least 1.
+func (r *RNN) EnableReplay(capacity, interval, batch int) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if interval < 1 {
+		interval = 1
+	}
+	r.Replay = &Replay{
+		Capacity: capacity,
+		Interval: interval,
+		Batch:    batch,
+		States:   make([][]float64, 0, capacity),
+	}
+}
+
+// Step mixes the input into the beginning of the state, does a gradient descent operation
+// on the state, and then replaces the state with the l2 output. If replay is enabled past
+// states are periodically retrained on.
+func (r *RNN) Step(input ...float64) float32 {
+	copy(r.State, input)
+	total := r.Iterate(r.State)
+	if r.Replay != nil {
+		r.Replay.add(r.State)
+	}
+	r.L2(func(a *tf32.V) bool {
+		for i, value := range a.X {
+			r.State[i] = float64(value)
+		}
+		return true
+	})
+
+	r.steps++
+	if r.Replay != nil && r.steps%r.Replay.Interval == 0 {
+		for i := 0; i < r.Replay.Batch; i++ {
+			r.Iterate(r.Replay.States[r.Rnd.Intn(len(r.Replay.States))])
+		}
+	}
+	return total
+}
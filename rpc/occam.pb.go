@@ -0,0 +1,703 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: occam.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Sample is a sample of measures
+type Sample struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Measures []float64 `protobuf:"fixed64,1,rep,packed,name=measures,proto3" json:"measures,omitempty"`
+	Label    string    `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+}
+
+func (x *Sample) Reset() {
+	*x = Sample{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sample) ProtoMessage() {}
+
+func (x *Sample) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sample.ProtoReflect.Descriptor instead.
+func (*Sample) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Sample) GetMeasures() []float64 {
+	if x != nil {
+		return x.Measures
+	}
+	return nil
+}
+
+func (x *Sample) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+// StartTrainingRequest is a request to train a network, the points of which are initialized to the samples
+type StartTrainingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Samples []*Sample `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+	// steps is the number of iterations training stops at
+	Steps int64 `protobuf:"varint,2,opt,name=steps,proto3" json:"steps,omitempty"`
+	// eta is the learning rate, the default if zero
+	Eta  float32 `protobuf:"fixed32,3,opt,name=eta,proto3" json:"eta,omitempty"`
+	Seed int64   `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	// cost is the name of the cost in occam.Costs, the default objective if empty
+	Cost string `protobuf:"bytes,5,opt,name=cost,proto3" json:"cost,omitempty"`
+}
+
+func (x *StartTrainingRequest) Reset() {
+	*x = StartTrainingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartTrainingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTrainingRequest) ProtoMessage() {}
+
+func (x *StartTrainingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTrainingRequest.ProtoReflect.Descriptor instead.
+func (*StartTrainingRequest) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StartTrainingRequest) GetSamples() []*Sample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+func (x *StartTrainingRequest) GetSteps() int64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *StartTrainingRequest) GetEta() float32 {
+	if x != nil {
+		return x.Eta
+	}
+	return 0
+}
+
+func (x *StartTrainingRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *StartTrainingRequest) GetCost() string {
+	if x != nil {
+		return x.Cost
+	}
+	return ""
+}
+
+// StartTrainingResponse identifies the run started
+type StartTrainingResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Run string `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+}
+
+func (x *StartTrainingResponse) Reset() {
+	*x = StartTrainingResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartTrainingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTrainingResponse) ProtoMessage() {}
+
+func (x *StartTrainingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTrainingResponse.ProtoReflect.Descriptor instead.
+func (*StartTrainingResponse) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StartTrainingResponse) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+// StreamCostRequest is a request for the cost of the steps of a run
+type StreamCostRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Run string `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	// every only streams the steps that are a multiple of every, all of the steps if zero
+	Every int64 `protobuf:"varint,2,opt,name=every,proto3" json:"every,omitempty"`
+}
+
+func (x *StreamCostRequest) Reset() {
+	*x = StreamCostRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamCostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCostRequest) ProtoMessage() {}
+
+func (x *StreamCostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCostRequest.ProtoReflect.Descriptor instead.
+func (*StreamCostRequest) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamCostRequest) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+func (x *StreamCostRequest) GetEvery() int64 {
+	if x != nil {
+		return x.Every
+	}
+	return 0
+}
+
+// CostEvent is the cost after a step of training
+type CostEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Step  int64   `protobuf:"varint,1,opt,name=step,proto3" json:"step,omitempty"`
+	Steps int64   `protobuf:"varint,2,opt,name=steps,proto3" json:"steps,omitempty"`
+	Cost  float32 `protobuf:"fixed32,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// elapsed is the number of seconds since training started
+	Elapsed float64 `protobuf:"fixed64,4,opt,name=elapsed,proto3" json:"elapsed,omitempty"`
+	// eta is the estimated number of seconds until training finishes
+	Eta float64 `protobuf:"fixed64,5,opt,name=eta,proto3" json:"eta,omitempty"`
+	// done is true for the last event of a run
+	Done bool `protobuf:"varint,6,opt,name=done,proto3" json:"done,omitempty"`
+	// error is why training stopped early
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *CostEvent) Reset() {
+	*x = CostEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CostEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CostEvent) ProtoMessage() {}
+
+func (x *CostEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CostEvent.ProtoReflect.Descriptor instead.
+func (*CostEvent) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CostEvent) GetStep() int64 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *CostEvent) GetSteps() int64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *CostEvent) GetCost() float32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *CostEvent) GetElapsed() float64 {
+	if x != nil {
+		return x.Elapsed
+	}
+	return 0
+}
+
+func (x *CostEvent) GetEta() float64 {
+	if x != nil {
+		return x.Eta
+	}
+	return 0
+}
+
+func (x *CostEvent) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *CostEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// InferRequest is a request to run the measures through the network of a run
+type InferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Run      string    `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	Measures []float64 `protobuf:"fixed64,2,rep,packed,name=measures,proto3" json:"measures,omitempty"`
+}
+
+func (x *InferRequest) Reset() {
+	*x = InferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InferRequest) ProtoMessage() {}
+
+func (x *InferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InferRequest.ProtoReflect.Descriptor instead.
+func (*InferRequest) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *InferRequest) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+func (x *InferRequest) GetMeasures() []float64 {
+	if x != nil {
+		return x.Measures
+	}
+	return nil
+}
+
+// InferResponse is the output of the network for the measures
+type InferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// entropy is the cost of the network for the measures
+	Entropy float32 `protobuf:"fixed32,1,opt,name=entropy,proto3" json:"entropy,omitempty"`
+	// attention is the l1 attention over the points
+	Attention []float32 `protobuf:"fixed32,2,rep,packed,name=attention,proto3" json:"attention,omitempty"`
+	// cluster is the index of the point with the most attention
+	Cluster int64 `protobuf:"varint,3,opt,name=cluster,proto3" json:"cluster,omitempty"`
+}
+
+func (x *InferResponse) Reset() {
+	*x = InferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_occam_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InferResponse) ProtoMessage() {}
+
+func (x *InferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_occam_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InferResponse.ProtoReflect.Descriptor instead.
+func (*InferResponse) Descriptor() ([]byte, []int) {
+	return file_occam_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InferResponse) GetEntropy() float32 {
+	if x != nil {
+		return x.Entropy
+	}
+	return 0
+}
+
+func (x *InferResponse) GetAttention() []float32 {
+	if x != nil {
+		return x.Attention
+	}
+	return nil
+}
+
+func (x *InferResponse) GetCluster() int64 {
+	if x != nil {
+		return x.Cluster
+	}
+	return 0
+}
+
+var File_occam_proto protoreflect.FileDescriptor
+
+var file_occam_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6f,
+	0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x22, 0x3a, 0x0a, 0x06, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x01, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x22, 0x93, 0x01, 0x0a, 0x14, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x72,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a,
+	0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74,
+	0x65, 0x70, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73,
+	0x12, 0x10, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x03, 0x65,
+	0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x22, 0x29, 0x0a, 0x15, 0x53, 0x74,
+	0x61, 0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x75, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x72, 0x75, 0x6e, 0x22, 0x3b, 0x0a, 0x11, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43,
+	0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x75,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x72, 0x75, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x76, 0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x65, 0x76, 0x65,
+	0x72, 0x79, 0x22, 0x9f, 0x01, 0x0a, 0x09, 0x43, 0x6f, 0x73, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x74, 0x65, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x07, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x74, 0x61, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x65, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x6f,
+	0x6e, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x64, 0x6f, 0x6e, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0x3c, 0x0a, 0x0c, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x75, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x72, 0x75, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72,
+	0x65, 0x73, 0x22, 0x61, 0x0a, 0x0d, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x02, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x12, 0x1c, 0x0a,
+	0x09, 0x61, 0x74, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x02,
+	0x52, 0x09, 0x61, 0x74, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x32, 0xdb, 0x01, 0x0a, 0x05, 0x4f, 0x63, 0x63, 0x61, 0x6d, 0x12,
+	0x52, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
+	0x12, 0x1f, 0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74,
+	0x61, 0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0a, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x6f, 0x73,
+	0x74, 0x12, 0x1c, 0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x43, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x14, 0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x6f, 0x73, 0x74,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x3a, 0x0a, 0x05, 0x49, 0x6e, 0x66, 0x65, 0x72,
+	0x12, 0x17, 0x2e, 0x6f, 0x63, 0x63, 0x61, 0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x49, 0x6e, 0x66,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6f, 0x63, 0x63, 0x61,
+	0x6d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x65, 0x72, 0x2f, 0x6f, 0x63,
+	0x63, 0x61, 0x6d, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_occam_proto_rawDescOnce sync.Once
+	file_occam_proto_rawDescData = file_occam_proto_rawDesc
+)
+
+func file_occam_proto_rawDescGZIP() []byte {
+	file_occam_proto_rawDescOnce.Do(func() {
+		file_occam_proto_rawDescData = protoimpl.X.CompressGZIP(file_occam_proto_rawDescData)
+	})
+	return file_occam_proto_rawDescData
+}
+
+var file_occam_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_occam_proto_goTypes = []any{
+	(*Sample)(nil),                // 0: occam.rpc.Sample
+	(*StartTrainingRequest)(nil),  // 1: occam.rpc.StartTrainingRequest
+	(*StartTrainingResponse)(nil), // 2: occam.rpc.StartTrainingResponse
+	(*StreamCostRequest)(nil),     // 3: occam.rpc.StreamCostRequest
+	(*CostEvent)(nil),             // 4: occam.rpc.CostEvent
+	(*InferRequest)(nil),          // 5: occam.rpc.InferRequest
+	(*InferResponse)(nil),         // 6: occam.rpc.InferResponse
+}
+var file_occam_proto_depIdxs = []int32{
+	0, // 0: occam.rpc.StartTrainingRequest.samples:type_name -> occam.rpc.Sample
+	1, // 1: occam.rpc.Occam.StartTraining:input_type -> occam.rpc.StartTrainingRequest
+	3, // 2: occam.rpc.Occam.StreamCost:input_type -> occam.rpc.StreamCostRequest
+	5, // 3: occam.rpc.Occam.Infer:input_type -> occam.rpc.InferRequest
+	2, // 4: occam.rpc.Occam.StartTraining:output_type -> occam.rpc.StartTrainingResponse
+	4, // 5: occam.rpc.Occam.StreamCost:output_type -> occam.rpc.CostEvent
+	6, // 6: occam.rpc.Occam.Infer:output_type -> occam.rpc.InferResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_occam_proto_init() }
+func file_occam_proto_init() {
+	if File_occam_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_occam_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Sample); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*StartTrainingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*StartTrainingResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamCostRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CostEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*InferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_occam_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*InferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_occam_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_occam_proto_goTypes,
+		DependencyIndexes: file_occam_proto_depIdxs,
+		MessageInfos:      file_occam_proto_msgTypes,
+	}.Build()
+	File_occam_proto = out.File
+	file_occam_proto_rawDesc = nil
+	file_occam_proto_goTypes = nil
+	file_occam_proto_depIdxs = nil
+}
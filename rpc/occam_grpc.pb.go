@@ -0,0 +1,228 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: occam.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Occam_StartTraining_FullMethodName = "/occam.rpc.Occam/StartTraining"
+	Occam_StreamCost_FullMethodName    = "/occam.rpc.Occam/StreamCost"
+	Occam_Infer_FullMethodName         = "/occam.rpc.Occam/Infer"
+)
+
+// OccamClient is the client API for Occam service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Occam trains networks and runs inference on them remotely
+type OccamClient interface {
+	// StartTraining starts training a network on the samples in the background
+	StartTraining(ctx context.Context, in *StartTrainingRequest, opts ...grpc.CallOption) (*StartTrainingResponse, error)
+	// StreamCost streams the cost of each step of a run until training stops
+	StreamCost(ctx context.Context, in *StreamCostRequest, opts ...grpc.CallOption) (Occam_StreamCostClient, error)
+	// Infer runs a sample through the network of a run
+	Infer(ctx context.Context, in *InferRequest, opts ...grpc.CallOption) (*InferResponse, error)
+}
+
+type occamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOccamClient(cc grpc.ClientConnInterface) OccamClient {
+	return &occamClient{cc}
+}
+
+func (c *occamClient) StartTraining(ctx context.Context, in *StartTrainingRequest, opts ...grpc.CallOption) (*StartTrainingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartTrainingResponse)
+	err := c.cc.Invoke(ctx, Occam_StartTraining_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occamClient) StreamCost(ctx context.Context, in *StreamCostRequest, opts ...grpc.CallOption) (Occam_StreamCostClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Occam_ServiceDesc.Streams[0], Occam_StreamCost_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &occamStreamCostClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Occam_StreamCostClient interface {
+	Recv() (*CostEvent, error)
+	grpc.ClientStream
+}
+
+type occamStreamCostClient struct {
+	grpc.ClientStream
+}
+
+func (x *occamStreamCostClient) Recv() (*CostEvent, error) {
+	m := new(CostEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *occamClient) Infer(ctx context.Context, in *InferRequest, opts ...grpc.CallOption) (*InferResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InferResponse)
+	err := c.cc.Invoke(ctx, Occam_Infer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OccamServer is the server API for Occam service.
+// All implementations must embed UnimplementedOccamServer
+// for forward compatibility
+//
+// Occam trains networks and runs inference on them remotely
+type OccamServer interface {
+	// StartTraining starts training a network on the samples in the background
+	StartTraining(context.Context, *StartTrainingRequest) (*StartTrainingResponse, error)
+	// StreamCost streams the cost of each step of a run until training stops
+	StreamCost(*StreamCostRequest, Occam_StreamCostServer) error
+	// Infer runs a sample through the network of a run
+	Infer(context.Context, *InferRequest) (*InferResponse, error)
+	mustEmbedUnimplementedOccamServer()
+}
+
+// UnimplementedOccamServer must be embedded to have forward compatible implementations.
+type UnimplementedOccamServer struct {
+}
+
+func (UnimplementedOccamServer) StartTraining(context.Context, *StartTrainingRequest) (*StartTrainingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTraining not implemented")
+}
+func (UnimplementedOccamServer) StreamCost(*StreamCostRequest, Occam_StreamCostServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCost not implemented")
+}
+func (UnimplementedOccamServer) Infer(context.Context, *InferRequest) (*InferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Infer not implemented")
+}
+func (UnimplementedOccamServer) mustEmbedUnimplementedOccamServer() {}
+
+// UnsafeOccamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OccamServer will
+// result in compilation errors.
+type UnsafeOccamServer interface {
+	mustEmbedUnimplementedOccamServer()
+}
+
+func RegisterOccamServer(s grpc.ServiceRegistrar, srv OccamServer) {
+	s.RegisterService(&Occam_ServiceDesc, srv)
+}
+
+func _Occam_StartTraining_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTrainingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccamServer).StartTraining(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occam_StartTraining_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccamServer).StartTraining(ctx, req.(*StartTrainingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occam_StreamCost_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamCostRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OccamServer).StreamCost(m, &occamStreamCostServer{ServerStream: stream})
+}
+
+type Occam_StreamCostServer interface {
+	Send(*CostEvent) error
+	grpc.ServerStream
+}
+
+type occamStreamCostServer struct {
+	grpc.ServerStream
+}
+
+func (x *occamStreamCostServer) Send(m *CostEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Occam_Infer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccamServer).Infer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occam_Infer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccamServer).Infer(ctx, req.(*InferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Occam_ServiceDesc is the grpc.ServiceDesc for Occam service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Occam_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "occam.rpc.Occam",
+	HandlerType: (*OccamServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartTraining",
+			Handler:    _Occam_StartTraining_Handler,
+		},
+		{
+			MethodName: "Infer",
+			Handler:    _Occam_Infer_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamCost",
+			Handler:       _Occam_StreamCost_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "occam.proto",
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rpc is a gRPC service that trains networks, streams the cost of training, and runs inference,
+// so long runs can be monitored and used without access to the file system of the server
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative occam.proto
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pointlander/occam"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Buffer is the number of cost events buffered for each stream, events are dropped for streams that fall
+// further behind so a slow client doesn't stall training
+const Buffer = 1024
+
+// Run is a network being trained by the server
+type Run struct {
+	sync.Mutex
+	ID      string
+	Network *occam.Network
+	// last is the last event of the run
+	last *CostEvent
+	// streams are the channels of the streams of the cost by the interval of the stream
+	streams map[chan *CostEvent]int64
+	// done is closed when training stops
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// publish sends the event to the streams
+func (r *Run) publish(event *CostEvent) {
+	r.Lock()
+	defer r.Unlock()
+	r.last = event
+	for stream, every := range r.streams {
+		if !event.Done && every > 0 && event.Step%every != 0 {
+			continue
+		}
+		select {
+		case stream <- event:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel of the cost events of the run, which is closed after the last event
+func (r *Run) subscribe(every int64) chan *CostEvent {
+	r.Lock()
+	defer r.Unlock()
+	stream := make(chan *CostEvent, Buffer)
+	select {
+	case <-r.done:
+		if r.last != nil {
+			stream <- r.last
+		}
+		close(stream)
+	default:
+		r.streams[stream] = every
+	}
+	return stream
+}
+
+// unsubscribe stops sending events to the channel
+func (r *Run) unsubscribe(stream chan *CostEvent) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.streams, stream)
+}
+
+// finish closes the streams of the run
+func (r *Run) finish() {
+	r.Lock()
+	defer r.Unlock()
+	for stream := range r.streams {
+		close(stream)
+		delete(r.streams, stream)
+	}
+	close(r.done)
+}
+
+// Server is the occam service. The network of a run is locked for each step of training, so inference can
+// be done while it trains.
+type Server struct {
+	UnimplementedOccamServer
+	sync.RWMutex
+	Runs map[string]*Run
+}
+
+// NewServer creates a new server
+func NewServer() *Server {
+	return &Server{
+		Runs: make(map[string]*Run),
+	}
+}
+
+// run returns the run with the id
+func (s *Server) run(id string) (*Run, error) {
+	s.RLock()
+	defer s.RUnlock()
+	r := s.Runs[id]
+	if r == nil {
+		return nil, status.Errorf(codes.NotFound, "run %s not found", id)
+	}
+	return r, nil
+}
+
+// StartTraining starts training a network on the samples in the background
+func (s *Server) StartTraining(ctx context.Context, request *StartTrainingRequest) (*StartTrainingResponse, error) {
+	samples := request.GetSamples()
+	if len(samples) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no samples")
+	}
+	width := len(samples[0].GetMeasures())
+	if width == 0 {
+		return nil, status.Error(codes.InvalidArgument, "samples don't have measures")
+	}
+	data := make([][]float64, len(samples))
+	for i, sample := range samples {
+		if len(sample.GetMeasures()) != width {
+			return nil, status.Errorf(codes.InvalidArgument, "sample %d has %d measures but should have %d",
+				i, len(sample.GetMeasures()), width)
+		}
+		data[i] = sample.GetMeasures()
+	}
+	config := occam.DefaultNetworkConfig()
+	if request.GetEta() > 0 {
+		config.Eta = request.GetEta()
+	}
+	if name := request.GetCost(); name != "" {
+		cost, ok := occam.Costs[name]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown cost %s", name)
+		}
+		config.Cost = cost
+	}
+
+	n := occam.NewNetworkWithConfig(width, len(data), config, occam.WithSeed(request.GetSeed()))
+	for i, measures := range data {
+		for j, measure := range measures {
+			n.Point.X[width*i+j] = float32(measure)
+		}
+	}
+	training, cancel := context.WithCancel(context.Background())
+	r := &Run{
+		ID:      fmt.Sprintf("%016x", rand.Uint64()),
+		Network: n,
+		streams: make(map[chan *CostEvent]int64),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+	s.Lock()
+	s.Runs[r.ID] = r
+	s.Unlock()
+
+	go r.train(training, data, int(request.GetSteps()))
+
+	return &StartTrainingResponse{Run: r.ID}, nil
+}
+
+// train trains the network of the run until steps with the samples shuffled each epoch
+func (r *Run) train(ctx context.Context, samples [][]float64, steps int) {
+	defer r.finish()
+	n := r.Network
+	indexes := make([]int, len(samples))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	start, first := time.Now(), n.I
+	event := &CostEvent{Steps: int64(steps)}
+	var err error
+	// The samples are indexed by the attempts, because a rollback can return to or before the first step
+	for attempt := 0; n.I < steps; attempt++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		r.Lock()
+		if attempt%len(indexes) == 0 {
+			n.Rnd.Shuffle(len(indexes), func(i, j int) {
+				indexes[i], indexes[j] = indexes[j], indexes[i]
+			})
+		}
+		var cost float32
+		cost, err = n.TryIterate(samples[indexes[attempt%len(indexes)]])
+		step := n.I
+		r.Unlock()
+		if err != nil {
+			break
+		}
+		elapsed := time.Since(start)
+		event = &CostEvent{
+			Step:    int64(step),
+			Steps:   int64(steps),
+			Cost:    cost,
+			Elapsed: elapsed.Seconds(),
+		}
+		if done := step - first; done > 0 {
+			event.Eta = elapsed.Seconds() / float64(done) * float64(steps-step)
+		}
+		r.publish(event)
+	}
+	last := &CostEvent{
+		Step:    event.Step,
+		Steps:   event.Steps,
+		Cost:    event.Cost,
+		Elapsed: time.Since(start).Seconds(),
+		Done:    true,
+	}
+	if err != nil {
+		last.Error = err.Error()
+	}
+	r.publish(last)
+}
+
+// StreamCost streams the cost of each step of a run until training stops
+func (s *Server) StreamCost(request *StreamCostRequest, stream Occam_StreamCostServer) error {
+	r, err := s.run(request.GetRun())
+	if err != nil {
+		return err
+	}
+	events := r.subscribe(request.GetEvery())
+	defer r.unsubscribe(events)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			err := stream.Send(event)
+			if err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// Infer runs a sample through the network of a run
+func (s *Server) Infer(ctx context.Context, request *InferRequest) (*InferResponse, error) {
+	r, err := s.run(request.GetRun())
+	if err != nil {
+		return nil, err
+	}
+	r.Lock()
+	defer r.Unlock()
+	n := r.Network
+	measures := request.GetMeasures()
+	if len(measures) != n.Width {
+		return nil, status.Errorf(codes.InvalidArgument, "%d measures but the network has a width of %d",
+			len(measures), n.Width)
+	}
+	entropy := n.GetEntropy([]occam.Sample{{Measures: measures}})
+	return &InferResponse{
+		Entropy:   entropy[0].Entropy,
+		Attention: n.Attention(measures),
+		Cluster:   int64(n.Cluster(measures)),
+	}, nil
+}
+
+// Stop stops training the run and waits for training to stop
+func (s *Server) Stop(id string) error {
+	r, err := s.run(id)
+	if err != nil {
+		return err
+	}
+	r.cancel()
+	<-r.done
+	return nil
+}
+
+// Close stops training all of the runs
+func (s *Server) Close() error {
+	s.RLock()
+	runs := make([]*Run, 0, len(s.Runs))
+	for _, r := range s.Runs {
+		runs = append(runs, r)
+	}
+	s.RUnlock()
+	for _, r := range runs {
+		r.cancel()
+		<-r.done
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/datum/iris"
+)
+
+// Sample is a labeled vector of measures
+type Sample struct {
+	Measures []float64
+	Label    string
+}
+
+// FromIris converts iris data to samples
+func FromIris(data []iris.Iris) []Sample {
+	samples := make([]Sample, len(data))
+	for i, value := range data {
+		samples[i] = Sample(value)
+	}
+	return samples
+}
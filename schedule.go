@@ -0,0 +1,91 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+)
+
+// Schedule is a learning rate schedule
+type Schedule interface {
+	// Rate returns the learning rate for the step given the base learning rate eta
+	Rate(step int, eta float32) float32
+}
+
+// Constant is the constant learning rate schedule
+type Constant struct{}
+
+// Rate returns eta
+func (c Constant) Rate(step int, eta float32) float32 {
+	return eta
+}
+
+// StepDecay multiplies the learning rate by Factor every Steps steps
+type StepDecay struct {
+	Steps  int
+	Factor float32
+}
+
+// Rate returns eta decayed by the number of elapsed periods
+func (s StepDecay) Rate(step int, eta float32) float32 {
+	if s.Steps <= 0 {
+		return eta
+	}
+	return eta * float32(math.Pow(float64(s.Factor), float64(step/s.Steps)))
+}
+
+// Cosine anneals the learning rate from eta to Min over Steps steps along a half cosine
+// https://arxiv.org/abs/1608.03983
+type Cosine struct {
+	Steps int
+	Min   float32
+}
+
+// Rate returns the annealed learning rate, which stays at Min after Steps steps. The learning rate isn't
+// annealed if Steps isn't positive.
+func (c Cosine) Rate(step int, eta float32) float32 {
+	if c.Steps <= 0 {
+		return eta
+	}
+	if step >= c.Steps {
+		return c.Min
+	}
+	progress := float64(step) / float64(c.Steps)
+	return c.Min + (eta-c.Min)*float32(1+math.Cos(math.Pi*progress))/2
+}
+
+// Warmup linearly increases the learning rate from zero to eta over Steps steps and then
+// follows Schedule, which is started at step zero when the warmup ends
+type Warmup struct {
+	Steps    int
+	Schedule Schedule
+}
+
+// Rate returns the warmup learning rate
+func (w Warmup) Rate(step int, eta float32) float32 {
+	if step < w.Steps {
+		return eta * float32(step+1) / float32(w.Steps)
+	}
+	if w.Schedule == nil {
+		return eta
+	}
+	return w.Schedule.Rate(step-w.Steps, eta)
+}
+
+// temperature returns the annealed temperature of the configuration for the step
+func (c NetworkConfig) temperature(step int) float32 {
+	if c.Annealing == nil {
+		return c.Temperature
+	}
+	return c.Annealing.Rate(step, c.Temperature)
+}
+
+// rate returns the learning rate of the configuration for the step
+func (c NetworkConfig) rate(step int) float32 {
+	if c.Schedule == nil {
+		return c.Eta
+	}
+	return c.Schedule.Rate(step, c.Eta)
+}
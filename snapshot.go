@@ -0,0 +1,93 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Snapshots are copies of the network taken during training. Averaging the weights or the entropies
+// of the last snapshots reduces the step to step noise of stochastic gradient descent.
+type Snapshots struct {
+	// Size is the number of snapshots kept
+	Size int
+	// Interval is the number of iterations between snapshots
+	Interval int
+	// Models are the snapshots from oldest to newest
+	Models []*Network
+}
+
+// Snapshot keeps a copy of the network every interval iterations, up to the last size copies. The size
+// and the interval must be positive.
+func (n *Network) Snapshot(size, interval int) error {
+	if size <= 0 {
+		return fmt.Errorf("size of the snapshots is %d but should be positive", size)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("interval of the snapshots is %d but should be positive", interval)
+	}
+	n.Snapshots = &Snapshots{
+		Size:     size,
+		Interval: interval,
+	}
+	return nil
+}
+
+// record takes a snapshot of the network if the iteration is on the interval
+func (s *Snapshots) record(n *Network) {
+	if s == nil || s.Size <= 0 || s.Interval <= 0 || n.I%s.Interval != 0 {
+		return
+	}
+	model := NewNetworkWithConfig(n.Width, n.Length, n.Config)
+	model.TransferFrom(n)
+	model.I = n.I
+	model.anneal()
+	if len(s.Models) < s.Size {
+		s.Models = append(s.Models, model)
+		return
+	}
+	copy(s.Models, s.Models[1:])
+	s.Models[len(s.Models)-1] = model
+}
+
+// Average returns a new network with the weights averaged over the snapshots
+func (s *Snapshots) Average() (*Network, error) {
+	if s == nil || len(s.Models) == 0 {
+		return nil, errors.New("no snapshots")
+	}
+	return Average(s.Models...)
+}
+
+// Entropy returns the entropy of each input averaged over the snapshots
+func (s *Snapshots) Entropy(inputs []Sample) []Entropy {
+	if s == nil || len(s.Models) == 0 {
+		return nil
+	}
+	entropy := s.Models[0].GetEntropy(inputs)
+	for _, model := range s.Models[1:] {
+		for i, e := range model.GetEntropy(inputs) {
+			entropy[i].Entropy += e.Entropy
+		}
+	}
+	for i := range entropy {
+		entropy[i].Entropy /= float32(len(s.Models))
+	}
+	return rank(entropy)
+}
+
+// AverageCheckpoints returns a new network with the weights averaged over the checkpoints saved by
+// SaveCheckpoint, such as the last checkpoints of a run
+func AverageCheckpoints(config NetworkConfig, paths ...string) (*Network, error) {
+	models := make([]*Network, 0, len(paths))
+	for _, path := range paths {
+		model, err := OpenNetwork(path, config)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+	return Average(models...)
+}
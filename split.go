@@ -0,0 +1,89 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+// variance computes the variance of the entropies
+func variance(entropy []Entropy) float32 {
+	sum, count := float32(0.0), float32(0.0)
+	for _, e := range entropy {
+		sum += e.Entropy
+		count++
+	}
+	avg, vari := sum/count, float32(0.0)
+	for _, e := range entropy {
+		difference := e.Entropy - avg
+		vari += difference * difference
+	}
+	return vari / count
+}
+
+// HierarchicalSplit is a node of the recursive decomposition of entropies sorted in descending order.
+// Each node is split at the index that maximizes the reduction in the variance of the entropies.
+type HierarchicalSplit struct {
+	// Entropy are the entropies of the node
+	Entropy []Entropy
+	// Index is the index of the split, the first entropy of the low part
+	Index int
+	// Gain is the reduction in variance of the split
+	Gain float32
+	// High is the split of the entropies before Index and Low is the split of the entropies from Index,
+	// both are nil for a leaf
+	High, Low *HierarchicalSplit
+}
+
+// NewHierarchicalSplit recursively splits the entropies, which are sorted in descending order, depth times
+func NewHierarchicalSplit(entropy []Entropy, depth int) *HierarchicalSplit {
+	h := &HierarchicalSplit{
+		Entropy: entropy,
+	}
+	if depth == 0 || len(entropy) == 0 {
+		return h
+	}
+
+	vari := variance(entropy)
+	for i := 1; i < len(entropy); i++ {
+		gain := vari - (variance(entropy[:i]) + variance(entropy[i:]))
+		if gain > h.Gain {
+			h.Index, h.Gain = i, gain
+		}
+	}
+	// A node without a split that reduces the variance, such as a single entropy, is a leaf
+	if h.Index == 0 {
+		return h
+	}
+	h.Low = NewHierarchicalSplit(entropy[h.Index:], depth-1)
+	h.High = NewHierarchicalSplit(entropy[:h.Index], depth-1)
+	return h
+}
+
+// Leaf returns true if the node isn't split
+func (h *HierarchicalSplit) Leaf() bool {
+	return h.Low == nil
+}
+
+// Splits returns the Order of the first entropy of the low part of each split, the node first
+// followed by the splits of the low part and then the splits of the high part
+func (h *HierarchicalSplit) Splits() []int {
+	if h.Leaf() {
+		return nil
+	}
+	splits := []int{h.Entropy[h.Index].Order}
+	splits = append(splits, h.Low.Splits()...)
+	return append(splits, h.High.Splits()...)
+}
+
+// Leaves returns the entropies of the leaves from the highest to the lowest entropy
+func (h *HierarchicalSplit) Leaves() [][]Entropy {
+	if h.Leaf() {
+		return [][]Entropy{h.Entropy}
+	}
+	return append(h.High.Leaves(), h.Low.Leaves()...)
+}
+
+// SplitEntropy recursively splits the entropies, which are sorted in descending order, depth times and
+// returns the Order of the first entropy of the low part of each split
+func SplitEntropy(entropy []Entropy, depth int) []int {
+	return NewHierarchicalSplit(entropy, depth).Splits()
+}
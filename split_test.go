@@ -0,0 +1,124 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"testing"
+)
+
+// entropies returns the values as entropies sorted in descending order
+func entropies(values ...float32) []Entropy {
+	entropy := make([]Entropy, len(values))
+	for i, value := range values {
+		entropy[i] = Entropy{Entropy: value, Index: i}
+	}
+	return rank(entropy)
+}
+
+func TestHierarchicalSplit(t *testing.T) {
+	entropy := entropies(10, 9.9, 9.8, 9.7, 1.2, 1.1, 1, .9)
+	h := NewHierarchicalSplit(entropy, 1)
+	if h.Leaf() || h.Index != 4 {
+		t.Fatalf("the split is at %d but should be at 4", h.Index)
+	}
+	if h.Gain <= 0 {
+		t.Errorf("the gain is %f but should be positive", h.Gain)
+	}
+	leaves := h.Leaves()
+	if len(leaves) != 2 {
+		t.Fatalf("there are %d leaves but should be 2", len(leaves))
+	}
+	for i, leaf := range leaves {
+		for _, e := range leaf {
+			if e.Order/4 != i {
+				t.Errorf("entropy %f with order %d is in leaf %d", e.Entropy, e.Order, i)
+			}
+		}
+	}
+
+	// Each of three groups is separated by the first two levels of splits
+	entropy = entropies(10, 9.9, 9.8, 5.1, 5, 4.9, 1.1, 1, .9)
+	splits := make(map[int]bool)
+	for _, split := range SplitEntropy(entropy, 2) {
+		splits[split] = true
+	}
+	if !splits[3] || !splits[6] {
+		t.Errorf("the splits %v should separate the groups at 3 and 6", splits)
+	}
+}
+
+func TestHierarchicalSplitUniform(t *testing.T) {
+	for _, entropy := range [][]Entropy{nil, entropies(1), entropies(2, 2, 2, 2)} {
+		h := NewHierarchicalSplit(entropy, 3)
+		if !h.Leaf() {
+			t.Errorf("%d entropies without variance are split at %d", len(entropy), h.Index)
+		}
+		if splits := h.Splits(); len(splits) != 0 {
+			t.Errorf("%d entropies without variance have splits %v", len(entropy), splits)
+		}
+	}
+}
+
+func TestSplitEntropy(t *testing.T) {
+	entropy := entropies(8, 7.9, 6, 5.8, 4, 3.9, 2, 1.8, 1, .2, .1, 0)
+	for depth := 0; depth < 5; depth++ {
+		splits := SplitEntropy(entropy, depth)
+		if len(splits) > 1<<depth-1 {
+			t.Errorf("there are %d splits at depth %d", len(splits), depth)
+		}
+		seen := make(map[int]bool)
+		for _, split := range splits {
+			// A split is the order of the first entropy of the low part, so it can't be the first entropy
+			if split <= 0 || split >= len(entropy) {
+				t.Errorf("split %d at depth %d is out of bounds", split, depth)
+			}
+			if seen[split] {
+				t.Errorf("split %d at depth %d is repeated", split, depth)
+			}
+			seen[split] = true
+		}
+	}
+
+	// The node is first, followed by the splits of the low part and then the splits of the high part
+	h := NewHierarchicalSplit(entropy, 3)
+	var check func(h *HierarchicalSplit, splits []int) []int
+	check = func(h *HierarchicalSplit, splits []int) []int {
+		if h.Leaf() {
+			return splits
+		}
+		if len(splits) == 0 || splits[0] != h.Entropy[h.Index].Order {
+			t.Fatalf("the splits %v should start with %d", splits, h.Entropy[h.Index].Order)
+		}
+		low, high := h.Entropy[h.Index].Order, h.Entropy[0].Order
+		splits = check(h.Low, splits[1:])
+		for _, split := range h.Low.Splits() {
+			if split <= low {
+				t.Errorf("split %d of the low part is before %d", split, low)
+			}
+		}
+		for _, split := range h.High.Splits() {
+			if split <= high || split >= low {
+				t.Errorf("split %d of the high part is outside of %d to %d", split, high, low)
+			}
+		}
+		return check(h.High, splits)
+	}
+	if rest := check(h, h.Splits()); len(rest) != 0 {
+		t.Errorf("the splits %v aren't in the tree", rest)
+	}
+}
+
+func TestHierarchicalSplitAssign(t *testing.T) {
+	// The high part can't be split, so its samples stay in the column of its high part
+	entropy := entropies(10, 10, 10, 5.1, 5, 1.1, 1)
+	assignment := make([]int, len(entropy))
+	NewHierarchicalSplit(entropy, 2).assign(assignment, 0, 2)
+	want := []int{0, 0, 0, 2, 2, 3, 3}
+	for i, column := range assignment {
+		if column != want[i] {
+			t.Errorf("the column of entropy %d is %d but should be %d", i, column, want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// StabilityAnalysis measures how stable the entropy split of the samples is under noise. Each trial adds
+// gaussian noise with a standard deviation of sigma to the measures of the samples, sets the points of a
+// new network to the noisy samples, and splits their entropies depth times. The trials run concurrently
+// and each one seeds its network with the seed of the options plus the index of the trial. The result has
+// a row for each sample with the fraction of the trials the sample is in each leaf of the split. The column
+// of a leaf is its path through the split, with a bit for each level that is 0 for the high part and 1
+// for the low part, so the columns are the leaves from the highest to the lowest entropy and they mean
+// the same thing in every trial. A node that isn't split keeps its samples in the column of its high part.
+func StabilityAnalysis(samples []Sample, sigma float64, trials, depth int, options ...Option) [][]float64 {
+	leaves := 1 << depth
+	frequencies := make([][]float64, len(samples))
+	for i := range frequencies {
+		frequencies[i] = make([]float64, leaves)
+	}
+	if len(samples) == 0 || trials < 1 {
+		return frequencies
+	}
+	width := len(samples[0].Measures)
+
+	// The leaf of each sample for each trial
+	assignments := make([][]int, trials)
+	indexes := make(chan int, trials)
+	workers := runtime.GOMAXPROCS(0)
+	if workers > trials {
+		workers = trials
+	}
+	var wait sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for trial := range indexes {
+				n := NewNetwork(width, len(samples), options...)
+				n.SetSeed(n.RandState().Seed + int64(trial))
+				data := make([]Sample, len(samples))
+				for i, sample := range samples {
+					measures := make([]float64, len(sample.Measures))
+					for j, measure := range sample.Measures {
+						measures[j] = measure + n.Rnd.NormFloat64()*sigma
+						n.Point.X[width*i+j] = float32(measures[j])
+					}
+					data[i] = Sample{
+						Measures: measures,
+						Label:    sample.Label,
+					}
+				}
+				entropy := n.GetEntropy(data)
+				sort.SliceStable(entropy, func(i, j int) bool {
+					return entropy[i].Entropy > entropy[j].Entropy
+				})
+				assignment := make([]int, len(samples))
+				NewHierarchicalSplit(entropy, depth).assign(assignment, 0, depth)
+				assignments[trial] = assignment
+			}
+		}()
+	}
+	for trial := 0; trial < trials; trial++ {
+		indexes <- trial
+	}
+	close(indexes)
+	wait.Wait()
+
+	for _, assignment := range assignments {
+		for i, leaf := range assignment {
+			frequencies[i][leaf]++
+		}
+	}
+	for _, frequency := range frequencies {
+		for j := range frequency {
+			frequency[j] /= float64(trials)
+		}
+	}
+	return frequencies
+}
+
+// assign sets the column of the samples of each leaf to the path through the split, where column is the
+// path to the node and depth is the number of levels below it
+func (h *HierarchicalSplit) assign(assignment []int, column, depth int) {
+	if h.Leaf() {
+		for _, e := range h.Entropy {
+			assignment[e.Index] = column << depth
+		}
+		return
+	}
+	h.High.assign(assignment, column<<1, depth-1)
+	h.Low.assign(assignment, column<<1|1, depth-1)
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Eviction is the policy that selects the point replaced by a new sample when the stream is full
+type Eviction int
+
+const (
+	// EvictLeastUsed replaces the point with the least decayed attention
+	EvictLeastUsed Eviction = iota
+	// EvictOldest replaces the point that was added first
+	EvictOldest
+)
+
+// Stream is the state of the online clustering mode
+type Stream struct {
+	// Capacity is the maximum number of points
+	Capacity int
+	// Eviction selects the point replaced by a new sample when the network has Capacity points
+	Eviction Eviction
+	// Threshold is the attention below which a sample replaces a point when the network is full, so
+	// a sample that isn't close to any of the points is added
+	Threshold float32
+	// Decay is the exponential decay of the usage of the points
+	Decay float32
+	// Usage is the exponentially decayed attention of each point
+	Usage []float32
+	// Added is the sample number at which each point was added
+	Added []int
+	// Samples is the number of samples seen
+	Samples int
+}
+
+// Online enables the online clustering mode, in which Partial adds the samples as points until the
+// network has capacity points and then replaces points selected by eviction with novel samples. The
+// current points of the network are the initial points. Online mode is only supported for networks
+// without contrastive pairs, distillation, or supervision.
+func (n *Network) Online(capacity int, eviction Eviction) {
+	if capacity < n.Length {
+		capacity = n.Length
+	}
+	n.Stream = &Stream{
+		Capacity:  capacity,
+		Eviction:  eviction,
+		Threshold: .5,
+		Decay:     .99,
+		Usage:     make([]float32, n.Length),
+		Added:     make([]int, n.Length),
+	}
+}
+
+// Partial does a gradient descent operation on a sample of a stream and returns the cost. The sample is
+// first added as a new point if the network isn't full, or if the attention of every point for the
+// sample is below the threshold, in which case it replaces an evicted point.
+func (n *Network) Partial(sample []float64) float32 {
+	s := n.Stream
+	if s == nil {
+		panic("online mode has not been enabled")
+	}
+	s.Samples++
+
+	attention := n.Attention(sample)
+	max := float32(0)
+	for _, a := range attention {
+		if a > max {
+			max = a
+		}
+	}
+	if n.Length < s.Capacity || max < s.Threshold {
+		row := n.Length
+		if n.Length < s.Capacity {
+			n.resize(n.Length + 1)
+			s.Usage = append(s.Usage, 0)
+			s.Added = append(s.Added, 0)
+		} else {
+			row = s.evict()
+		}
+		for j, measure := range sample {
+			n.Point.X[row*n.Width+j] = float32(measure)
+		}
+		for _, state := range n.Point.States {
+			for j := row * n.Width; j < (row+1)*n.Width; j++ {
+				state[j] = 0
+			}
+		}
+		s.Usage[row], s.Added[row] = 1, s.Samples
+		// The index is rebuilt so it has the new point
+		if n.Index != nil {
+			n.Index = NewIndex(n.Point.X, n.Width, n.Config.Kernel)
+		}
+		attention = n.Attention(sample)
+	}
+
+	for i, a := range attention {
+		s.Usage[i] = s.Decay*s.Usage[i] + a
+	}
+	return n.Iterate(sample)
+}
+
+// evict returns the row of the point to replace
+func (s *Stream) evict() int {
+	row := 0
+	for i := range s.Usage {
+		switch s.Eviction {
+		case EvictOldest:
+			if s.Added[i] < s.Added[row] {
+				row = i
+			}
+		default:
+			if s.Usage[i] < s.Usage[row] {
+				row = i
+			}
+		}
+	}
+	return row
+}
+
+// resize grows the network to length points in place. The weights of the existing rows and their optimizer
+// states are kept and the new rows are zeros, so the graphs attached to the network stay valid and the
+// initializer and the random number generator aren't used. The checkpoint of the recovery has the old
+// length so it is dropped.
+func (n *Network) resize(length int) {
+	added := length - n.Length
+	if added <= 0 {
+		return
+	}
+	grow := func(v *tf32.V, size int) {
+		v.X = append(v.X, make([]float32, size)...)
+		v.D = append(v.D, make([]float32, size)...)
+		for j := range v.States {
+			v.States[j] = append(v.States[j], make([]float32, size)...)
+		}
+	}
+	for _, layer := range n.Layers {
+		grow(layer, added*n.Width)
+		layer.S[1] = length
+	}
+	for _, bias := range n.Biases {
+		grow(bias, added)
+		bias.S[0] = length
+	}
+	// The target of the cross entropy cost is a distribution over the points
+	if target := n.Others.ByName["target"]; target != nil {
+		grow(target, added)
+		target.S[0] = length
+	}
+	n.Length = length
+
+	for name, rows := range n.Frozen {
+		for len(rows) < length {
+			rows = append(rows, false)
+		}
+		n.Frozen[name] = rows
+	}
+	for len(n.Sensitivity.Sum) > 0 && len(n.Sensitivity.Sum) < length {
+		n.Sensitivity.Sum = append(n.Sensitivity.Sum, 0)
+	}
+	if n.Recovery != nil {
+		n.Recovery.weights, n.Recovery.states = nil, nil
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"testing"
+)
+
+func TestPartialResize(t *testing.T) {
+	n := testNetwork([][]float64{{1, 0, 0}, {0, 1, 0}}, WithRecovery(1, 2, .5))
+	n.Online(4, EvictLeastUsed)
+	n.Iterate([]float64{1, 0, 0})
+	n.Others.Add("extra", 1, 1)
+	extra, input, state := n.Others.ByName["extra"], n.Input, n.RandState()
+
+	n.Partial([]float64{0, 0, 1})
+	if n.Length != 3 || len(n.Point.X) != 3*n.Width || n.Point.S[1] != 3 {
+		t.Fatalf("the network has %d points and %d weights but should have 3 points", n.Length, len(n.Point.X))
+	}
+	for _, state := range n.Point.States {
+		if len(state) != len(n.Point.X) {
+			t.Fatalf("the optimizer state has %d values but should have %d", len(state), len(n.Point.X))
+		}
+	}
+	if n.Others.ByName["extra"] != extra || n.Input != input {
+		t.Error("the others of the network were rebuilt")
+	}
+	if got := n.RandState(); got != state {
+		t.Errorf("the random state is %v but should be %v", got, state)
+	}
+	if n.Recovery.iteration != n.I-1 || len(n.Recovery.weights[0]) != len(n.Point.X) {
+		t.Error("the checkpoint of the recovery has the old length")
+	}
+}
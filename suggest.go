@@ -0,0 +1,69 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+	"sort"
+)
+
+// Suggestion is a proposed label for a sample
+type Suggestion struct {
+	Label string
+	// Confidence is the fraction of the vote for the label
+	Confidence float64
+}
+
+// Suggest proposes a label for the measures by a vote of the k labeled samples nearest in attention
+// space, with each vote weighted by the cosine similarity of the attention over the points
+func (n *Network) Suggest(measures []float64, labeled []Sample, k int) Suggestion {
+	attention := n.Features(measures, LayerL1)
+	cosine := func(a, b []float32) float64 {
+		ab, aa, bb := 0.0, 0.0, 0.0
+		for i := range a {
+			ab += float64(a[i] * b[i])
+			aa += float64(a[i] * a[i])
+			bb += float64(b[i] * b[i])
+		}
+		if aa == 0 || bb == 0 {
+			return 0
+		}
+		return ab / (math.Sqrt(aa) * math.Sqrt(bb))
+	}
+
+	type Neighbor struct {
+		Label      string
+		Similarity float64
+	}
+	neighbors := make([]Neighbor, 0, len(labeled))
+	for _, sample := range labeled {
+		neighbors = append(neighbors, Neighbor{
+			Label:      sample.Label,
+			Similarity: cosine(attention, n.Features(sample.Measures, LayerL1)),
+		})
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].Similarity > neighbors[j].Similarity
+	})
+	if k > len(neighbors) {
+		k = len(neighbors)
+	}
+
+	votes, total := make(map[string]float64), 0.0
+	for _, neighbor := range neighbors[:k] {
+		votes[neighbor.Label] += neighbor.Similarity
+		total += neighbor.Similarity
+	}
+	suggestion := Suggestion{}
+	for label, vote := range votes {
+		if vote > suggestion.Confidence || (vote == suggestion.Confidence && label < suggestion.Label) {
+			suggestion.Label, suggestion.Confidence = label, vote
+		}
+	}
+	if total > 0 {
+		suggestion.Confidence /= total
+	}
+	return suggestion
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"math"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// Supervision is the graph for training a classification head on the l2 output together with the clustering
+type Supervision struct {
+	Classes int
+	// Set are the weights of the head, which are kept out of the set of the network so they aren't
+	// clipped, exported, pruned, transferred, or averaged with the points
+	Set          tf32.Set
+	Target       *tf32.V
+	Weight       float32
+	Output       tf32.Meta
+	CrossEntropy tf32.Meta
+	Cost         tf32.Meta
+	// labeled is true during a step on a labeled sample, the head is only updated by the optimizer on
+	// these steps so the momentum and decay of the optimizer don't move it on unlabeled steps
+	labeled bool
+}
+
+// Supervise adds a classification head with classes outputs on top of the l2 output. The head is
+// trained jointly with the points by IterateLabeled.
+func (n *Network) Supervise(classes int) *Supervision {
+	if n.Supervision != nil {
+		return n.Supervision
+	}
+	s := Supervision{
+		Classes: classes,
+		Set:     tf32.NewSet(),
+		Weight:  1,
+	}
+	s.Set.Add("head", n.Width, classes)
+	head := s.Set.ByName["head"]
+	factor := math.Sqrt(2.0 / float64(head.S[0]))
+	for i := 0; i < cap(head.X); i++ {
+		head.X = append(head.X, float32(n.Rnd.NormFloat64()*factor))
+	}
+	head.States = make([][]float32, StateTotal)
+	for i := range head.States {
+		head.States[i] = make([]float32, len(head.X))
+	}
+	s.Set.Add("bias", classes, 1)
+	bias := s.Set.ByName["bias"]
+	bias.X = bias.X[:cap(bias.X)]
+	bias.States = make([][]float32, StateTotal)
+	for i := range bias.States {
+		bias.States[i] = make([]float32, len(bias.X))
+	}
+
+	n.Others.Add("label", classes, 1)
+	s.Target = n.Others.ByName["label"]
+	s.Target.X = s.Target.X[:cap(s.Target.X)]
+
+	softmax, scale := tf32.U(SphericalSoftmax), tf32.U(Scale)
+	s.Output = softmax(tf32.Add(tf32.Mul(s.Set.Get("head"), n.L2), s.Set.Get("bias")))
+	s.CrossEntropy = tf32.CrossEntropy(s.Output, n.Others.Get("label"))
+	s.Cost = tf32.Add(n.Cost, scale(s.CrossEntropy, map[string]interface{}{
+		"scale": &s.Weight,
+	}))
+	n.Supervision = &s
+	return n.Supervision
+}
+
+// SetLabel sets the target of the classification head
+func (s *Supervision) SetLabel(label int) {
+	for i := range s.Target.X {
+		s.Target.X[i] = 0
+	}
+	s.Target.X[label] = 1
+}
+
+// IterateLabeled does a gradient descent operation on the sample. If the label isn't negative the
+// cross entropy of the classification head scaled by weight is added to the cost.
+func (n *Network) IterateLabeled(data []float64, label int, weight float32) float32 {
+	if n.Supervision == nil {
+		panic("supervision has not been enabled")
+	}
+	if label < 0 {
+		return n.Iterate(data)
+	}
+	s := n.Supervision
+	s.Weight = weight
+	s.SetLabel(label)
+	s.labeled = true
+	defer func() {
+		s.labeled = false
+	}()
+	for i, measure := range data {
+		n.Input.X[i] = float32(measure)
+	}
+
+	total, _ := n.guard(n.Config.rate, func() float32 {
+		// Calculate the gradients
+		return tf32.Gradient(s.Cost).X[0]
+	})
+
+	return total
+}
+
+// PredictLabel returns the class predicted by the classification head and the class probabilities
+func (n *Network) PredictLabel(data []float64) (int, []float32) {
+	if n.Supervision == nil {
+		panic("supervision has not been enabled")
+	}
+	for i, measure := range data {
+		n.Input.X[i] = float32(measure)
+	}
+	var probabilities []float32
+	n.Supervision.Output(func(a *tf32.V) bool {
+		probabilities = make([]float32, len(a.X))
+		copy(probabilities, a.X)
+		return true
+	})
+	index, max := 0, float32(0.0)
+	for i, p := range probabilities {
+		if p > max {
+			index, max = i, p
+		}
+	}
+	return index, probabilities
+}
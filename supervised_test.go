@@ -0,0 +1,32 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"testing"
+)
+
+func TestSupervisedHead(t *testing.T) {
+	n := testNetwork([][]float64{{1, 0, 0}, {0, 1, 0}}, WithOptimizer(NewAdamW(.1)))
+	s := n.Supervise(2)
+	n.IterateLabeled([]float64{1, 0, 0}, 0, 1)
+	head := append([]float32(nil), s.Set.ByName["head"].X...)
+	for i := 0; i < 4; i++ {
+		n.Iterate([]float64{0, 1, 0})
+	}
+	for i, x := range s.Set.ByName["head"].X {
+		if x != head[i] {
+			t.Fatalf("weight %d of the head moved from %f to %f on an unlabeled step", i, head[i], x)
+		}
+	}
+	n.IterateLabeled([]float64{0, 1, 0}, 1, 1)
+	moved := false
+	for i, x := range s.Set.ByName["head"].X {
+		moved = moved || x != head[i]
+	}
+	if !moved {
+		t.Error("the head didn't move on a labeled step")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package synthetic generates labeled data sets with known clusters for validating occam networks
+package synthetic
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/pointlander/occam"
+)
+
+// Generator generates a labeled data set of samples with width features
+type Generator func(rnd *rand.Rand, samples, width int) []occam.Sample
+
+// Generators are the data set generators with their default parameters
+var Generators = map[string]Generator{
+	"blobs": func(rnd *rand.Rand, samples, width int) []occam.Sample {
+		return Blobs(rnd, samples, width, 3, .5)
+	},
+	"circles": func(rnd *rand.Rand, samples, width int) []occam.Sample {
+		return Circles(rnd, samples, width, .05, .5)
+	},
+	"moons": func(rnd *rand.Rand, samples, width int) []occam.Sample {
+		return Moons(rnd, samples, width, .1)
+	},
+}
+
+// pad returns the two dimensional point padded to width with gaussian noise of deviation
+func pad(rnd *rand.Rand, x, y float64, width int, deviation float64) []float64 {
+	measures := make([]float64, width)
+	measures[0] = x
+	if width > 1 {
+		measures[1] = y
+	}
+	for i := 2; i < width; i++ {
+		measures[i] = rnd.NormFloat64() * deviation
+	}
+	return measures
+}
+
+// Blobs generates isotropic gaussian blobs around centers drawn uniformly from [-5, 5) in each feature.
+// The samples are assigned to the centers in turn.
+func Blobs(rnd *rand.Rand, samples, width, centers int, deviation float64) []occam.Sample {
+	means := make([][]float64, centers)
+	for i := range means {
+		means[i] = make([]float64, width)
+		for j := range means[i] {
+			means[i][j] = 10*rnd.Float64() - 5
+		}
+	}
+	data := make([]occam.Sample, samples)
+	for i := range data {
+		center := i % centers
+		measures := make([]float64, width)
+		for j := range measures {
+			measures[j] = means[center][j] + rnd.NormFloat64()*deviation
+		}
+		data[i] = occam.Sample{
+			Measures: measures,
+			Label:    strconv.Itoa(center),
+		}
+	}
+	return data
+}
+
+// Circles generates a large circle containing a smaller circle scaled by factor. Gaussian noise of
+// deviation noise is added to the points, and the features beyond the first two are noise.
+func Circles(rnd *rand.Rand, samples, width int, noise, factor float64) []occam.Sample {
+	data := make([]occam.Sample, samples)
+	for i := range data {
+		inner := i % 2
+		radius := 1.0
+		if inner == 1 {
+			radius = factor
+		}
+		theta := 2 * math.Pi * rnd.Float64()
+		x := radius*math.Cos(theta) + rnd.NormFloat64()*noise
+		y := radius*math.Sin(theta) + rnd.NormFloat64()*noise
+		data[i] = occam.Sample{
+			Measures: pad(rnd, x, y, width, noise),
+			Label:    strconv.Itoa(inner),
+		}
+	}
+	return data
+}
+
+// Moons generates two interleaving half circles. Gaussian noise of deviation noise is added to the
+// points, and the features beyond the first two are noise.
+func Moons(rnd *rand.Rand, samples, width int, noise float64) []occam.Sample {
+	data := make([]occam.Sample, samples)
+	for i := range data {
+		moon := i % 2
+		theta := math.Pi * rnd.Float64()
+		x, y := math.Cos(theta), math.Sin(theta)
+		if moon == 1 {
+			x, y = 1-x, .5-y
+		}
+		x += rnd.NormFloat64() * noise
+		y += rnd.NormFloat64() * noise
+		data[i] = occam.Sample{
+			Measures: pad(rnd, x, y, width, noise),
+			Label:    strconv.Itoa(moon),
+		}
+	}
+	return data
+}
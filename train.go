@@ -0,0 +1,132 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pointlander/gradient/tf32"
+)
+
+// IterateBatch does a gradient descent operation on a mini-batch of samples. The gradients of the samples
+// are averaged before the weights are updated and the average cost is returned.
+func (n *Network) IterateBatch(samples [][]float64) float32 {
+	if len(samples) == 0 {
+		return 0
+	}
+	total, _ := n.guard(n.Config.rate, func() float32 {
+		total := float32(0.0)
+		for _, sample := range samples {
+			for i, measure := range sample {
+				n.Input.X[i] = float32(measure)
+			}
+			// Calculate the gradients, which accumulate over the samples
+			total += tf32.Gradient(n.Cost).X[0]
+		}
+		scale := 1 / float32(len(samples))
+		for _, w := range n.Set.Weights {
+			for i := range w.D {
+				w.D[i] *= scale
+			}
+		}
+		return total * scale
+	})
+
+	return total
+}
+
+// Train trains the network for epochs passes over the samples, which are shuffled each epoch and split
+// into mini-batches of batchSize. The average cost of the last epoch is returned. Training stops early
+// if the cost becomes nan.
+func (n *Network) Train(samples [][]float64, epochs, batchSize int) float32 {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	indexes := make([]int, len(samples))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	batch := make([][]float64, 0, batchSize)
+	cost := float32(0.0)
+	for epoch := 0; epoch < epochs; epoch++ {
+		n.Rnd.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+		sum, batches := float32(0.0), 0
+		for begin := 0; begin < len(indexes); begin += batchSize {
+			end := begin + batchSize
+			if end > len(indexes) {
+				end = len(indexes)
+			}
+			batch = batch[:0]
+			for _, index := range indexes[begin:end] {
+				batch = append(batch, samples[index])
+			}
+			total := n.IterateBatch(batch)
+			if !finite(total) {
+				return total
+			}
+			sum += total
+			batches++
+		}
+		if batches > 0 {
+			cost = sum / float32(batches)
+		}
+	}
+	return cost
+}
+
+// ErrNaN is returned when training stops because the cost is nan
+var ErrNaN = errors.New("cost is nan")
+
+// Progress is the progress of training after a step
+type Progress struct {
+	// Step is the number of steps done, the iteration of the network
+	Step int
+	// Steps is the number of steps training stops at
+	Steps int
+	// Cost is the cost of the step
+	Cost float32
+	// Elapsed is the time since training started
+	Elapsed time.Duration
+	// ETA is the estimated time until training finishes at the average speed of the steps so far
+	ETA time.Duration
+}
+
+// ProgressFunc is called with the progress of training after each step
+type ProgressFunc func(progress Progress)
+
+// TrainContext trains the network until steps with the samples returned by sample for each iteration.
+// progress is called after each step if it isn't nil. Training stops before the next step when the context
+// is done, and the error of the context is returned, so the network can be saved in a consistent state.
+// A NaNError, which matches ErrNaN, is returned if the cost isn't finite and can't be recovered.
+func (n *Network) TrainContext(ctx context.Context, steps int, sample func(step int) []float64, progress ProgressFunc) error {
+	start, first := time.Now(), n.I
+	for n.I < steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		total, err := n.TryIterate(sample(n.I))
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			p := Progress{
+				Step:    n.I,
+				Steps:   steps,
+				Cost:    total,
+				Elapsed: time.Since(start),
+			}
+			// A rollback can return to or before the first step, then there is no speed to estimate from
+			if done := n.I - first; done > 0 {
+				p.ETA = time.Duration(float64(p.Elapsed) / float64(done) * float64(steps-n.I))
+			}
+			progress(p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"errors"
+)
+
+// Trajectory is the position of selected points recorded during training
+type Trajectory struct {
+	// Rows are the indexes of the recorded points
+	Rows []int
+	// Interval is the number of iterations between recordings
+	Interval int
+	// Steps are the iterations at which the points were recorded
+	Steps []int
+	// Positions are the recorded points indexed by recording, row, and feature
+	Positions [][][]float32
+}
+
+// Track records the points in rows every interval iterations. If rows is empty all of the points are recorded.
+func (n *Network) Track(interval int, rows ...int) {
+	if len(rows) == 0 {
+		rows = make([]int, n.Length)
+		for i := range rows {
+			rows[i] = i
+		}
+	}
+	n.Trajectory = &Trajectory{
+		Rows:     rows,
+		Interval: interval,
+	}
+}
+
+// record records the tracked points if the iteration is on the interval
+func (t *Trajectory) record(n *Network) {
+	if t == nil || n.I%t.Interval != 0 {
+		return
+	}
+	positions := make([][]float32, len(t.Rows))
+	for i, row := range t.Rows {
+		positions[i] = make([]float32, n.Width)
+		copy(positions[i], n.Point.X[row*n.Width:(row+1)*n.Width])
+	}
+	t.Steps = append(t.Steps, n.I)
+	t.Positions = append(t.Positions, positions)
+}
+
+// Project projects the recorded points onto their first two principal components, which are computed over
+// all of the recordings so the projections are comparable over time
+func (t *Trajectory) Project() ([][]XY, error) {
+	if len(t.Positions) == 0 {
+		return nil, errors.New("no recorded points")
+	}
+	rows := make([][]float64, 0, len(t.Positions)*len(t.Rows))
+	for _, positions := range t.Positions {
+		for _, position := range positions {
+			row := make([]float64, len(position))
+			for j, value := range position {
+				row[j] = float64(value)
+			}
+			rows = append(rows, row)
+		}
+	}
+	projected, err := Principal(rows, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	projections := make([][]XY, len(t.Positions))
+	r := 0
+	for i := range t.Positions {
+		projections[i] = make([]XY, len(t.Rows))
+		for j := range t.Rows {
+			projections[i][j].X = projected[r][0]
+			if len(projected[r]) > 1 {
+				projections[i][j].Y = projected[r][1]
+			}
+			r++
+		}
+	}
+	return projections, nil
+}
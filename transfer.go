@@ -0,0 +1,60 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package occam
+
+import (
+	"fmt"
+)
+
+// TransferFrom initializes the weights of the network from the weights of another network with the same
+// names. If the other network has a different number of rows only the overlapping rows are copied.
+func (n *Network) TransferFrom(other *Network) error {
+	for _, w := range other.Set.Weights {
+		v := n.Set.ByName[w.N]
+		if v == nil {
+			continue
+		}
+		if v.S[0] != w.S[0] {
+			return fmt.Errorf("width of %s is %d but should be %d", w.N, w.S[0], v.S[0])
+		}
+		size := len(v.X)
+		if len(w.X) < size {
+			size = len(w.X)
+		}
+		copy(v.X[:size], w.X[:size])
+	}
+	return nil
+}
+
+// Freeze freezes the rows of the named weights so they aren't updated during training.
+// If no rows are given all of the rows are frozen.
+func (n *Network) Freeze(name string, rows ...int) {
+	w := n.Set.ByName[name]
+	if w == nil {
+		panic(fmt.Sprintf("%s is not a weight", name))
+	}
+	if n.Frozen == nil {
+		n.Frozen = make(map[string][]bool)
+	}
+	frozen := n.Frozen[name]
+	if frozen == nil {
+		frozen = make([]bool, w.S[1])
+		n.Frozen[name] = frozen
+	}
+	if len(rows) == 0 {
+		for i := range frozen {
+			frozen[i] = true
+		}
+		return
+	}
+	for _, row := range rows {
+		frozen[row] = true
+	}
+}
+
+// Unfreeze unfreezes all of the rows of the named weights
+func (n *Network) Unfreeze(name string) {
+	delete(n.Frozen, name)
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tune
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// TPE is a tree-structured parzen estimator that suggests the next parameter values to try
+// based on the costs of past trials
+// https://papers.nips.cc/paper/2011/hash/86e8f7ab32cfd12577bc2619bc635690-Abstract.html
+type TPE struct {
+	Rnd        *rand.Rand
+	Parameters []Parameter
+	// Gamma is the fraction of the trials that are considered good
+	Gamma float64
+	// Candidates is the number of candidates drawn from the good density
+	Candidates int
+	// Startup is the number of random trials before the densities are used
+	Startup int
+}
+
+// NewTPE creates a new tree-structured parzen estimator with the default settings
+func NewTPE(rnd *rand.Rand, parameters []Parameter) *TPE {
+	return &TPE{
+		Rnd:        rnd,
+		Parameters: parameters,
+		Gamma:      .25,
+		Candidates: 24,
+		Startup:    10,
+	}
+}
+
+// normalize maps a parameter value into [0, 1]
+func normalize(p Parameter, value float64) float64 {
+	if p.Log {
+		min, max := math.Log(p.Min), math.Log(p.Max)
+		return (math.Log(value) - min) / (max - min)
+	}
+	return (value - p.Min) / (p.Max - p.Min)
+}
+
+// parzen is a mixture of gaussians in the normalized parameter space with a uniform prior
+type parzen struct {
+	means []float64
+	sigma float64
+}
+
+func newParzen(means []float64) parzen {
+	sigma := .2
+	if len(means) > 1 {
+		sum, sum2 := 0.0, 0.0
+		for _, mean := range means {
+			sum += mean
+			sum2 += mean * mean
+		}
+		n := float64(len(means))
+		std := math.Sqrt(math.Max(sum2/n-(sum/n)*(sum/n), 0))
+		sigma = 1.06 * std * math.Pow(n, -.2)
+	}
+	if sigma < .05 {
+		sigma = .05
+	}
+	return parzen{
+		means: means,
+		sigma: sigma,
+	}
+}
+
+// density is the probability density at x
+func (p parzen) density(x float64) float64 {
+	n := float64(len(p.means) + 1)
+	sum := 1 / n
+	for _, mean := range p.means {
+		d := (x - mean) / p.sigma
+		sum += math.Exp(-d*d/2) / (p.sigma * math.Sqrt(2*math.Pi)) / n
+	}
+	return sum
+}
+
+// sample draws a sample from the density
+func (p parzen) sample(rnd *rand.Rand) float64 {
+	i := rnd.Intn(len(p.means) + 1)
+	if i == len(p.means) {
+		return rnd.Float64()
+	}
+	x := p.means[i] + rnd.NormFloat64()*p.sigma
+	return math.Max(0, math.Min(1, x))
+}
+
+// Suggest suggests the next parameter values to try given the past trials
+func (t *TPE) Suggest(history Leaderboard) Values {
+	if len(history) < t.Startup {
+		return Random(t.Rnd, t.Parameters, 1)[0]
+	}
+	sorted := make(Leaderboard, len(history))
+	copy(sorted, history)
+	sorted.sort()
+	good := int(math.Ceil(t.Gamma * float64(len(sorted))))
+	if good < 1 {
+		good = 1
+	}
+	values := make(Values, len(t.Parameters))
+	for _, parameter := range t.Parameters {
+		l, g := make([]float64, 0, good), make([]float64, 0, len(sorted)-good)
+		for i, trial := range sorted {
+			value, ok := trial.Values[parameter.Name]
+			if !ok {
+				continue
+			}
+			if i < good {
+				l = append(l, normalize(parameter, value))
+			} else {
+				g = append(g, normalize(parameter, value))
+			}
+		}
+		lp, gp := newParzen(l), newParzen(g)
+		best, max := t.Rnd.Float64(), math.Inf(-1)
+		for i := 0; i < t.Candidates; i++ {
+			x := lp.sample(t.Rnd)
+			score := lp.density(x) / gp.density(x)
+			if score > max {
+				best, max = x, score
+			}
+		}
+		values[parameter.Name] = parameter.round(parameter.interpolate(best))
+	}
+	return values
+}
+
+// Optimize sequentially evaluates count trials suggested by the estimator and returns the leaderboard
+func (t *TPE) Optimize(objective Objective, count int) Leaderboard {
+	history := make(Leaderboard, 0, count)
+	for i := 0; i < count; i++ {
+		values := t.Suggest(history)
+		start := time.Now()
+		cost := objective(values)
+		if math.IsNaN(cost) {
+			cost = math.Inf(1)
+		}
+		history = append(history, Trial{
+			Values:   values,
+			Cost:     cost,
+			Duration: time.Since(start),
+		})
+	}
+	history.sort()
+	return history
+}
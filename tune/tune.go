@@ -0,0 +1,205 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tune searches for the hyperparameters of occam networks
+package tune
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pointlander/occam"
+)
+
+// Parameter is a named range of values for a hyperparameter
+type Parameter struct {
+	Name string
+	// Values are the values used by the grid search, if empty Steps values between Min and Max are used
+	Values []float64
+	Min    float64
+	Max    float64
+	Steps  int
+	// Log spaces the values logarithmically
+	Log bool
+	// Integer rounds the values to integers
+	Integer bool
+}
+
+// grid returns the values of the parameter for the grid search
+func (p Parameter) grid() []float64 {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+	if p.Steps < 2 {
+		return []float64{p.round(p.Min)}
+	}
+	values := make([]float64, 0, p.Steps)
+	for i := 0; i < p.Steps; i++ {
+		t := float64(i) / float64(p.Steps-1)
+		values = append(values, p.round(p.interpolate(t)))
+	}
+	return values
+}
+
+// interpolate maps t in [0, 1] to the range of the parameter
+func (p Parameter) interpolate(t float64) float64 {
+	if p.Log {
+		min, max := math.Log(p.Min), math.Log(p.Max)
+		return math.Exp(min + t*(max-min))
+	}
+	return p.Min + t*(p.Max-p.Min)
+}
+
+// round rounds the value if the parameter is an integer
+func (p Parameter) round(value float64) float64 {
+	if p.Integer {
+		return math.Round(value)
+	}
+	return value
+}
+
+// Values are the values of a set of parameters
+type Values map[string]float64
+
+// Grid returns every combination of the parameter values
+func Grid(parameters []Parameter) []Values {
+	trials := []Values{{}}
+	for _, parameter := range parameters {
+		next := make([]Values, 0, len(trials))
+		for _, trial := range trials {
+			for _, value := range parameter.grid() {
+				values := make(Values, len(trial)+1)
+				for k, v := range trial {
+					values[k] = v
+				}
+				values[parameter.Name] = value
+				next = append(next, values)
+			}
+		}
+		trials = next
+	}
+	return trials
+}
+
+// Random returns count random combinations of the parameter values
+func Random(rnd *rand.Rand, parameters []Parameter, count int) []Values {
+	trials := make([]Values, 0, count)
+	for i := 0; i < count; i++ {
+		values := make(Values, len(parameters))
+		for _, parameter := range parameters {
+			values[parameter.Name] = parameter.round(parameter.interpolate(rnd.Float64()))
+		}
+		trials = append(trials, values)
+	}
+	return trials
+}
+
+// Config applies the values eta, temperature, beta, and layers to a network configuration
+func Config(config occam.NetworkConfig, values Values) occam.NetworkConfig {
+	if eta, ok := values["eta"]; ok {
+		config.Eta = float32(eta)
+	}
+	if temperature, ok := values["temperature"]; ok {
+		config.Temperature = float32(temperature)
+	}
+	if beta, ok := values["beta"]; ok {
+		config.Beta = float32(beta)
+	}
+	if layers, ok := values["layers"]; ok {
+		config.Layers = int(layers)
+	}
+	return config
+}
+
+// Trial is the result of evaluating a set of parameter values
+type Trial struct {
+	Values   Values        `json:"values"`
+	Cost     float64       `json:"cost"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Leaderboard is a list of trials sorted from best to worst
+type Leaderboard []Trial
+
+// Objective returns the cost of a set of parameter values, lower is better
+type Objective func(values Values) float64
+
+// Run evaluates the trials with workers goroutines and returns the leaderboard.
+// The objective must be safe to call concurrently.
+func Run(trials []Values, objective Objective, workers int) Leaderboard {
+	if workers < 1 {
+		workers = 1
+	}
+	leaderboard := make(Leaderboard, len(trials))
+	indexes := make(chan int, len(trials))
+	for i := range trials {
+		indexes <- i
+	}
+	close(indexes)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				start := time.Now()
+				cost := objective(trials[i])
+				if math.IsNaN(cost) {
+					cost = math.Inf(1)
+				}
+				leaderboard[i] = Trial{
+					Values:   trials[i],
+					Cost:     cost,
+					Duration: time.Since(start),
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	leaderboard.sort()
+	return leaderboard
+}
+
+func (l Leaderboard) sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].Cost < l[j].Cost
+	})
+}
+
+// Save saves the leaderboard as json
+func (l Leaderboard) Save(path string) error {
+	// json can't encode infinite costs
+	trials := make([]Trial, len(l))
+	for i, trial := range l {
+		if math.IsInf(trial.Cost, 0) {
+			trial.Cost = math.MaxFloat64
+		}
+		trials[i] = trial
+	}
+	data, err := json.MarshalIndent(trials, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load loads a leaderboard from json
+func Load(path string) (Leaderboard, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var leaderboard Leaderboard
+	err = json.Unmarshal(data, &leaderboard)
+	if err != nil {
+		return nil, err
+	}
+	leaderboard.sort()
+	return leaderboard, nil
+}
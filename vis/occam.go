@@ -0,0 +1,160 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vis
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	imagedraw "image/draw"
+	"image/gif"
+	"os"
+
+	"github.com/pointlander/occam"
+
+	"gonum.org/v1/plot"
+	plotpalette "gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// PlotBottleneck plots the information bottleneck curve with one line per temperature
+func PlotBottleneck(curve []occam.Bottleneck, path string) error {
+	p := plot.New()
+
+	p.Title.Text = "compression vs information"
+	p.X.Label.Text = "log2 points"
+	p.Y.Label.Text = "I(X;C) bits"
+	p.Legend.Top = true
+
+	temperatures := make([]float32, 0, 8)
+	lines := make(map[float32]plotter.XYs)
+	for _, b := range curve {
+		if _, ok := lines[b.Temperature]; !ok {
+			temperatures = append(temperatures, b.Temperature)
+		}
+		lines[b.Temperature] = append(lines[b.Temperature], plotter.XY{X: b.Compression, Y: b.Information})
+	}
+	for _, temperature := range temperatures {
+		line, points, err := plotter.NewLinePoints(lines[temperature])
+		if err != nil {
+			return err
+		}
+		points.GlyphStyle.Radius = vg.Length(2)
+		points.GlyphStyle.Shape = draw.CircleGlyph{}
+		p.Add(line, points)
+		p.Legend.Add(fmt.Sprintf("t=%g", temperature), line, points)
+	}
+
+	return p.Save(8*vg.Inch, 8*vg.Inch, path)
+}
+
+// PlotLandscape plots the entropy landscape as a heat map
+func PlotLandscape(landscape *occam.Landscape, path string) error {
+	p := plot.New()
+
+	p.Title.Text = "entropy landscape"
+	p.X.Label.Text = fmt.Sprintf("feature %d", landscape.FeatureX)
+	p.Y.Label.Text = fmt.Sprintf("feature %d", landscape.FeatureY)
+
+	heat := plotter.NewHeatMap(landscape, plotpalette.Heat(64, 1))
+	p.Add(heat)
+
+	return p.Save(8*vg.Inch, 8*vg.Inch, path)
+}
+
+// plotTrajectory plots the projected paths of the points up to and including recording end
+func plotTrajectory(t *occam.Trajectory, projections [][]occam.XY, end int) (*plot.Plot, error) {
+	p := plot.New()
+
+	p.Title.Text = fmt.Sprintf("point trajectories at step %d", t.Steps[end])
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "y"
+
+	for j := range t.Rows {
+		path := make(plotter.XYs, 0, end+1)
+		for i := 0; i <= end; i++ {
+			path = append(path, plotter.XY{X: projections[i][j].X, Y: projections[i][j].Y})
+		}
+		line, err := plotter.NewLine(path)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = plotutil.Color(j)
+		scatter, err := plotter.NewScatter(path[len(path)-1:])
+		if err != nil {
+			return nil, err
+		}
+		scatter.GlyphStyle.Radius = vg.Length(3)
+		scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+		scatter.GlyphStyle.Color = plotutil.Color(j)
+		p.Add(line, scatter)
+	}
+	return p, nil
+}
+
+// PlotTrajectory plots the paths of the recorded points projected onto their first two principal components
+func PlotTrajectory(t *occam.Trajectory, path string) error {
+	projections, err := t.Project()
+	if err != nil {
+		return err
+	}
+	p, err := plotTrajectory(t, projections, len(projections)-1)
+	if err != nil {
+		return err
+	}
+	return p.Save(8*vg.Inch, 8*vg.Inch, path)
+}
+
+// AnimateTrajectory writes an animated gif of the paths of the recorded points projected onto their
+// first two principal components, with one frame per recording. delay is the time between frames in
+// hundredths of a second.
+func AnimateTrajectory(t *occam.Trajectory, path string, delay int) error {
+	projections, err := t.Project()
+	if err != nil {
+		return err
+	}
+	animation := &gif.GIF{}
+	for i := range projections {
+		p, err := plotTrajectory(t, projections, i)
+		if err != nil {
+			return err
+		}
+		// Keep the axes fixed across the frames
+		for _, projection := range projections {
+			for _, xy := range projection {
+				if xy.X < p.X.Min {
+					p.X.Min = xy.X
+				}
+				if xy.X > p.X.Max {
+					p.X.Max = xy.X
+				}
+				if xy.Y < p.Y.Min {
+					p.Y.Min = xy.Y
+				}
+				if xy.Y > p.Y.Max {
+					p.Y.Max = xy.Y
+				}
+			}
+		}
+		canvas := vgimg.New(4*vg.Inch, 4*vg.Inch)
+		p.Draw(draw.New(canvas))
+		img := canvas.Image()
+		frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+		imagedraw.FloydSteinberg.Draw(frame, img.Bounds(), img, image.Point{})
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	output, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	return gif.EncodeAll(output, animation)
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vis
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/components"
+)
+
+// Table is a table of a report
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// html returns the table as html
+func (t Table) html() string {
+	var b strings.Builder
+	b.WriteString("<h3>" + html.EscapeString(t.Title) + "</h3>\n<table>\n<tr>")
+	for _, header := range t.Header {
+		b.WriteString("<th>" + html.EscapeString(header) + "</th>")
+	}
+	b.WriteString("</tr>\n")
+	for _, row := range t.Rows {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			b.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+	return b.String()
+}
+
+// Report is a single html page of charts, a tree, and tables. The charts are rendered with go-echarts.
+type Report struct {
+	Title  string
+	Charts []Chart
+	// Tree is the tree below the root node of a tree chart, which is omitted if nil
+	Tree   []*TreeNode
+	Tables []Table
+	// Assets is a directory of local copies of the scripts of the charts, such as echarts.min.js, which
+	// are inlined so the page is self contained. The scripts are loaded from the go-echarts assets host
+	// if it is empty.
+	Assets string
+}
+
+// scripts matches the script tags that load a script from a url
+var scripts = regexp.MustCompile(`<script src="([^"]+)"></script>`)
+
+// inline replaces the script tags that load a script with the script from the assets directory
+func inline(page, assets string) (string, error) {
+	var err error
+	page = scripts.ReplaceAllStringFunc(page, func(tag string) string {
+		url := scripts.FindStringSubmatch(tag)[1]
+		script, e := os.ReadFile(filepath.Join(assets, filepath.Base(url)))
+		if e != nil {
+			if err == nil {
+				err = fmt.Errorf("%s isn't in the assets: %w", url, e)
+			}
+			return tag
+		}
+		return "<script>\n" + string(script) + "\n</script>"
+	})
+	return page, err
+}
+
+// Write writes the report to path
+func (r Report) Write(path string) error {
+	page := components.NewPage()
+	page.PageTitle = r.Title
+	for _, chart := range r.Charts {
+		page.AddCharts(echart(chart))
+	}
+	if r.Tree != nil {
+		page.AddCharts(treeChart(r.Tree))
+	}
+	var buffer bytes.Buffer
+	err := page.Render(&buffer)
+	if err != nil {
+		return err
+	}
+
+	var tables strings.Builder
+	tables.WriteString("<style>table{border-collapse:collapse;margin:16px auto}" +
+		"th,td{border:1px solid #ccc;padding:2px 8px}h3{text-align:center}</style>\n")
+	for _, table := range r.Tables {
+		tables.WriteString(table.html())
+	}
+	output := strings.Replace(buffer.String(), "</body>", tables.String()+"</body>", 1)
+	if r.Assets != "" {
+		output, err = inline(output, r.Assets)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(output), 0644)
+}
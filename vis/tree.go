@@ -0,0 +1,79 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vis
+
+import (
+	"os"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// TreeNode is a node of a tree chart
+type TreeNode struct {
+	Name     string
+	Children []*TreeNode
+}
+
+// translate converts the tree nodes to echarts tree data
+func translate(nodes []*TreeNode) []*opts.TreeData {
+	tree := make([]*opts.TreeData, 0, len(nodes))
+	for _, node := range nodes {
+		t := opts.TreeData{
+			Name: node.Name,
+		}
+		if node.Children != nil {
+			t.Children = translate(node.Children)
+		}
+		tree = append(tree, &t)
+	}
+	return tree
+}
+
+// Tree plots the tree below a root node as an interactive html page
+func Tree(tree []*TreeNode, path string) error {
+	page := components.NewPage()
+	page.AddCharts(
+		treeChart(tree),
+	)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return page.Render(f)
+}
+
+// treeChart converts the tree below a root node to a go-echarts tree chart
+func treeChart(tree []*TreeNode) *charts.Tree {
+	t := []opts.TreeData{
+		{
+			Name:     "Root",
+			Children: translate(tree),
+		},
+	}
+	graph := charts.NewTree()
+	graph.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "100%", Height: "500vh"}),
+		charts.WithTitleOpts(opts.Title{Title: "basic tree example"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: false}),
+	)
+	graph.AddSeries("tree", t).
+		SetSeriesOptions(
+			charts.WithTreeOpts(
+				opts.TreeChart{
+					Layout:           "orthogonal",
+					Orient:           "LR",
+					InitialTreeDepth: -1,
+					Leaves: &opts.TreeLeaves{
+						Label: &opts.Label{Show: true, Position: "right", Color: "Black"},
+					},
+				},
+			),
+			charts.WithLabelOpts(opts.Label{Show: true, Position: "top", Color: "Black"}),
+		)
+	return graph
+}
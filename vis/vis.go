@@ -0,0 +1,413 @@
+// Copyright 2022 The Occam Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vis plots charts with pluggable plotting backends. The Plotter interface sends the charts to
+// images, such as png or svg, html pages, vega-lite specifications, or in memory images.
+package vis
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"sync"
+
+	"github.com/pointlander/occam"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// Kind is the kind of a chart
+type Kind int
+
+const (
+	// KindScatter is a scatter plot
+	KindScatter Kind = iota
+	// KindLine is a line plot
+	KindLine
+)
+
+// Point is a point of a series
+type Point struct {
+	X, Y float64
+}
+
+// Series is a named series of points
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Chart is a chart of one or more series
+type Chart struct {
+	Kind   Kind
+	Title  string
+	XLabel string
+	YLabel string
+	// LogY is true for a log scale y axis, all of the y values must be positive
+	LogY bool
+	// Radius is the radius of the glyphs of a scatter chart in points, 1 if zero
+	Radius float64
+	Series []Series
+}
+
+// Scatter returns a scatter chart of the series
+func Scatter(title, xlabel, ylabel string, series ...Series) Chart {
+	return Chart{
+		Kind:   KindScatter,
+		Title:  title,
+		XLabel: xlabel,
+		YLabel: ylabel,
+		Series: series,
+	}
+}
+
+// Cost returns a scatter chart of the cost history of a network
+func Cost(points []occam.XY) Chart {
+	series := Series{
+		Name:   "cost",
+		Points: make([]Point, len(points)),
+	}
+	for i, xy := range points {
+		series.Points[i] = Point{X: xy.X, Y: xy.Y}
+	}
+	return Chart{
+		Kind:   KindScatter,
+		Title:  "epochs vs cost",
+		XLabel: "epochs",
+		YLabel: "cost",
+		Series: []Series{series},
+	}
+}
+
+// Costs returns a scatter chart overlaying the cost histories of several runs
+func Costs(names []string, runs ...[]occam.XY) Chart {
+	chart := Chart{
+		Kind:   KindScatter,
+		Title:  "epochs vs cost",
+		XLabel: "epochs",
+		YLabel: "cost",
+	}
+	for i, run := range runs {
+		series := Cost(run).Series[0]
+		if i < len(names) {
+			series.Name = names[i]
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	return chart
+}
+
+// Smooth returns a line chart with each series smoothed with an exponential moving average. alpha is
+// the weight of each new point.
+func (c Chart) Smooth(alpha float64) Chart {
+	smoothed := c
+	smoothed.Kind = KindLine
+	smoothed.Series = make([]Series, len(c.Series))
+	for i, series := range c.Series {
+		points := make([]Point, len(series.Points))
+		average := 0.0
+		for j, point := range series.Points {
+			if j == 0 {
+				average = point.Y
+			} else {
+				average = alpha*point.Y + (1-alpha)*average
+			}
+			points[j] = Point{X: point.X, Y: average}
+		}
+		smoothed.Series[i] = Series{
+			Name:   series.Name,
+			Points: points,
+		}
+	}
+	return smoothed
+}
+
+// Plotter plots a chart to a file
+type Plotter interface {
+	// Plot plots the chart to the file
+	Plot(chart Chart, path string) error
+	// Extension is the default file extension of the plotter
+	Extension() string
+}
+
+// Formats are the image formats supported by the gonum plotter
+var Formats = []string{"png", "svg", "pdf", "eps", "jpg", "tiff"}
+
+// New returns the plotter with the name: gonum, echarts, vega, or none. format is the image format of
+// the gonum plotter, png if empty.
+func New(name, format string) (Plotter, error) {
+	switch name {
+	case "gonum":
+		if format == "" {
+			format = "png"
+		}
+		for _, f := range Formats {
+			if f == format {
+				return Gonum{Format: format}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown format %s", format)
+	case "echarts":
+		return ECharts{}, nil
+	case "vega":
+		return VegaLite{}, nil
+	case "none":
+		return Nop{}, nil
+	}
+	return nil, fmt.Errorf("unknown plotter %s", name)
+}
+
+// Gonum plots charts as images with gonum/plot. The format is selected by the extension of the path,
+// so png, svg, pdf, eps, jpg, and tiff are supported.
+type Gonum struct {
+	// Format is the default image format
+	Format string
+}
+
+// Extension is the default file extension
+func (g Gonum) Extension() string {
+	if g.Format == "" {
+		return ".png"
+	}
+	return "." + g.Format
+}
+
+// Plot plots the chart
+func (Gonum) Plot(chart Chart, path string) error {
+	p, err := gonumPlot(chart)
+	if err != nil {
+		return err
+	}
+	return p.Save(8*vg.Inch, 8*vg.Inch, path)
+}
+
+// gonumPlot converts the chart to a gonum plot
+func gonumPlot(chart Chart) (*plot.Plot, error) {
+	p := plot.New()
+
+	p.Title.Text = chart.Title
+	p.X.Label.Text = chart.XLabel
+	p.Y.Label.Text = chart.YLabel
+	if chart.LogY {
+		p.Y.Scale = plot.LogScale{}
+		p.Y.Tick.Marker = plot.LogTicks{}
+	}
+	radius := chart.Radius
+	if radius == 0 {
+		radius = 1
+	}
+
+	for i, series := range chart.Series {
+		xys := make(plotter.XYs, len(series.Points))
+		for j, point := range series.Points {
+			xys[j] = plotter.XY{X: point.X, Y: point.Y}
+		}
+		switch chart.Kind {
+		case KindLine:
+			line, err := plotter.NewLine(xys)
+			if err != nil {
+				return nil, err
+			}
+			line.Color = plotutil.Color(i)
+			p.Add(line)
+			if len(chart.Series) > 1 {
+				p.Legend.Add(series.Name, line)
+			}
+		default:
+			scatter, err := plotter.NewScatter(xys)
+			if err != nil {
+				return nil, err
+			}
+			scatter.GlyphStyle.Radius = vg.Length(radius)
+			scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+			if len(chart.Series) > 1 {
+				scatter.GlyphStyle.Color = plotutil.Color(i)
+			}
+			p.Add(scatter)
+			if len(chart.Series) > 1 {
+				p.Legend.Add(series.Name, scatter)
+			}
+		}
+	}
+	if len(chart.Series) > 1 {
+		p.Legend.Top = true
+	}
+
+	return p, nil
+}
+
+// Memory renders charts to in memory images with gonum/plot instead of files, which is useful for
+// serving or composing plots. The path is the key of the image.
+type Memory struct {
+	sync.Mutex
+	// Images are the rendered images by path
+	Images map[string]image.Image
+}
+
+// Extension is the default file extension
+func (*Memory) Extension() string {
+	return ""
+}
+
+// Plot renders the chart to an image
+func (m *Memory) Plot(chart Chart, path string) error {
+	p, err := gonumPlot(chart)
+	if err != nil {
+		return err
+	}
+	canvas := vgimg.New(8*vg.Inch, 8*vg.Inch)
+	p.Draw(draw.New(canvas))
+
+	m.Lock()
+	defer m.Unlock()
+	if m.Images == nil {
+		m.Images = make(map[string]image.Image)
+	}
+	m.Images[path] = canvas.Image()
+	return nil
+}
+
+// CostPlot plots the cost history of a network to the path with gonum/plot. The image format is
+// selected by the extension of the path.
+func CostPlot(points []occam.XY, path string) error {
+	return Gonum{}.Plot(Cost(points), path)
+}
+
+// ScatterPlot plots the points to the path with gonum/plot. The image format is selected by the
+// extension of the path.
+func ScatterPlot(xy []Point, path string) error {
+	return Gonum{}.Plot(Scatter("", "x", "y", Series{Name: "points", Points: xy}), path)
+}
+
+// ECharts plots charts as interactive html pages with go-echarts
+type ECharts struct{}
+
+// Extension is the default file extension
+func (ECharts) Extension() string {
+	return ".html"
+}
+
+// Plot plots the chart
+func (ECharts) Plot(chart Chart, path string) error {
+	page := components.NewPage()
+	page.AddCharts(echart(chart))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return page.Render(f)
+}
+
+// echart converts the chart to a go-echarts chart
+func echart(chart Chart) components.Charter {
+	scale := "value"
+	if chart.LogY {
+		scale = "log"
+	}
+	global := []charts.GlobalOpts{
+		charts.WithTitleOpts(opts.Title{Title: chart.Title}),
+		charts.WithXAxisOpts(opts.XAxis{Name: chart.XLabel, Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: chart.YLabel, Type: scale}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+	}
+	switch chart.Kind {
+	case KindLine:
+		line := charts.NewLine()
+		line.SetGlobalOptions(global...)
+		for _, series := range chart.Series {
+			data := make([]opts.LineData, len(series.Points))
+			for i, point := range series.Points {
+				data[i] = opts.LineData{Value: []float64{point.X, point.Y}}
+			}
+			line.AddSeries(series.Name, data)
+		}
+		return line
+	default:
+		scatter := charts.NewScatter()
+		scatter.SetGlobalOptions(global...)
+		for _, series := range chart.Series {
+			data := make([]opts.ScatterData, len(series.Points))
+			for i, point := range series.Points {
+				data[i] = opts.ScatterData{Value: []float64{point.X, point.Y}, SymbolSize: 2}
+			}
+			scatter.AddSeries(series.Name, data)
+		}
+		return scatter
+	}
+}
+
+// Nop doesn't plot anything
+type Nop struct{}
+
+// Extension is the default file extension
+func (Nop) Extension() string {
+	return ""
+}
+
+// Plot does nothing
+func (Nop) Plot(chart Chart, path string) error {
+	return nil
+}
+
+// VegaLite exports charts as vega-lite json specifications for notebooks
+type VegaLite struct{}
+
+// Extension is the default file extension
+func (VegaLite) Extension() string {
+	return ".json"
+}
+
+// Plot writes the vega-lite specification of the chart
+func (VegaLite) Plot(chart Chart, path string) error {
+	type Value struct {
+		Series string  `json:"series"`
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+	}
+	values := make([]Value, 0, 1024)
+	for _, series := range chart.Series {
+		for _, point := range series.Points {
+			values = append(values, Value{Series: series.Name, X: point.X, Y: point.Y})
+		}
+	}
+	mark := "point"
+	if chart.Kind == KindLine {
+		mark = "line"
+	}
+	y := map[string]interface{}{"field": "y", "type": "quantitative", "title": chart.YLabel}
+	if chart.LogY {
+		y["scale"] = map[string]interface{}{"type": "log"}
+	}
+	spec := map[string]interface{}{
+		"$schema": "https://vega.github.io/schema/vega-lite/v5.json",
+		"title":   chart.Title,
+		"data":    map[string]interface{}{"values": values},
+		"mark":    mark,
+		"encoding": map[string]interface{}{
+			"x":     map[string]interface{}{"field": "x", "type": "quantitative", "title": chart.XLabel},
+			"y":     y,
+			"color": map[string]interface{}{"field": "series", "type": "nominal"},
+		},
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spec)
+}